@@ -3,6 +3,7 @@ package database
 import (
     "log"
     "os"
+    "time"
     "daily-lesson-api/models"
     "github.com/glebarez/sqlite"
     "gorm.io/gorm"
@@ -28,18 +29,15 @@ func Connect() {
     DB = db
     
     // Auto migrate tables
-    err = DB.AutoMigrate(
-        &models.User{},
-        &models.DailyLesson{},
-        &models.LessonReport{},
-        &models.Activity{},
-    )
-    if err != nil {
+    if err := Migrate(DB); err != nil {
         log.Fatal("Failed to migrate database:", err)
     }
     
     log.Println("SQLite database connected successfully (Pure Go driver)")
     
+    // Full-text search index untuk isi lesson
+    SetupLessonSearch()
+    
     // Create default users if not exists
     createDefaultUsers()
     
@@ -47,6 +45,58 @@ func Connect() {
     createSampleActivities()
 }
 
+// Migrate membuat atau memperbarui seluruh tabel aplikasi
+func Migrate(db *gorm.DB) error {
+    err := db.AutoMigrate(
+        &models.User{},
+        &models.DailyLesson{},
+        &models.LessonReport{},
+        &models.Activity{},
+        &models.SchoolProfile{},
+        &models.ReportTemplate{},
+        &models.IdempotencyKey{},
+        &models.Substitution{},
+        &models.LeaveRequest{},
+        &models.Student{},
+        &models.ClassMembership{},
+        &models.Attendance{},
+        &models.CapaianPembelajaran{},
+        &models.TujuanPembelajaran{},
+        &models.SemesterPlan{},
+        &models.PlannedTopic{},
+        &models.LessonPlan{},
+        &models.LessonPlanDocument{},
+        &models.Assignment{},
+        &models.ScoreScale{},
+        &models.Assessment{},
+        &models.AssessmentScore{},
+        &models.Incident{},
+    )
+    if err != nil {
+        return err
+    }
+    
+    return backfillClassMemberships(db)
+}
+
+// backfillClassMemberships membuat ClassMembership tahun ajaran berjalan untuk siswa lama
+// yang belum punya riwayat kelas, agar ikut roster per tahun dan kenaikan kelas
+func backfillClassMemberships(db *gorm.DB) error {
+    now := time.Now()
+    result := db.Exec(`INSERT INTO class_memberships (created_at, updated_at, student_id, tahun_ajaran, kelas)
+        SELECT ?, ?, students.id, ?, students.kelas FROM students
+        WHERE students.deleted_at IS NULL AND students.kelas <> ''
+        AND NOT EXISTS (SELECT 1 FROM class_memberships WHERE class_memberships.student_id = students.id)`,
+        now, now, models.TahunAjaranOf(now))
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected > 0 {
+        log.Printf("Backfilled class memberships for %d students", result.RowsAffected)
+    }
+    return nil
+}
+
 func createDefaultUsers() {
     // Create admin user
     var admin models.User
@@ -0,0 +1,56 @@
+package database
+
+import "log"
+
+// SetupLessonSearch membuat index FTS5 untuk isi catatan mengajar. Index memakai
+// external content dari tabel daily_lessons dan dijaga tetap sinkron oleh trigger,
+// sehingga create/update/delete (termasuk soft delete dan restore) langsung ter-index.
+// Lesson yang sudah di-soft delete tidak dimasukkan ke index.
+func SetupLessonSearch() {
+    var existing int64
+    DB.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'daily_lessons_fts'").Scan(&existing)
+
+    statements := []string{
+        `CREATE VIRTUAL TABLE IF NOT EXISTS daily_lessons_fts USING fts5(
+            pokok_materi, catatan, mata_pelajaran, nama_guru,
+            content='daily_lessons', content_rowid='id',
+            tokenize='unicode61 remove_diacritics 2'
+        )`,
+        `CREATE TRIGGER IF NOT EXISTS daily_lessons_fts_insert AFTER INSERT ON daily_lessons
+            WHEN new.deleted_at IS NULL BEGIN
+            INSERT INTO daily_lessons_fts(rowid, pokok_materi, catatan, mata_pelajaran, nama_guru)
+            VALUES (new.id, new.pokok_materi, new.catatan, new.mata_pelajaran, new.nama_guru);
+        END`,
+        `CREATE TRIGGER IF NOT EXISTS daily_lessons_fts_delete AFTER DELETE ON daily_lessons
+            WHEN old.deleted_at IS NULL BEGIN
+            INSERT INTO daily_lessons_fts(daily_lessons_fts, rowid, pokok_materi, catatan, mata_pelajaran, nama_guru)
+            VALUES ('delete', old.id, old.pokok_materi, old.catatan, old.mata_pelajaran, old.nama_guru);
+        END`,
+        `CREATE TRIGGER IF NOT EXISTS daily_lessons_fts_update_old AFTER UPDATE ON daily_lessons
+            WHEN old.deleted_at IS NULL BEGIN
+            INSERT INTO daily_lessons_fts(daily_lessons_fts, rowid, pokok_materi, catatan, mata_pelajaran, nama_guru)
+            VALUES ('delete', old.id, old.pokok_materi, old.catatan, old.mata_pelajaran, old.nama_guru);
+        END`,
+        `CREATE TRIGGER IF NOT EXISTS daily_lessons_fts_update_new AFTER UPDATE ON daily_lessons
+            WHEN new.deleted_at IS NULL BEGIN
+            INSERT INTO daily_lessons_fts(rowid, pokok_materi, catatan, mata_pelajaran, nama_guru)
+            VALUES (new.id, new.pokok_materi, new.catatan, new.mata_pelajaran, new.nama_guru);
+        END`,
+    }
+
+    for _, statement := range statements {
+        if err := DB.Exec(statement).Error; err != nil {
+            log.Fatal("Failed to setup lesson search index:", err)
+        }
+    }
+
+    // Index baru dibuat: isi dengan data lesson yang sudah ada
+    if existing == 0 {
+        err := DB.Exec(`INSERT INTO daily_lessons_fts(rowid, pokok_materi, catatan, mata_pelajaran, nama_guru)
+            SELECT id, pokok_materi, catatan, mata_pelajaran, nama_guru FROM daily_lessons WHERE deleted_at IS NULL`).Error
+        if err != nil {
+            log.Fatal("Failed to build lesson search index:", err)
+        }
+        log.Println("Lesson search index created")
+    }
+}
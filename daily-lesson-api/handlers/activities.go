@@ -3,7 +3,6 @@ package handlers
 import (
     "daily-lesson-api/database"
     "daily-lesson-api/models"
-    "strconv"
 
     "github.com/gofiber/fiber/v2"
 )
@@ -11,28 +10,27 @@ import (
 // GetUserActivities mendapatkan aktivitas user
 func GetUserActivities(c *fiber.Ctx) error {
     // Mendapatkan parameter query untuk pagination
-    limitStr := c.Query("limit", "10")
-    limit, err := strconv.Atoi(limitStr)
+    pagination, err := parsePagination(c, []string{"id", "created_at", "action", "performed_by"}, "created_at", 10)
     if err != nil {
-        limit = 20
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
     }
 
-    var activities []models.Activity
-
     // Query untuk mendapatkan semua aktivitas
-    result := database.DB.Order("created_at DESC").Limit(limit).Find(&activities)
+    query := database.DB.Model(&models.Activity{})
 
-    if result.Error != nil {
+    activities, meta, err := paginate(c, query, pagination, func(activity models.Activity) uint {
+        return activity.ID
+    })
+    if err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error":   "Failed to fetch activities",
-            "message": result.Error.Error(),
+            "message": err.Error(),
         })
     }
 
-    return c.JSON(fiber.Map{
-        "activities": activities,
-        "total":      len(activities),
-    })
+    return c.JSON(paginatedResponse(activities, meta))
 }
 
 // CreateActivity untuk membuat aktivitas baru (akan dipanggil dari handler lain)
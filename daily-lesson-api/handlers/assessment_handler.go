@@ -0,0 +1,560 @@
+package handlers
+
+import (
+    "fmt"
+    "math"
+    "strings"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// assessmentTypes adalah jenis penilaian harian yang diterima
+var assessmentTypes = map[string]bool{
+    models.AssessmentKuis:    true,
+    models.AssessmentUlangan: true,
+    models.AssessmentPraktik: true,
+    models.AssessmentLisan:   true,
+    models.AssessmentTugas:   true,
+}
+
+type ScoreScaleRequest struct {
+    NilaiMaksimal float64 `json:"nilai_maksimal"`
+    KKM           float64 `json:"kkm"`
+}
+
+type AssessmentRequest struct {
+    Judul         string   `json:"judul"`
+    Jenis         string   `json:"jenis"`
+    NilaiMaksimal *float64 `json:"nilai_maksimal"`
+    KKM           *float64 `json:"kkm"`
+}
+
+// AssessmentScoreRequest adalah nilai satu siswa; nilai null menghapus nilai yang sudah ada
+type AssessmentScoreRequest struct {
+    StudentID uint     `json:"student_id"`
+    Nilai     *float64 `json:"nilai"`
+    Catatan   string   `json:"catatan"`
+}
+
+type SaveAssessmentScoresRequest struct {
+    Scores []AssessmentScoreRequest `json:"scores"`
+}
+
+// AssessmentScoreEntry adalah satu baris daftar nilai; Nilai nil berarti belum dinilai
+type AssessmentScoreEntry struct {
+    StudentID uint     `json:"student_id"`
+    NIS       string   `json:"nis"`
+    Nama      string   `json:"nama"`
+    Nilai     *float64 `json:"nilai"`
+    Catatan   string   `json:"catatan"`
+    Tuntas    *bool    `json:"tuntas"`
+}
+
+// AssessmentListItem adalah penilaian beserta ringkasan jumlah siswa yang sudah dinilai
+type AssessmentListItem struct {
+    models.Assessment
+    JumlahDinilai int64   `json:"jumlah_dinilai"`
+    RataRata      float64 `json:"rata_rata"`
+}
+
+// roundScore membulatkan nilai menjadi dua angka di belakang koma
+func roundScore(value float64) float64 {
+    return math.Round(value*100) / 100
+}
+
+// loadScoreScale mengambil skala nilai sekolah, default 0-100 dengan KKM 75
+func loadScoreScale() models.ScoreScale {
+    var scale models.ScoreScale
+    if err := database.DB.First(&scale).Error; err != nil {
+        scale = models.ScoreScale{NilaiMaksimal: 100, KKM: 75}
+    }
+    return scale
+}
+
+func validateScale(nilaiMaksimal, kkm float64) string {
+    if nilaiMaksimal <= 0 || nilaiMaksimal > 1000 {
+        return "nilai_maksimal harus antara 1 dan 1000"
+    }
+    if kkm < 0 || kkm > nilaiMaksimal {
+        return "kkm harus antara 0 dan nilai_maksimal"
+    }
+    return ""
+}
+
+// GetScoreScale menampilkan skala nilai bawaan untuk penilaian baru
+func GetScoreScale(c *fiber.Ctx) error {
+    return c.JSON(loadScoreScale())
+}
+
+// UpdateScoreScale mengubah skala nilai bawaan. Penilaian yang sudah dibuat tidak ikut berubah.
+func UpdateScoreScale(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req ScoreScaleRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if message := validateScale(req.NilaiMaksimal, req.KKM); message != "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": message,
+        })
+    }
+
+    var scale models.ScoreScale
+    database.DB.First(&scale)
+
+    scale.NilaiMaksimal = req.NilaiMaksimal
+    scale.KKM = req.KKM
+    scale.UpdatedByID = userID
+
+    if err := database.DB.Save(&scale).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update score scale",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Mengubah skala nilai menjadi 0-%g dengan KKM %g", scale.NilaiMaksimal, scale.KKM))
+
+    return c.JSON(scale)
+}
+
+// loadOwnedAssessment mengambil penilaian beserta lesson-nya; guru hanya boleh melihat dan
+// mengubah penilaian pada lesson miliknya. Jika gagal, response sudah dikirim dan ok bernilai false.
+func loadOwnedAssessment(c *fiber.Ctx) (models.Assessment, bool, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var assessment models.Assessment
+
+    if err := database.DB.Preload("Lesson").First(&assessment, c.Params("id")).Error; err != nil {
+        return assessment, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Assessment not found",
+        })
+    }
+
+    if userRole == "teacher" && (assessment.Lesson == nil || !lessonOwnedBy(*assessment.Lesson, userID)) {
+        return assessment, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Access denied",
+        })
+    }
+
+    return assessment, true, nil
+}
+
+// assessmentScores menggabungkan roster kelas penilaian pada tahun ajaran tanggal penilaian
+// dengan nilai yang sudah tercatat. Siswa yang sudah pindah kelas tetap muncul jika nilainya
+// tercatat pada penilaian ini.
+func assessmentScores(assessment models.Assessment) ([]AssessmentScoreEntry, error) {
+    roster, err := classRoster(assessment.Kelas, assessment.Tanggal)
+    if err != nil {
+        return nil, err
+    }
+
+    var scores []models.AssessmentScore
+    if err := database.DB.Preload("Student", func(db *gorm.DB) *gorm.DB {
+        return db.Unscoped()
+    }).Where("assessment_id = ?", assessment.ID).Find(&scores).Error; err != nil {
+        return nil, err
+    }
+
+    scoreByStudent := map[uint]models.AssessmentScore{}
+    for _, score := range scores {
+        scoreByStudent[score.StudentID] = score
+    }
+
+    entry := func(student models.Student) AssessmentScoreEntry {
+        result := AssessmentScoreEntry{StudentID: student.ID, NIS: student.NIS, Nama: student.Nama}
+        if score, ok := scoreByStudent[student.ID]; ok {
+            nilai := score.Nilai
+            tuntas := nilai >= assessment.KKM
+            result.Nilai = &nilai
+            result.Catatan = score.Catatan
+            result.Tuntas = &tuntas
+        }
+        return result
+    }
+
+    entries := make([]AssessmentScoreEntry, 0, len(roster))
+    listed := map[uint]bool{}
+    for _, student := range roster {
+        entries = append(entries, entry(student))
+        listed[student.ID] = true
+    }
+    for _, score := range scores {
+        if !listed[score.StudentID] {
+            entries = append(entries, entry(score.Student))
+        }
+    }
+    return entries, nil
+}
+
+func assessmentSummary(entries []AssessmentScoreEntry) fiber.Map {
+    var dinilai, tuntas int
+    var total, tertinggi, terendah float64
+    for _, entry := range entries {
+        if entry.Nilai == nil {
+            continue
+        }
+        if dinilai == 0 || *entry.Nilai > tertinggi {
+            tertinggi = *entry.Nilai
+        }
+        if dinilai == 0 || *entry.Nilai < terendah {
+            terendah = *entry.Nilai
+        }
+        dinilai++
+        total += *entry.Nilai
+        if *entry.Tuntas {
+            tuntas++
+        }
+    }
+
+    rataRata := 0.0
+    if dinilai > 0 {
+        rataRata = roundScore(total / float64(dinilai))
+    }
+    return fiber.Map{
+        "dinilai":       dinilai,
+        "belum_dinilai": len(entries) - dinilai,
+        "rata_rata":     rataRata,
+        "tertinggi":     tertinggi,
+        "terendah":      terendah,
+        "tuntas":        tuntas,
+        "belum_tuntas":  dinilai - tuntas,
+    }
+}
+
+// GetLessonAssessments menampilkan penilaian pada satu lesson
+func GetLessonAssessments(c *fiber.Ctx) error {
+    lesson, ok, err := loadOwnedLesson(c)
+    if !ok {
+        return err
+    }
+
+    var assessments []models.Assessment
+    if err := database.DB.Where("lesson_id = ?", lesson.ID).Order("id ASC").Find(&assessments).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch assessments",
+        })
+    }
+
+    var stats []struct {
+        AssessmentID  uint
+        JumlahDinilai int64
+        RataRata      float64
+    }
+    if err := database.DB.Model(&models.AssessmentScore{}).
+        Select("assessment_scores.assessment_id, count(*) AS jumlah_dinilai, avg(assessment_scores.nilai) AS rata_rata").
+        Joins("JOIN assessments ON assessments.id = assessment_scores.assessment_id").
+        Where("assessments.lesson_id = ?", lesson.ID).
+        Group("assessment_scores.assessment_id").Scan(&stats).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch assessments",
+        })
+    }
+
+    itemByAssessment := map[uint]*AssessmentListItem{}
+    items := make([]AssessmentListItem, len(assessments))
+    for i, assessment := range assessments {
+        items[i] = AssessmentListItem{Assessment: assessment}
+        itemByAssessment[assessment.ID] = &items[i]
+    }
+    for _, stat := range stats {
+        if item, ok := itemByAssessment[stat.AssessmentID]; ok {
+            item.JumlahDinilai = stat.JumlahDinilai
+            item.RataRata = roundScore(stat.RataRata)
+        }
+    }
+
+    return c.JSON(items)
+}
+
+// CreateAssessment menambahkan penilaian pada lesson. Skala nilai default mengikuti skala sekolah.
+func CreateAssessment(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req AssessmentRequest
+
+    lesson, ok, err := loadOwnedLesson(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    req.Jenis = strings.ToLower(strings.TrimSpace(req.Jenis))
+    if strings.TrimSpace(req.Judul) == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "judul wajib diisi",
+        })
+    }
+    if !assessmentTypes[req.Jenis] {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "jenis harus salah satu dari kuis, ulangan, praktik, lisan, tugas",
+        })
+    }
+
+    scale := loadScoreScale()
+    assessment := models.Assessment{
+        LessonID:      lesson.ID,
+        Judul:         strings.TrimSpace(req.Judul),
+        Jenis:         req.Jenis,
+        Kelas:         lesson.Kelas,
+        MataPelajaran: lesson.MataPelajaran,
+        Tanggal:       lesson.TanggalMengajar,
+        TeacherID:     lesson.CreatedByID,
+        NilaiMaksimal: scale.NilaiMaksimal,
+        KKM:           scale.KKM,
+        CreatedByID:   userID,
+    }
+    // KKM bawaan disesuaikan secara proporsional jika nilai maksimal berbeda dari skala sekolah
+    if req.NilaiMaksimal != nil {
+        assessment.NilaiMaksimal = *req.NilaiMaksimal
+        assessment.KKM = roundScore(scale.KKM / scale.NilaiMaksimal * assessment.NilaiMaksimal)
+    }
+    if req.KKM != nil {
+        assessment.KKM = *req.KKM
+    }
+    if message := validateScale(assessment.NilaiMaksimal, assessment.KKM); message != "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": message,
+        })
+    }
+
+    if err := database.DB.Create(&assessment).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create assessment",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Membuat penilaian %s (%s): %s - %s %s", assessment.Judul, assessment.Jenis,
+        assessment.MataPelajaran, assessment.Kelas, assessment.Tanggal.Format("2006-01-02"))
+    createActivity(userEmail, "create", activityDescription)
+
+    return c.Status(fiber.StatusCreated).JSON(assessment)
+}
+
+// GetAssessment menampilkan penilaian beserta daftar nilai siswa dan ringkasannya
+func GetAssessment(c *fiber.Ctx) error {
+    assessment, ok, err := loadOwnedAssessment(c)
+    if !ok {
+        return err
+    }
+
+    entries, err := assessmentScores(assessment)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch scores",
+        })
+    }
+
+    assessment.Lesson = nil
+    return c.JSON(fiber.Map{
+        "assessment": assessment,
+        "data":       entries,
+        "summary":    assessmentSummary(entries),
+    })
+}
+
+// UpdateAssessment mengubah judul, jenis atau skala nilai penilaian. Nilai maksimal tidak boleh
+// lebih kecil dari nilai yang sudah tercatat.
+func UpdateAssessment(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var req AssessmentRequest
+
+    assessment, ok, err := loadOwnedAssessment(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    assessment.Judul = strings.TrimSpace(valueOr(req.Judul, assessment.Judul))
+    if req.Jenis != "" {
+        assessment.Jenis = strings.ToLower(strings.TrimSpace(req.Jenis))
+        if !assessmentTypes[assessment.Jenis] {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "jenis harus salah satu dari kuis, ulangan, praktik, lisan, tugas",
+            })
+        }
+    }
+    if req.NilaiMaksimal != nil {
+        assessment.NilaiMaksimal = *req.NilaiMaksimal
+    }
+    if req.KKM != nil {
+        assessment.KKM = *req.KKM
+    }
+    if message := validateScale(assessment.NilaiMaksimal, assessment.KKM); message != "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": message,
+        })
+    }
+
+    var highest int64
+    database.DB.Model(&models.AssessmentScore{}).
+        Where("assessment_id = ? AND nilai > ?", assessment.ID, assessment.NilaiMaksimal).Count(&highest)
+    if highest > 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": fmt.Sprintf("%d nilai siswa melebihi nilai_maksimal %g", highest, assessment.NilaiMaksimal),
+        })
+    }
+
+    if err := database.DB.Omit("Lesson").Save(&assessment).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update assessment",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Memperbarui penilaian %s: %s - %s", assessment.Judul, assessment.MataPelajaran, assessment.Kelas))
+
+    assessment.Lesson = nil
+    return c.JSON(assessment)
+}
+
+// SaveAssessmentScores mengisi nilai banyak siswa sekaligus. Siswa yang tidak disebut tidak berubah.
+func SaveAssessmentScores(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req SaveAssessmentScoresRequest
+
+    assessment, ok, err := loadOwnedAssessment(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if len(req.Scores) == 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "scores wajib diisi",
+        })
+    }
+
+    entries, err := assessmentScores(assessment)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch scores",
+        })
+    }
+    listed := map[uint]bool{}
+    for _, entry := range entries {
+        listed[entry.StudentID] = true
+    }
+
+    updates := map[uint]AssessmentScoreRequest{}
+    for i, score := range req.Scores {
+        if !listed[score.StudentID] {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("scores[%d]: siswa %d tidak terdaftar di kelas %s", i, score.StudentID, assessment.Kelas),
+            })
+        }
+        if score.Nilai != nil && (*score.Nilai < 0 || *score.Nilai > assessment.NilaiMaksimal) {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("scores[%d]: nilai harus antara 0 dan %g", i, assessment.NilaiMaksimal),
+            })
+        }
+        updates[score.StudentID] = score
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        var existing []models.AssessmentScore
+        if err := tx.Unscoped().Where("assessment_id = ?", assessment.ID).Find(&existing).Error; err != nil {
+            return err
+        }
+        existingByStudent := map[uint]models.AssessmentScore{}
+        for _, score := range existing {
+            existingByStudent[score.StudentID] = score
+        }
+
+        for studentID, update := range updates {
+            score, found := existingByStudent[studentID]
+            if update.Nilai == nil {
+                if found && !score.DeletedAt.Valid {
+                    if err := tx.Delete(&score).Error; err != nil {
+                        return err
+                    }
+                }
+                continue
+            }
+            if !found {
+                score = models.AssessmentScore{AssessmentID: assessment.ID, StudentID: studentID}
+            }
+            score.Nilai = *update.Nilai
+            score.Catatan = strings.TrimSpace(update.Catatan)
+            score.RecordedByID = userID
+            score.DeletedAt = gorm.DeletedAt{}
+            if err := tx.Unscoped().Save(&score).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not save scores",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Mengisi nilai %d siswa pada penilaian %s: %s - %s", len(updates),
+        assessment.Judul, assessment.MataPelajaran, assessment.Kelas)
+    createActivity(userEmail, "assessment", activityDescription)
+
+    entries, err = assessmentScores(assessment)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch scores",
+        })
+    }
+
+    assessment.Lesson = nil
+    return c.JSON(fiber.Map{
+        "assessment": assessment,
+        "data":       entries,
+        "summary":    assessmentSummary(entries),
+    })
+}
+
+// DeleteAssessment menghapus penilaian beserta nilai siswanya
+func DeleteAssessment(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    assessment, ok, err := loadOwnedAssessment(c)
+    if !ok {
+        return err
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("assessment_id = ?", assessment.ID).Delete(&models.AssessmentScore{}).Error; err != nil {
+            return err
+        }
+        return tx.Delete(&assessment).Error
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete assessment",
+        })
+    }
+
+    createActivity(userEmail, "delete", fmt.Sprintf("Menghapus penilaian %s: %s - %s", assessment.Judul, assessment.MataPelajaran, assessment.Kelas))
+
+    return c.JSON(fiber.Map{
+        "message": "Assessment deleted successfully",
+    })
+}
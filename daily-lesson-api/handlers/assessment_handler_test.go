@@ -0,0 +1,32 @@
+package handlers
+
+import (
+    "testing"
+    "time"
+
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestAssessmentScoresUseRosterOfAssessmentYear(t *testing.T) {
+    setupTestDB(t)
+    lastYear := time.Now().AddDate(-1, 0, 0)
+    current := models.TahunAjaranOf(time.Now())
+    previous := models.TahunAjaranOf(lastYear)
+
+    naik := createTestStudent(t, "2001", "Naik Kelas", "8A", map[string]string{previous: "7A", current: "8A"})
+    baru := createTestStudent(t, "2002", "Siswa Baru", "7A", map[string]string{current: "7A"})
+
+    assessment := models.Assessment{Judul: "UH 1", Kelas: "7A", MataPelajaran: "Matematika", Tanggal: lastYear, NilaiMaksimal: 100, KKM: 75}
+    if err := database.DB.Create(&assessment).Error; err != nil {
+        t.Fatalf("create assessment: %v", err)
+    }
+
+    entries, err := assessmentScores(assessment)
+    if err != nil {
+        t.Fatalf("assessmentScores: %v", err)
+    }
+    if len(entries) != 1 || entries[0].StudentID != naik.ID {
+        t.Fatalf("entries = %+v, want only student %d from %s 7A (not %d)", entries, naik.ID, previous, baru.ID)
+    }
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+type AssignmentRequest struct {
+    Judul     string `json:"judul"`
+    Deskripsi string `json:"deskripsi"`
+    Kelas     string `json:"kelas"`
+    Tenggat   string `json:"tenggat"`
+}
+
+type GradeAssignmentRequest struct {
+    SudahDinilai bool `json:"sudah_dinilai"`
+}
+
+// parseTenggat menerima YYYY-MM-DD (dianggap akhir hari), YYYY-MM-DD HH:MM atau RFC3339.
+// Tanpa zona waktu, tenggat dibaca dalam zona waktu server.
+func parseTenggat(value string) (time.Time, error) {
+    value = strings.TrimSpace(value)
+    if tanggal, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+        return tanggal.Add(24*time.Hour - time.Minute), nil
+    }
+    if tanggal, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+        return tanggal, nil
+    }
+    if tanggal, err := time.Parse(time.RFC3339, value); err == nil {
+        return tanggal.Local(), nil
+    }
+    return time.Time{}, fmt.Errorf("Format tenggat tidak valid. Gunakan format YYYY-MM-DD atau YYYY-MM-DD HH:MM")
+}
+
+// loadOwnedAssignment mengambil tugas beserta lesson-nya; guru hanya boleh mengubah tugas
+// pada lesson miliknya. Jika gagal, response sudah dikirim dan ok bernilai false.
+func loadOwnedAssignment(c *fiber.Ctx) (models.Assignment, bool, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var assignment models.Assignment
+
+    if err := database.DB.Preload("Lesson").First(&assignment, c.Params("id")).Error; err != nil {
+        return assignment, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Assignment not found",
+        })
+    }
+
+    if userRole == "teacher" && (assignment.Lesson == nil || !lessonOwnedBy(*assignment.Lesson, userID)) {
+        return assignment, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat mengubah data sendiri",
+        })
+    }
+
+    return assignment, true, nil
+}
+
+// GetLessonAssignments menampilkan tugas yang diberikan pada satu lesson
+func GetLessonAssignments(c *fiber.Ctx) error {
+    var assignments []models.Assignment
+
+    if err := database.DB.Where("lesson_id = ?", c.Params("id")).Order("tenggat ASC").Find(&assignments).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch assignments",
+        })
+    }
+
+    return c.JSON(assignments)
+}
+
+// CreateAssignment menambahkan tugas pada lesson. Kelas default mengikuti kelas lesson.
+func CreateAssignment(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+    var req AssignmentRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&lesson, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat mengubah data sendiri",
+        })
+    }
+
+    if strings.TrimSpace(req.Judul) == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "judul wajib diisi",
+        })
+    }
+
+    tenggat, err := parseTenggat(req.Tenggat)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+    if tenggat.Before(lesson.TanggalMengajar) {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "tenggat tidak boleh sebelum tanggal mengajar",
+        })
+    }
+
+    assignment := models.Assignment{
+        LessonID:      lesson.ID,
+        Judul:         strings.TrimSpace(req.Judul),
+        Deskripsi:     strings.TrimSpace(req.Deskripsi),
+        Kelas:         strings.TrimSpace(valueOr(req.Kelas, lesson.Kelas)),
+        MataPelajaran: lesson.MataPelajaran,
+        Tenggat:       tenggat,
+        TeacherID:     lesson.CreatedByID,
+        CreatedByID:   userID,
+    }
+
+    if err := database.DB.Create(&assignment).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create assignment",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Memberikan tugas %s: %s - %s, tenggat %s", assignment.Judul,
+        assignment.MataPelajaran, assignment.Kelas, assignment.Tenggat.Format("2006-01-02 15:04"))
+    createActivity(userEmail, "create", activityDescription)
+
+    return c.Status(fiber.StatusCreated).JSON(assignment)
+}
+
+// UpdateAssignment mengubah judul, deskripsi, kelas atau tenggat tugas
+func UpdateAssignment(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var req AssignmentRequest
+
+    assignment, ok, err := loadOwnedAssignment(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    assignment.Judul = strings.TrimSpace(valueOr(req.Judul, assignment.Judul))
+    assignment.Deskripsi = strings.TrimSpace(valueOr(req.Deskripsi, assignment.Deskripsi))
+    assignment.Kelas = strings.TrimSpace(valueOr(req.Kelas, assignment.Kelas))
+    if req.Tenggat != "" {
+        tenggat, err := parseTenggat(req.Tenggat)
+        if err != nil {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": err.Error(),
+            })
+        }
+        if tenggat.Before(assignment.Lesson.TanggalMengajar) {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "tenggat tidak boleh sebelum tanggal mengajar",
+            })
+        }
+        assignment.Tenggat = tenggat
+    }
+
+    if err := database.DB.Omit("Lesson").Save(&assignment).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update assignment",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Memperbarui tugas %s: %s - %s", assignment.Judul, assignment.MataPelajaran, assignment.Kelas))
+
+    assignment.Lesson = nil
+    return c.JSON(assignment)
+}
+
+// GradeAssignment menandai tugas sudah atau belum dinilai
+func GradeAssignment(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var req GradeAssignmentRequest
+
+    assignment, ok, err := loadOwnedAssignment(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    assignment.SudahDinilai = req.SudahDinilai
+    assignment.DinilaiPada = nil
+    if req.SudahDinilai {
+        now := time.Now()
+        assignment.DinilaiPada = &now
+    }
+
+    if err := database.DB.Model(&assignment).Updates(map[string]interface{}{
+        "sudah_dinilai": assignment.SudahDinilai,
+        "dinilai_pada":  assignment.DinilaiPada,
+    }).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update assignment",
+        })
+    }
+
+    status := "belum dinilai"
+    if assignment.SudahDinilai {
+        status = "sudah dinilai"
+    }
+    createActivity(userEmail, "update", fmt.Sprintf("Menandai tugas %s (%s) %s", assignment.Judul, assignment.Kelas, status))
+
+    assignment.Lesson = nil
+    return c.JSON(assignment)
+}
+
+// DeleteAssignment menghapus tugas
+func DeleteAssignment(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    assignment, ok, err := loadOwnedAssignment(c)
+    if !ok {
+        return err
+    }
+
+    if err := database.DB.Delete(&assignment).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete assignment",
+        })
+    }
+
+    createActivity(userEmail, "delete", fmt.Sprintf("Menghapus tugas %s: %s - %s", assignment.Judul, assignment.MataPelajaran, assignment.Kelas))
+
+    return c.JSON(fiber.Map{
+        "message": "Assignment deleted successfully",
+    })
+}
+
+// activeLessonAssignments membatasi query tugas pada lesson yang belum dihapus
+func activeLessonAssignments() *gorm.DB {
+    return database.DB.Model(&models.Assignment{}).
+        Joins("JOIN daily_lessons ON daily_lessons.id = assignments.lesson_id AND daily_lessons.deleted_at IS NULL")
+}
+
+// GetUpcomingAssignments menampilkan tugas satu kelas yang tenggatnya belum lewat,
+// dalam rentang days hari ke depan (default 14). Tugas dari lesson yang dihapus tidak ditampilkan.
+func GetUpcomingAssignments(c *fiber.Ctx) error {
+    kelas := c.Query("kelas")
+    if kelas == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "kelas wajib diisi",
+        })
+    }
+
+    days := c.QueryInt("days", 14)
+    if days < 1 || days > 180 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "days harus antara 1 dan 180",
+        })
+    }
+
+    now := time.Now()
+    var assignments []models.Assignment
+    if err := activeLessonAssignments().
+        Where("lower(assignments.kelas) = lower(?) AND assignments.tenggat >= ? AND assignments.tenggat <= ?", kelas, now, now.AddDate(0, 0, days)).
+        Order("assignments.tenggat ASC").Find(&assignments).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch assignments",
+        })
+    }
+
+    return c.JSON(fiber.Map{
+        "kelas": kelas,
+        "days":  days,
+        "data":  assignments,
+    })
+}
+
+// PendingAssignment adalah tugas yang tenggatnya sudah lewat tetapi belum dinilai
+type PendingAssignment struct {
+    models.Assignment
+    HariTerlambat int `json:"hari_terlambat"`
+}
+
+// GetPendingGrading menampilkan tugas yang sudah lewat tenggat dan belum dinilai.
+// Guru melihat tugas miliknya; admin/supervisor bisa memfilter per guru lewat teacher_id.
+// Seperti buku nilai, tugas dari lesson yang dihapus tidak dihitung.
+func GetPendingGrading(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+
+    now := time.Now()
+    query := activeLessonAssignments().Where("assignments.sudah_dinilai = ? AND assignments.tenggat < ?", false, now)
+    if userRole == "teacher" {
+        query = query.Where("assignments.teacher_id = ?", userID)
+    } else if teacherID := c.QueryInt("teacher_id"); teacherID != 0 {
+        query = query.Where("assignments.teacher_id = ?", teacherID)
+    }
+
+    var assignments []models.Assignment
+    if err := query.Order("assignments.tenggat ASC").Find(&assignments).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch assignments",
+        })
+    }
+
+    pending := make([]PendingAssignment, len(assignments))
+    perTeacher := map[uint]int{}
+    for i, assignment := range assignments {
+        pending[i] = PendingAssignment{
+            Assignment:    assignment,
+            HariTerlambat: int(now.Sub(assignment.Tenggat).Hours() / 24),
+        }
+        perTeacher[assignment.TeacherID]++
+    }
+
+    return c.JSON(fiber.Map{
+        "data":     pending,
+        "total":    len(pending),
+        "per_guru": perTeacher,
+    })
+}
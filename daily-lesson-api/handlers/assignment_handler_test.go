@@ -0,0 +1,60 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestAssignmentListsSkipDeletedLessons(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "Guru A", models.RoleTeacher)
+    active := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID})
+    deleted := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7A", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID})
+
+    now := time.Now()
+    for _, assignment := range []models.Assignment{
+        {LessonID: active.ID, Judul: "Aktif mendatang", Kelas: "7A", Tenggat: now.AddDate(0, 0, 3), TeacherID: guru.ID},
+        {LessonID: deleted.ID, Judul: "Terhapus mendatang", Kelas: "7A", Tenggat: now.AddDate(0, 0, 3), TeacherID: guru.ID},
+        {LessonID: active.ID, Judul: "Aktif lewat", Kelas: "7A", Tenggat: now.AddDate(0, 0, -3), TeacherID: guru.ID},
+        {LessonID: deleted.ID, Judul: "Terhapus lewat", Kelas: "7A", Tenggat: now.AddDate(0, 0, -3), TeacherID: guru.ID},
+    } {
+        if err := database.DB.Create(&assignment).Error; err != nil {
+            t.Fatalf("create assignment: %v", err)
+        }
+    }
+    database.DB.Delete(&deleted)
+
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", guru.ID)
+        c.Locals("role", string(models.RoleTeacher))
+        return c.Next()
+    })
+    app.Get("/assignments/upcoming", GetUpcomingAssignments)
+    app.Get("/assignments/pending-grading", GetPendingGrading)
+
+    for path, want := range map[string]string{
+        "/assignments/upcoming?kelas=7A": "Aktif mendatang",
+        "/assignments/pending-grading":   "Aktif lewat",
+    } {
+        resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+        if err != nil || resp.StatusCode != fiber.StatusOK {
+            t.Fatalf("%s: status %v, err %v", path, resp.StatusCode, err)
+        }
+        var body struct {
+            Data []models.Assignment `json:"data"`
+        }
+        if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+            t.Fatalf("decode: %v", err)
+        }
+        if len(body.Data) != 1 || body.Data[0].Judul != want {
+            t.Errorf("%s returned %d assignments, want only %q", path, len(body.Data), want)
+        }
+    }
+}
@@ -0,0 +1,503 @@
+package handlers
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// attendanceStatuses adalah status kehadiran siswa yang diterima
+var attendanceStatuses = map[string]bool{
+    models.AttendanceHadir: true,
+    models.AttendanceSakit: true,
+    models.AttendanceIzin:  true,
+    models.AttendanceAlpa:  true,
+}
+
+type AttendanceRecordRequest struct {
+    StudentID  uint   `json:"student_id"`
+    Status     string `json:"status"`
+    Keterangan string `json:"keterangan"`
+}
+
+type SaveAttendanceRequest struct {
+    DefaultStatus string                    `json:"default_status"`
+    Records       []AttendanceRecordRequest `json:"records"`
+}
+
+// AttendanceEntry adalah satu baris daftar hadir lesson; Status kosong berarti belum diisi
+type AttendanceEntry struct {
+    StudentID  uint   `json:"student_id"`
+    Nama       string `json:"nama"`
+    Status     string `json:"status"`
+    Keterangan string `json:"keterangan"`
+}
+
+// AttendanceCount adalah jumlah kehadiran per status
+type AttendanceCount struct {
+    Hadir      int64   `json:"hadir"`
+    Sakit      int64   `json:"sakit"`
+    Izin       int64   `json:"izin"`
+    Alpa       int64   `json:"alpa"`
+    Total      int64   `json:"total"`
+    Persentase float64 `json:"persentase_hadir"`
+}
+
+func (count *AttendanceCount) add(status string) {
+    switch status {
+    case models.AttendanceHadir:
+        count.Hadir++
+    case models.AttendanceSakit:
+        count.Sakit++
+    case models.AttendanceIzin:
+        count.Izin++
+    case models.AttendanceAlpa:
+        count.Alpa++
+    default:
+        return
+    }
+    count.Total++
+}
+
+func (count *AttendanceCount) finish() {
+    if count.Total > 0 {
+        count.Persentase = float64(count.Hadir*10000/count.Total) / 100
+    }
+}
+
+// attendanceCountColumns menghitung jumlah per status dari tabel attendances
+const attendanceCountColumns = `sum(CASE WHEN attendances.status = 'hadir' THEN 1 ELSE 0 END) AS hadir,
+    sum(CASE WHEN attendances.status = 'sakit' THEN 1 ELSE 0 END) AS sakit,
+    sum(CASE WHEN attendances.status = 'izin' THEN 1 ELSE 0 END) AS izin,
+    sum(CASE WHEN attendances.status = 'alpa' THEN 1 ELSE 0 END) AS alpa,
+    count(*) AS total`
+
+// loadOwnedLesson mengambil lesson dan memastikan guru hanya mengakses lesson miliknya.
+// Jika gagal, response sudah dikirim dan ok bernilai false.
+func loadOwnedLesson(c *fiber.Ctx) (models.DailyLesson, bool, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var lesson models.DailyLesson
+
+    if err := database.DB.First(&lesson, c.Params("id")).Error; err != nil {
+        return lesson, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return lesson, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Access denied",
+        })
+    }
+
+    return lesson, true, nil
+}
+
+// classRoster mengembalikan siswa kelas pada tahun ajaran tanggal tersebut berdasarkan
+// ClassMembership, sehingga lesson lama tetap memakai roster tahun itu setelah kenaikan kelas.
+// Status aktif hanya disaring untuk tahun ajaran berjalan.
+func classRoster(kelas string, date time.Time) ([]models.Student, error) {
+    tahunAjaran := models.TahunAjaranOf(date)
+    query := database.DB.
+        Joins("JOIN class_memberships ON class_memberships.student_id = students.id AND class_memberships.deleted_at IS NULL").
+        Where("class_memberships.tahun_ajaran = ? AND lower(class_memberships.kelas) = lower(?)", tahunAjaran, kelas)
+    if tahunAjaran == models.TahunAjaranOf(time.Now()) {
+        query = query.Where("students.aktif = ?", true)
+    }
+
+    var roster []models.Student
+    err := query.Order("students.nama ASC").Find(&roster).Error
+    return roster, err
+}
+
+// lessonAttendance menggabungkan roster kelas lesson dengan kehadiran yang sudah tercatat.
+// Siswa yang sudah pindah kelas tetap muncul jika kehadirannya tercatat pada lesson ini.
+func lessonAttendance(lesson models.DailyLesson) ([]AttendanceEntry, error) {
+    roster, err := classRoster(lesson.Kelas, lesson.TanggalMengajar)
+    if err != nil {
+        return nil, err
+    }
+
+    var records []models.Attendance
+    if err := database.DB.Unscoped().Preload("Student", func(db *gorm.DB) *gorm.DB {
+        return db.Unscoped()
+    }).Where("lesson_id = ? AND deleted_at IS NULL", lesson.ID).Find(&records).Error; err != nil {
+        return nil, err
+    }
+
+    recordByStudent := map[uint]models.Attendance{}
+    for _, record := range records {
+        recordByStudent[record.StudentID] = record
+    }
+
+    entries := make([]AttendanceEntry, 0, len(roster))
+    for _, student := range roster {
+        record := recordByStudent[student.ID]
+        entries = append(entries, AttendanceEntry{
+            StudentID:  student.ID,
+            Nama:       student.Nama,
+            Status:     record.Status,
+            Keterangan: record.Keterangan,
+        })
+        delete(recordByStudent, student.ID)
+    }
+    for _, record := range records {
+        if _, ok := recordByStudent[record.StudentID]; ok {
+            entries = append(entries, AttendanceEntry{
+                StudentID:  record.StudentID,
+                Nama:       record.Student.Nama,
+                Status:     record.Status,
+                Keterangan: record.Keterangan,
+            })
+        }
+    }
+    return entries, nil
+}
+
+func attendanceSummary(entries []AttendanceEntry) fiber.Map {
+    var count AttendanceCount
+    belumDiisi := 0
+    for _, entry := range entries {
+        if entry.Status == "" {
+            belumDiisi++
+            continue
+        }
+        count.add(entry.Status)
+    }
+    count.finish()
+    return fiber.Map{
+        "hadir":            count.Hadir,
+        "sakit":            count.Sakit,
+        "izin":             count.Izin,
+        "alpa":             count.Alpa,
+        "belum_diisi":      belumDiisi,
+        "persentase_hadir": count.Persentase,
+    }
+}
+
+// GetLessonAttendance menampilkan daftar hadir siswa pada satu lesson
+func GetLessonAttendance(c *fiber.Ctx) error {
+    lesson, ok, err := loadOwnedLesson(c)
+    if !ok {
+        return err
+    }
+
+    entries, err := lessonAttendance(lesson)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch attendance",
+        })
+    }
+
+    return c.JSON(fiber.Map{
+        "lesson_id": lesson.ID,
+        "kelas":     lesson.Kelas,
+        "data":      entries,
+        "summary":   attendanceSummary(entries),
+    })
+}
+
+// SaveLessonAttendance mengisi kehadiran banyak siswa sekaligus. Siswa roster yang tidak
+// disebut di records dan belum punya status diisi dengan default_status jika dikirim.
+func SaveLessonAttendance(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req SaveAttendanceRequest
+
+    lesson, ok, err := loadOwnedLesson(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    req.DefaultStatus = strings.ToLower(strings.TrimSpace(req.DefaultStatus))
+    if req.DefaultStatus != "" && !attendanceStatuses[req.DefaultStatus] {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "default_status harus salah satu dari hadir, sakit, izin, alpa",
+        })
+    }
+    if len(req.Records) == 0 && req.DefaultStatus == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "records atau default_status wajib diisi",
+        })
+    }
+
+    entries, err := lessonAttendance(lesson)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch attendance",
+        })
+    }
+    entryByStudent := map[uint]AttendanceEntry{}
+    for _, entry := range entries {
+        entryByStudent[entry.StudentID] = entry
+    }
+
+    updates := map[uint]AttendanceRecordRequest{}
+    for i, record := range req.Records {
+        record.Status = strings.ToLower(strings.TrimSpace(record.Status))
+        if _, ok := entryByStudent[record.StudentID]; !ok {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("records[%d]: siswa %d tidak terdaftar di kelas %s", i, record.StudentID, lesson.Kelas),
+            })
+        }
+        if !attendanceStatuses[record.Status] {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("records[%d]: status harus salah satu dari hadir, sakit, izin, alpa", i),
+            })
+        }
+        updates[record.StudentID] = record
+    }
+    if req.DefaultStatus != "" {
+        for _, entry := range entries {
+            if _, ok := updates[entry.StudentID]; !ok && entry.Status == "" {
+                updates[entry.StudentID] = AttendanceRecordRequest{StudentID: entry.StudentID, Status: req.DefaultStatus}
+            }
+        }
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        var existing []models.Attendance
+        if err := tx.Unscoped().Where("lesson_id = ?", lesson.ID).Find(&existing).Error; err != nil {
+            return err
+        }
+        existingByStudent := map[uint]models.Attendance{}
+        for _, record := range existing {
+            existingByStudent[record.StudentID] = record
+        }
+
+        for studentID, update := range updates {
+            record, found := existingByStudent[studentID]
+            if !found {
+                record = models.Attendance{LessonID: lesson.ID, StudentID: studentID}
+            }
+            record.Status = update.Status
+            record.Keterangan = strings.TrimSpace(update.Keterangan)
+            record.RecordedByID = userID
+            record.DeletedAt = gorm.DeletedAt{}
+            if err := tx.Unscoped().Save(&record).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not save attendance",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Mengisi kehadiran %d siswa: %s - %s (%s) %s", len(updates),
+        lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru, lesson.TanggalMengajar.Format("2006-01-02"))
+    createActivity(userEmail, "attendance", activityDescription)
+
+    entries, err = lessonAttendance(lesson)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch attendance",
+        })
+    }
+
+    return c.JSON(fiber.Map{
+        "lesson_id": lesson.ID,
+        "kelas":     lesson.Kelas,
+        "data":      entries,
+        "summary":   attendanceSummary(entries),
+    })
+}
+
+// attendanceMonth membaca parameter month (YYYY-MM), default bulan berjalan
+func attendanceMonth(c *fiber.Ctx) (string, string, string, error) {
+    month := c.Query("month")
+    start := time.Now()
+    if month != "" {
+        parsed, err := time.Parse("2006-01", month)
+        if err != nil {
+            return "", "", "", fmt.Errorf("month harus berformat YYYY-MM")
+        }
+        start = parsed
+    }
+    start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+    return start.Format("2006-01"), start.Format("2006-01-02"), start.AddDate(0, 1, -1).Format("2006-01-02"), nil
+}
+
+// attendanceInRange adalah query kehadiran pada lesson yang tanggalnya di antara start dan end
+func attendanceInRange(start, end string) *gorm.DB {
+    return database.DB.Model(&models.Attendance{}).
+        Joins("JOIN daily_lessons ON daily_lessons.id = attendances.lesson_id AND daily_lessons.deleted_at IS NULL").
+        Where("date(daily_lessons.tanggal_mengajar) BETWEEN ? AND ?", start, end)
+}
+
+type studentAttendance struct {
+    StudentID uint   `json:"student_id"`
+    Nama      string `json:"nama"`
+    Kelas     string `json:"kelas"`
+    AttendanceCount
+}
+
+// GetStudentAttendanceRecap menampilkan rekap kehadiran per siswa dalam satu bulan
+func GetStudentAttendanceRecap(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    kelas := c.Query("kelas")
+
+    month, start, end, err := attendanceMonth(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query := attendanceInRange(start, end).
+        Select("attendances.student_id, " + attendanceCountColumns).
+        Group("attendances.student_id")
+    if kelas != "" {
+        query = query.Where("lower(daily_lessons.kelas) = lower(?)", kelas)
+    }
+
+    var counts []studentAttendance
+    if err := query.Scan(&counts).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch attendance recap",
+        })
+    }
+    countByStudent := map[uint]AttendanceCount{}
+    studentIDs := []uint{}
+    for _, count := range counts {
+        countByStudent[count.StudentID] = count.AttendanceCount
+        studentIDs = append(studentIDs, count.StudentID)
+    }
+
+    studentQuery := database.DB.Unscoped().Where("id IN ?", studentIDs)
+    if kelas != "" {
+        studentQuery = studentQuery.Or("lower(kelas) = lower(?) AND aktif = ? AND deleted_at IS NULL", kelas, true)
+    }
+    var students []models.Student
+    if err := studentQuery.Order("kelas ASC, nama ASC").Find(&students).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch attendance recap",
+        })
+    }
+
+    recap := make([]studentAttendance, 0, len(students))
+    for _, student := range students {
+        row := studentAttendance{
+            StudentID:       student.ID,
+            Nama:            student.Nama,
+            Kelas:           student.Kelas,
+            AttendanceCount: countByStudent[student.ID],
+        }
+        row.finish()
+        recap = append(recap, row)
+    }
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat rekap kehadiran siswa bulan %s", month))
+
+    return c.JSON(fiber.Map{
+        "month": month,
+        "kelas": kelas,
+        "data":  recap,
+    })
+}
+
+type classAttendance struct {
+    Kelas       string `json:"kelas"`
+    JumlahSiswa int64  `json:"jumlah_siswa"`
+    Pertemuan   int64  `json:"pertemuan"`
+    AttendanceCount
+}
+
+// GetClassAttendanceRecap menampilkan rekap kehadiran per kelas dalam satu bulan
+func GetClassAttendanceRecap(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    month, start, end, err := attendanceMonth(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    var recap []classAttendance
+    if err := attendanceInRange(start, end).
+        Select("daily_lessons.kelas AS kelas, count(DISTINCT attendances.student_id) AS jumlah_siswa, " +
+            "count(DISTINCT attendances.lesson_id) AS pertemuan, " + attendanceCountColumns).
+        Group("daily_lessons.kelas").Order("daily_lessons.kelas ASC").
+        Scan(&recap).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch attendance recap",
+        })
+    }
+    for i := range recap {
+        recap[i].finish()
+    }
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat rekap kehadiran kelas bulan %s", month))
+
+    return c.JSON(fiber.Map{
+        "month": month,
+        "data":  recap,
+    })
+}
+
+type monthlyAttendance struct {
+    Month string `json:"month"`
+    AttendanceCount
+}
+
+// GetStudentAttendanceHistory menampilkan rekap kehadiran satu siswa per bulan dalam satu tahun
+func GetStudentAttendanceHistory(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var student models.Student
+
+    if err := database.DB.Unscoped().First(&student, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Student not found",
+        })
+    }
+
+    year := c.QueryInt("year", time.Now().Year())
+    start := fmt.Sprintf("%04d-01-01", year)
+    end := fmt.Sprintf("%04d-12-31", year)
+
+    var recap []monthlyAttendance
+    if err := attendanceInRange(start, end).
+        Select("strftime('%Y-%m', daily_lessons.tanggal_mengajar) AS month, "+attendanceCountColumns).
+        Where("attendances.student_id = ?", student.ID).
+        Group("month").Order("month ASC").
+        Scan(&recap).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch attendance recap",
+        })
+    }
+
+    var total AttendanceCount
+    for i := range recap {
+        recap[i].finish()
+        total.Hadir += recap[i].Hadir
+        total.Sakit += recap[i].Sakit
+        total.Izin += recap[i].Izin
+        total.Alpa += recap[i].Alpa
+        total.Total += recap[i].Total
+    }
+    total.finish()
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat rekap kehadiran siswa %s tahun %d", student.Nama, year))
+
+    return c.JSON(fiber.Map{
+        "student": student,
+        "year":    year,
+        "data":    recap,
+        "total":   total,
+    })
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func attendanceTestApp(userID uint) *fiber.App {
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleTeacher))
+        c.Locals("email", "guru@sekolah.test")
+        return c.Next()
+    })
+    app.Put("/lessons/:id/attendance", SaveLessonAttendance)
+    app.Get("/reports/attendance/classes", GetClassAttendanceRecap)
+    return app
+}
+
+func saveTestAttendance(t *testing.T, app *fiber.App, lessonID uint, req SaveAttendanceRequest) (int, []byte) {
+    t.Helper()
+
+    data, _ := json.Marshal(req)
+    httpReq := httptest.NewRequest("PUT", fmt.Sprintf("/lessons/%d/attendance", lessonID), bytes.NewReader(data))
+    httpReq.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(httpReq)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+
+    var buf bytes.Buffer
+    buf.ReadFrom(resp.Body)
+    return resp.StatusCode, buf.Bytes()
+}
+
+func TestSaveLessonAttendance(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    other := createTestUser(t, "lain", models.RoleTeacher)
+    tanggal := testDate("2026-10-05")
+    tahunAjaran := models.TahunAjaranOf(tanggal)
+    andi := createTestStudent(t, "5001", "Andi", "7A", map[string]string{tahunAjaran: "7A"})
+    budi := createTestStudent(t, "5002", "Budi", "7A", map[string]string{tahunAjaran: "7A"})
+    citra := createTestStudent(t, "5003", "Citra", "7A", map[string]string{tahunAjaran: "7A"})
+    outsider := createTestStudent(t, "5004", "Dodi", "7B", map[string]string{tahunAjaran: "7B"})
+    lesson := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: tanggal, CreatedByID: guru.ID,
+    })
+    app := attendanceTestApp(guru.ID)
+
+    t.Run("rejects invalid requests", func(t *testing.T) {
+        for name, req := range map[string]SaveAttendanceRequest{
+            "empty":          {},
+            "unknown status": {DefaultStatus: "terlambat"},
+            "other class":    {Records: []AttendanceRecordRequest{{StudentID: outsider.ID, Status: models.AttendanceHadir}}},
+        } {
+            if status, body := saveTestAttendance(t, app, lesson.ID, req); status != fiber.StatusBadRequest {
+                t.Errorf("%s: status = %d (%s), want 400", name, status, body)
+            }
+        }
+        if status, _ := saveTestAttendance(t, attendanceTestApp(other.ID), lesson.ID, SaveAttendanceRequest{DefaultStatus: models.AttendanceHadir}); status != fiber.StatusForbidden {
+            t.Errorf("other teacher status = %d, want 403", status)
+        }
+    })
+
+    t.Run("default status fills unrecorded students", func(t *testing.T) {
+        req := SaveAttendanceRequest{
+            DefaultStatus: models.AttendanceHadir,
+            Records:       []AttendanceRecordRequest{{StudentID: budi.ID, Status: "Sakit", Keterangan: " demam "}},
+        }
+        status, body := saveTestAttendance(t, app, lesson.ID, req)
+        if status != fiber.StatusOK {
+            t.Fatalf("status = %d (%s), want 200", status, body)
+        }
+
+        var result struct {
+            Data []AttendanceEntry `json:"data"`
+        }
+        json.Unmarshal(body, &result)
+        want := map[uint]string{andi.ID: models.AttendanceHadir, budi.ID: models.AttendanceSakit, citra.ID: models.AttendanceHadir}
+        if len(result.Data) != len(want) {
+            t.Fatalf("entries = %+v, want the 7A roster", result.Data)
+        }
+        for _, entry := range result.Data {
+            if entry.Status != want[entry.StudentID] {
+                t.Errorf("%s status = %q, want %q", entry.Nama, entry.Status, want[entry.StudentID])
+            }
+            if entry.StudentID == budi.ID && entry.Keterangan != "demam" {
+                t.Errorf("keterangan = %q, want trimmed demam", entry.Keterangan)
+            }
+        }
+    })
+
+    t.Run("default status keeps recorded students", func(t *testing.T) {
+        req := SaveAttendanceRequest{
+            DefaultStatus: models.AttendanceAlpa,
+            Records:       []AttendanceRecordRequest{{StudentID: citra.ID, Status: models.AttendanceIzin}},
+        }
+        if status, body := saveTestAttendance(t, app, lesson.ID, req); status != fiber.StatusOK {
+            t.Fatalf("status = %d (%s), want 200", status, body)
+        }
+
+        var records []models.Attendance
+        database.DB.Where("lesson_id = ?", lesson.ID).Order("student_id ASC").Find(&records)
+        if len(records) != 3 {
+            t.Fatalf("records = %d, want one per student", len(records))
+        }
+        if records[0].Status != models.AttendanceHadir || records[1].Status != models.AttendanceSakit || records[2].Status != models.AttendanceIzin {
+            t.Fatalf("records = %+v, want hadir, sakit, izin", records)
+        }
+    })
+}
+
+func TestGetClassAttendanceRecap(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    andi := createTestStudent(t, "6001", "Andi", "7A", nil)
+    budi := createTestStudent(t, "6002", "Budi", "7A", nil)
+
+    // Dua pertemuan Oktober dan satu pertemuan September yang tidak ikut dihitung
+    for _, record := range []struct {
+        tanggal string
+        andi    string
+        budi    string
+    }{
+        {"2026-10-05", models.AttendanceHadir, models.AttendanceHadir},
+        {"2026-10-06", models.AttendanceHadir, models.AttendanceAlpa},
+        {"2026-09-28", models.AttendanceSakit, models.AttendanceSakit},
+    } {
+        lesson := createTestLesson(t, models.DailyLesson{
+            NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate(record.tanggal), CreatedByID: guru.ID,
+        })
+        database.DB.Create(&[]models.Attendance{
+            {LessonID: lesson.ID, StudentID: andi.ID, Status: record.andi, RecordedByID: guru.ID},
+            {LessonID: lesson.ID, StudentID: budi.ID, Status: record.budi, RecordedByID: guru.ID},
+        })
+    }
+
+    resp, err := attendanceTestApp(guru.ID).Test(httptest.NewRequest("GET", "/reports/attendance/classes?month=2026-10", nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    var result struct {
+        Month string            `json:"month"`
+        Data  []classAttendance `json:"data"`
+    }
+    json.NewDecoder(resp.Body).Decode(&result)
+    if result.Month != "2026-10" || len(result.Data) != 1 {
+        t.Fatalf("result = %+v, want one class for 2026-10", result)
+    }
+
+    recap := result.Data[0]
+    if recap.JumlahSiswa != 2 || recap.Pertemuan != 2 || recap.Hadir != 3 || recap.Alpa != 1 || recap.Sakit != 0 || recap.Persentase != 75 {
+        t.Fatalf("recap = %+v, want 2 students, 2 meetings, 75%% present", recap)
+    }
+
+    resp, err = attendanceTestApp(guru.ID).Test(httptest.NewRequest("GET", "/reports/attendance/classes?month=10-2026", nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusBadRequest {
+        t.Errorf("invalid month status = %d, want 400", resp.StatusCode)
+    }
+}
@@ -1,7 +1,11 @@
 package handlers
 
 import (
+    "fmt"
+    "strings"
+
     "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
     "daily-lesson-api/database"
     "daily-lesson-api/models"
     "daily-lesson-api/utils"
@@ -12,11 +16,23 @@ type LoginRequest struct {
     Password string `json:"password"`
 }
 
+// RegisterRequest tidak menerima role maupun departemen. Akun baru selalu guru; role diatur
+// admin lewat UpdateUserRole dan departemen lewat UpdateUserDepartemen.
 type RegisterRequest struct {
-    Name     string          `json:"name"`
-    Email    string          `json:"email"`
-    Password string          `json:"password"`
-    Role     models.UserRole `json:"role"`
+    Name     string `json:"name"`
+    Email    string `json:"email"`
+    Password string `json:"password"`
+}
+
+// userSummaryColumns membatasi kolom user yang ikut di-preload pada relasi agar email dan
+// data akun lain tidak ikut terkirim
+func userSummaryColumns(db *gorm.DB) *gorm.DB {
+    return db.Select("id", "name", "role")
+}
+
+// ownerSummaryColumns sama dengan userSummaryColumns ditambah departemen, dipakai untuk pemilik modul ajar
+func ownerSummaryColumns(db *gorm.DB) *gorm.DB {
+    return db.Select("id", "name", "role", "departemen")
 }
 
 func Register(c *fiber.Ctx) error {
@@ -32,7 +48,7 @@ func Register(c *fiber.Ctx) error {
         Name:     req.Name,
         Email:    req.Email,
         Password: req.Password,
-        Role:     req.Role,
+        Role:     models.RoleTeacher,
     }
     
     if err := user.HashPassword(); err != nil {
@@ -57,10 +73,11 @@ func Register(c *fiber.Ctx) error {
     return c.JSON(fiber.Map{
         "token": token,
         "user": fiber.Map{
-            "id":    user.ID,
-            "name":  user.Name,
-            "email": user.Email,
-            "role":  user.Role,
+            "id":         user.ID,
+            "name":       user.Name,
+            "email":      user.Email,
+            "role":       user.Role,
+            "departemen": user.Departemen,
         },
     })
 }
@@ -97,10 +114,11 @@ func Login(c *fiber.Ctx) error {
     return c.JSON(fiber.Map{
         "token": token,
         "user": fiber.Map{
-            "id":    user.ID,
-            "name":  user.Name,
-            "email": user.Email,
-            "role":  user.Role,
+            "id":         user.ID,
+            "name":       user.Name,
+            "email":      user.Email,
+            "role":       user.Role,
+            "departemen": user.Departemen,
         },
     })
 }
@@ -116,9 +134,108 @@ func GetProfile(c *fiber.Ctx) error {
     }
     
     return c.JSON(fiber.Map{
-        "id":    user.ID,
-        "name":  user.Name,
-        "email": user.Email,
-        "role":  user.Role,
+        "id":         user.ID,
+        "name":       user.Name,
+        "email":      user.Email,
+        "role":       user.Role,
+        "departemen": user.Departemen,
     })
-}
\ No newline at end of file
+}
+
+type UpdateDepartemenRequest struct {
+    Departemen string `json:"departemen"`
+}
+
+// UpdateUserDepartemen mengatur departemen seorang user (admin)
+func UpdateUserDepartemen(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var user models.User
+    var req UpdateDepartemenRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&user, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "User not found",
+        })
+    }
+
+    user.Departemen = strings.TrimSpace(req.Departemen)
+    if err := database.DB.Model(&user).Update("departemen", user.Departemen).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update user",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Mengatur departemen %s menjadi %q", user.Email, user.Departemen))
+
+    return c.JSON(fiber.Map{
+        "id":         user.ID,
+        "name":       user.Name,
+        "email":      user.Email,
+        "role":       user.Role,
+        "departemen": user.Departemen,
+    })
+}
+
+type UpdateRoleRequest struct {
+    Role models.UserRole `json:"role"`
+}
+
+// UpdateUserRole mengubah role seorang user (admin). Role dibaca dari token, jadi role baru
+// berlaku setelah user login ulang. Admin tidak bisa mengubah role-nya sendiri agar sekolah
+// tidak kehilangan akses admin karena salah klik.
+func UpdateUserRole(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var user models.User
+    var req UpdateRoleRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    switch req.Role {
+    case models.RoleAdmin, models.RoleTeacher, models.RoleSupervisor, models.RoleCounselor:
+    default:
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Role tidak valid",
+        })
+    }
+
+    if err := database.DB.First(&user, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "User not found",
+        })
+    }
+
+    if user.ID == userID && req.Role != user.Role {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Tidak dapat mengubah role akun sendiri",
+        })
+    }
+
+    previous := user.Role
+    user.Role = req.Role
+    if err := database.DB.Model(&user).Update("role", user.Role).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update user",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Mengubah role %s dari %s menjadi %s", user.Email, previous, user.Role))
+
+    return c.JSON(fiber.Map{
+        "id":         user.ID,
+        "name":       user.Name,
+        "email":      user.Email,
+        "role":       user.Role,
+        "departemen": user.Departemen,
+    })
+}
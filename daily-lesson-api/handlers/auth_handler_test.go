@@ -0,0 +1,94 @@
+package handlers
+
+import (
+    "bytes"
+    "fmt"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestRegisterIgnoresDepartemen(t *testing.T) {
+    setupTestDB(t)
+
+    app := fiber.New()
+    app.Post("/register", Register)
+
+    body := `{"name":"Guru Baru","email":"baru@sekolah.test","password":"rahasia123","role":"teacher","departemen":"MIPA"}`
+    req := httptest.NewRequest("POST", "/register", bytes.NewBufferString(body))
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+
+    var user models.User
+    if err := database.DB.Where("email = ?", "baru@sekolah.test").First(&user).Error; err != nil {
+        t.Fatalf("load user: %v", err)
+    }
+    if user.Departemen != "" {
+        t.Errorf("departemen = %q, want empty until set by admin", user.Departemen)
+    }
+}
+
+func TestRegisterAlwaysCreatesTeacher(t *testing.T) {
+    setupTestDB(t)
+
+    app := fiber.New()
+    app.Post("/register", Register)
+
+    body := `{"name":"Penyusup","email":"penyusup@sekolah.test","password":"rahasia123","role":"admin"}`
+    req := httptest.NewRequest("POST", "/register", bytes.NewBufferString(body))
+    req.Header.Set("Content-Type", "application/json")
+    if resp, err := app.Test(req); err != nil || resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("register: status %v, err %v", resp.StatusCode, err)
+    }
+
+    var user models.User
+    database.DB.Where("email = ?", "penyusup@sekolah.test").First(&user)
+    if user.Role != models.RoleTeacher {
+        t.Errorf("role = %q, want teacher", user.Role)
+    }
+}
+
+func TestUpdateUserRole(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "Admin", models.RoleAdmin)
+    guru := createTestUser(t, "Guru", models.RoleTeacher)
+
+    app := fiber.New()
+    app.Put("/admin/users/:id/role", func(c *fiber.Ctx) error {
+        c.Locals("userID", admin.ID)
+        c.Locals("email", admin.Email)
+        return UpdateUserRole(c)
+    })
+    put := func(id uint, role string) int {
+        req := httptest.NewRequest("PUT", fmt.Sprintf("/admin/users/%d/role", id), bytes.NewBufferString(`{"role":"`+role+`"}`))
+        req.Header.Set("Content-Type", "application/json")
+        resp, err := app.Test(req)
+        if err != nil {
+            t.Fatalf("request: %v", err)
+        }
+        return resp.StatusCode
+    }
+
+    if status := put(guru.ID, "counselor"); status != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", status)
+    }
+    database.DB.First(&guru, guru.ID)
+    if guru.Role != models.RoleCounselor {
+        t.Errorf("role = %q, want counselor", guru.Role)
+    }
+    if status := put(guru.ID, "kepala"); status != fiber.StatusBadRequest {
+        t.Errorf("invalid role status = %d, want 400", status)
+    }
+    if status := put(admin.ID, "teacher"); status != fiber.StatusBadRequest {
+        t.Errorf("own role status = %d, want 400", status)
+    }
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+type CloneLessonRequest struct {
+    TanggalMengajar string `json:"tanggal_mengajar"`
+    JamMulai        string `json:"jam_mulai"`
+    JamSelesai      string `json:"jam_selesai"`
+    Status          string `json:"status"`
+}
+
+type CopyWeekRequest struct {
+    SourceWeek string `json:"source_week"`
+    TargetWeek string `json:"target_week"`
+    TeacherID  uint   `json:"teacher_id"`
+}
+
+// cloneLesson menyalin isi lesson (termasuk modul ajar yang dipakai) ke tanggal baru tanpa bukti mengajar dan hasil verifikasinya
+func cloneLesson(source models.DailyLesson, tanggal time.Time) models.DailyLesson {
+    return models.DailyLesson{
+        NamaGuru:        source.NamaGuru,
+        MataPelajaran:   source.MataPelajaran,
+        Kelas:           source.Kelas,
+        PokokMateri:     source.PokokMateri,
+        TanggalMengajar: tanggal,
+        JamMulai:        source.JamMulai,
+        JamSelesai:      source.JamSelesai,
+        Status:          models.StatusDraft,
+        Catatan:         source.Catatan,
+        LessonPlanID:    source.LessonPlanID,
+        CreatedByID:     source.CreatedByID,
+    }
+}
+
+// weekStart mengembalikan hari Senin dari minggu tanggal tersebut
+func weekStart(t time.Time) time.Time {
+    offset := (int(t.Weekday()) + 6) % 7
+    return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// CloneLesson menyalin lesson ke tanggal lain sebagai draft
+func CloneLesson(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var source models.DailyLesson
+    var req CloneLessonRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&source, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(source, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat menyalin data sendiri",
+        })
+    }
+
+    tanggal, err := time.Parse("2006-01-02", req.TanggalMengajar)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format tanggal tidak valid. Gunakan format YYYY-MM-DD",
+        })
+    }
+
+    lesson := cloneLesson(source, tanggal)
+    if req.JamMulai != "" {
+        lesson.JamMulai = req.JamMulai
+    }
+    if req.JamSelesai != "" {
+        lesson.JamSelesai = req.JamSelesai
+    }
+    if req.Status != "" {
+        if !lessonStatuses[req.Status] {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("Status %q tidak dikenal", req.Status),
+            })
+        }
+        lesson.Status = req.Status
+    }
+
+    conflicts, ok, err := checkScheduleConflicts(c, lesson)
+    if !ok {
+        return err
+    }
+
+    if err := database.DB.Create(&lesson).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create lesson record",
+        })
+    }
+
+    description := fmt.Sprintf("Catatan mengajar disalin dari lesson #%d", source.ID)
+    recordLessonHistory(lesson, nil, "CLONE", conflictHistoryNote(description, conflicts), userID)
+
+    activityDescription := fmt.Sprintf("Menyalin lesson: %s - %s (%s) ke %s", lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru, req.TanggalMengajar)
+    createActivity(userEmail, "create", activityDescription)
+
+    return c.Status(fiber.StatusCreated).JSON(lesson)
+}
+
+// CopyWeek menyalin semua lesson seorang guru dari satu minggu ke minggu lain sebagai draft.
+// Tanggal tujuan yang sudah memiliki catatan mengajar guru tersebut dilewati.
+func CopyWeek(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var req CopyWeekRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    // Guru hanya bisa menyalin minggunya sendiri; admin/supervisor memilih guru lewat teacher_id
+    teacherID := userID
+    if userRole != "teacher" && req.TeacherID != 0 {
+        teacherID = req.TeacherID
+    }
+
+    sourceDate, err := time.Parse("2006-01-02", req.SourceWeek)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format source_week tidak valid. Gunakan format YYYY-MM-DD",
+        })
+    }
+    source := weekStart(sourceDate)
+
+    target := source.AddDate(0, 0, 7)
+    if req.TargetWeek != "" {
+        targetDate, err := time.Parse("2006-01-02", req.TargetWeek)
+        if err != nil {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "Format target_week tidak valid. Gunakan format YYYY-MM-DD",
+            })
+        }
+        target = weekStart(targetDate)
+    }
+
+    if target.Equal(source) {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Minggu tujuan harus berbeda dengan minggu sumber",
+        })
+    }
+
+    var lessons []models.DailyLesson
+    if err := database.DB.Where("created_by_id = ? AND date(tanggal_mengajar) BETWEEN ? AND ?",
+        teacherID, source.Format("2006-01-02"), source.AddDate(0, 0, 6).Format("2006-01-02")).
+        Order("tanggal_mengajar ASC, jam_mulai ASC").Find(&lessons).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lessons",
+        })
+    }
+
+    var existingDates []string
+    if err := database.DB.Model(&models.DailyLesson{}).
+        Where("created_by_id = ? AND date(tanggal_mengajar) BETWEEN ? AND ?",
+            teacherID, target.Format("2006-01-02"), target.AddDate(0, 0, 6).Format("2006-01-02")).
+        Distinct().Pluck("date(tanggal_mengajar)", &existingDates).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lessons",
+        })
+    }
+
+    occupied := map[string]bool{}
+    for _, date := range existingDates {
+        occupied[date] = true
+    }
+
+    offset := int(target.Sub(source).Hours() / 24)
+    created := []models.DailyLesson{}
+    skippedDates := []string{}
+    skipped := map[string]bool{}
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        for _, lesson := range lessons {
+            tanggal := lesson.TanggalMengajar.AddDate(0, 0, offset)
+            date := tanggal.Format("2006-01-02")
+            if occupied[date] {
+                if !skipped[date] {
+                    skipped[date] = true
+                    skippedDates = append(skippedDates, date)
+                }
+                continue
+            }
+
+            draft := cloneLesson(lesson, tanggal)
+            if err := tx.Create(&draft).Error; err != nil {
+                return err
+            }
+
+            history := newLessonHistory(draft, nil, "CLONE", fmt.Sprintf("Catatan mengajar disalin dari lesson #%d (salin minggu)", lesson.ID), userID)
+            if err := tx.Create(&history).Error; err != nil {
+                return err
+            }
+
+            created = append(created, draft)
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not copy lesson records",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Menyalin %d lesson dari minggu %s ke minggu %s",
+        len(created), source.Format("2006-01-02"), target.Format("2006-01-02"))
+    createActivity(userEmail, "create", activityDescription)
+
+    return c.JSON(fiber.Map{
+        "source_week":   source.Format("2006-01-02"),
+        "target_week":   target.Format("2006-01-02"),
+        "created":       len(created),
+        "skipped_dates": skippedDates,
+        "data":          created,
+    })
+}
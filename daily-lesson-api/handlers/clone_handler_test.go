@@ -0,0 +1,97 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func cloneTestRequest(t *testing.T, userID uint, path string, body interface{}) (int, []byte) {
+    t.Helper()
+
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleTeacher))
+        c.Locals("email", "guru@sekolah.test")
+        return c.Next()
+    })
+    app.Post("/lessons/copy-week", CopyWeek)
+    app.Post("/lessons/:id/clone", CloneLesson)
+
+    data, _ := json.Marshal(body)
+    req := httptest.NewRequest("POST", path, bytes.NewReader(data))
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+
+    var buf bytes.Buffer
+    buf.ReadFrom(resp.Body)
+    return resp.StatusCode, buf.Bytes()
+}
+
+func TestCloneLessonAllowsSubstitute(t *testing.T) {
+    setupTestDB(t)
+    guruA := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    guruB := createTestUser(t, "Pak Budi", models.RoleTeacher)
+    guruC := createTestUser(t, "Bu Citra", models.RoleTeacher)
+    source := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guruA.ID,
+        GuruPengganti: "Pak Budi", GuruPenggantiID: &guruB.ID, PokokMateri: "Pecahan",
+    })
+    path := fmt.Sprintf("/lessons/%d/clone", source.ID)
+    req := CloneLessonRequest{TanggalMengajar: "2026-10-12"}
+
+    if status, _ := cloneTestRequest(t, guruC.ID, path, req); status != fiber.StatusForbidden {
+        t.Fatalf("other teacher status = %d, want 403", status)
+    }
+
+    status, body := cloneTestRequest(t, guruB.ID, path, req)
+    if status != fiber.StatusCreated {
+        t.Fatalf("substitute status = %d (%s), want 201", status, body)
+    }
+    var clone models.DailyLesson
+    json.Unmarshal(body, &clone)
+    if clone.Status != models.StatusDraft || clone.CreatedByID != guruA.ID || clone.GuruPenggantiID != nil || clone.PokokMateri != "Pecahan" {
+        t.Fatalf("clone = %+v, want a draft of the original teacher without substitute", clone)
+    }
+}
+
+func TestCopyWeekSkipsOccupiedDates(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    for _, date := range []string{"2026-10-05", "2026-10-06", "2026-10-13"} {
+        createTestLesson(t, models.DailyLesson{
+            NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate(date), CreatedByID: guru.ID,
+        })
+    }
+
+    status, body := cloneTestRequest(t, guru.ID, "/lessons/copy-week", CopyWeekRequest{SourceWeek: "2026-10-07"})
+    if status != fiber.StatusOK {
+        t.Fatalf("status = %d (%s), want 200", status, body)
+    }
+
+    var result struct {
+        TargetWeek   string   `json:"target_week"`
+        Created      int      `json:"created"`
+        SkippedDates []string `json:"skipped_dates"`
+    }
+    json.Unmarshal(body, &result)
+    if result.TargetWeek != "2026-10-12" || result.Created != 1 || len(result.SkippedDates) != 1 || result.SkippedDates[0] != "2026-10-13" {
+        t.Fatalf("result = %+v, want one copy on 2026-10-12 and 2026-10-13 skipped", result)
+    }
+
+    var histories int64
+    database.DB.Model(&models.LessonReport{}).Where("action = ?", "CLONE").Count(&histories)
+    if histories != 1 {
+        t.Errorf("clone histories = %d, want 1", histories)
+    }
+}
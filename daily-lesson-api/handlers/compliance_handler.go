@@ -0,0 +1,178 @@
+package handlers
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// TeacherCompliance adalah rekap kepatuhan pengisian catatan mengajar satu guru.
+// Lesson yang diajar guru pengganti dan lesson pada hari izin yang disetujui tidak dihitung
+// sebagai kewajiban guru asli; lesson pengganti dicatat sebagai tambahan bagi guru pengganti.
+type TeacherCompliance struct {
+    TeacherID          uint    `json:"teacher_id"`
+    NamaGuru           string  `json:"nama_guru"`
+    TotalCatatan       int64   `json:"total_catatan"`
+    Terlaksana         int64   `json:"terlaksana"`
+    Diganti            int64   `json:"diganti"`
+    Mengganti          int64   `json:"mengganti"`
+    Izin               int64   `json:"izin"`
+    HariIzin           int     `json:"hari_izin"`
+    BuktiTerverifikasi int64   `json:"bukti_terverifikasi"`
+    SlotTerbuka        int64   `json:"slot_pengganti_terbuka"`
+    Wajib              int64   `json:"wajib"`
+    Persentase         float64 `json:"persentase_terlaksana"`
+}
+
+type complianceCount struct {
+    TeacherID          uint
+    TotalCatatan       int64
+    Terlaksana         int64
+    Diganti            int64
+    Izin               int64
+    BuktiTerverifikasi int64
+}
+
+type teacherCount struct {
+    TeacherID uint
+    Total     int64
+}
+
+// onLeaveCondition bernilai benar jika lesson jatuh pada izin guru pemiliknya yang disetujui
+const onLeaveCondition = `EXISTS (SELECT 1 FROM leave_requests lr
+    WHERE lr.teacher_id = daily_lessons.created_by_id AND lr.status = ? AND lr.deleted_at IS NULL
+    AND date(daily_lessons.tanggal_mengajar) BETWEEN date(lr.tanggal_mulai) AND date(lr.tanggal_selesai))`
+
+// complianceRange membaca start_date dan end_date, default bulan berjalan
+func complianceRange(c *fiber.Ctx) (string, string, error) {
+    startDate, endDate := c.Query("start_date"), c.Query("end_date")
+    if startDate == "" && endDate == "" {
+        now := time.Now()
+        start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+        return start.Format("2006-01-02"), start.AddDate(0, 1, -1).Format("2006-01-02"), nil
+    }
+
+    for _, value := range []string{startDate, endDate} {
+        if _, err := time.Parse("2006-01-02", value); err != nil {
+            return "", "", fmt.Errorf("start_date dan end_date harus berformat YYYY-MM-DD")
+        }
+    }
+    return startDate, endDate, nil
+}
+
+// GetComplianceReport menampilkan rekap kepatuhan pengisian catatan mengajar per guru
+func GetComplianceReport(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    startDate, endDate, err := complianceRange(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    var teachers []models.User
+    if err := database.DB.Where("role = ?", models.RoleTeacher).Order("name ASC").Find(&teachers).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch compliance report",
+        })
+    }
+
+    lessonsInRange := func() *gorm.DB {
+        return database.DB.Model(&models.DailyLesson{}).
+            Where("date(tanggal_mengajar) BETWEEN ? AND ? AND status <> ?", startDate, endDate, models.StatusDraft)
+    }
+
+    var counts []complianceCount
+    if err := lessonsInRange().
+        Select(`created_by_id AS teacher_id,
+            count(*) AS total_catatan,
+            sum(CASE WHEN status = ? AND guru_pengganti_id IS NULL THEN 1 ELSE 0 END) AS terlaksana,
+            sum(CASE WHEN guru_pengganti_id IS NOT NULL THEN 1 ELSE 0 END) AS diganti,
+            sum(CASE WHEN guru_pengganti_id IS NULL AND `+onLeaveCondition+` THEN 1 ELSE 0 END) AS izin,
+            sum(CASE WHEN status = ? AND guru_pengganti_id IS NULL AND verifikasi_bukti = ? THEN 1 ELSE 0 END) AS bukti_terverifikasi`,
+            models.StatusTerlaksana, models.LeaveApproved, models.StatusTerlaksana, models.VerifikasiSesuai).
+        Group("created_by_id").Scan(&counts).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch compliance report",
+        })
+    }
+
+    var substituting []teacherCount
+    if err := lessonsInRange().
+        Select("guru_pengganti_id AS teacher_id, count(*) AS total").
+        Where("guru_pengganti_id IS NOT NULL AND status = ?", models.StatusTerlaksana).
+        Group("guru_pengganti_id").Scan(&substituting).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch compliance report",
+        })
+    }
+
+    var openSlots []teacherCount
+    if err := database.DB.Model(&models.Substitution{}).
+        Select("original_teacher_id AS teacher_id, count(*) AS total").
+        Where("status = ? AND date(tanggal) BETWEEN ? AND ?", models.SubstitutionOpen, startDate, endDate).
+        Group("original_teacher_id").Scan(&openSlots).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch compliance report",
+        })
+    }
+
+    teacherIDs := make([]uint, len(teachers))
+    for i, teacher := range teachers {
+        teacherIDs[i] = teacher.ID
+    }
+    leaveDays, err := approvedLeaveDays(teacherIDs, startDate, endDate)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch compliance report",
+        })
+    }
+
+    countByTeacher := map[uint]complianceCount{}
+    for _, count := range counts {
+        countByTeacher[count.TeacherID] = count
+    }
+    substitutingByTeacher := map[uint]int64{}
+    for _, count := range substituting {
+        substitutingByTeacher[count.TeacherID] = count.Total
+    }
+    openByTeacher := map[uint]int64{}
+    for _, count := range openSlots {
+        openByTeacher[count.TeacherID] = count.Total
+    }
+
+    report := make([]TeacherCompliance, 0, len(teachers))
+    for _, teacher := range teachers {
+        count := countByTeacher[teacher.ID]
+        row := TeacherCompliance{
+            TeacherID:          teacher.ID,
+            NamaGuru:           teacher.Name,
+            TotalCatatan:       count.TotalCatatan,
+            Terlaksana:         count.Terlaksana,
+            Diganti:            count.Diganti,
+            Mengganti:          substitutingByTeacher[teacher.ID],
+            BuktiTerverifikasi: count.BuktiTerverifikasi,
+            Izin:               count.Izin,
+            HariIzin:           len(leaveDays[teacher.ID]),
+            SlotTerbuka:        openByTeacher[teacher.ID],
+            Wajib:              count.TotalCatatan - count.Diganti - count.Izin,
+        }
+        if row.Wajib > 0 {
+            row.Persentase = float64(row.Terlaksana*10000/row.Wajib) / 100
+        }
+        report = append(report, row)
+    }
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat laporan kepatuhan guru dari %s sampai %s", startDate, endDate))
+
+    return c.JSON(fiber.Map{
+        "start_date": startDate,
+        "end_date":   endDate,
+        "data":       report,
+    })
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// ScheduleConflict adalah lesson lain yang jamnya bertabrakan dengan lesson yang disimpan
+type ScheduleConflict struct {
+    LessonID      uint   `json:"lesson_id"`
+    Type          string `json:"type"`
+    NamaGuru      string `json:"nama_guru"`
+    MataPelajaran string `json:"mata_pelajaran"`
+    Kelas         string `json:"kelas"`
+    JamMulai      string `json:"jam_mulai"`
+    JamSelesai    string `json:"jam_selesai"`
+}
+
+// findScheduleConflicts mencari lesson pada tanggal yang sama yang jamnya tumpang tindih,
+// baik yang diajar guru yang sama maupun di kelas yang sama. Lesson yang dibatalkan, tidak
+// terlaksana atau masih draft diabaikan, begitu juga lesson tanpa jam yang valid.
+func findScheduleConflicts(db *gorm.DB, lesson models.DailyLesson) ([]ScheduleConflict, error) {
+    conflicts := []ScheduleConflict{}
+    if lesson.Status == models.StatusDibatalkan || lesson.Status == models.StatusTidakTerlaksana {
+        return conflicts, nil
+    }
+
+    start, okStart := parseClock(lesson.JamMulai)
+    end, okEnd := parseClock(lesson.JamSelesai)
+    if !okStart || !okEnd || end <= start {
+        return conflicts, nil
+    }
+
+    var candidates []models.DailyLesson
+    err := db.
+        Where("id <> ? AND date(tanggal_mengajar) = ? AND status NOT IN ?",
+            lesson.ID, lesson.TanggalMengajar.Format("2006-01-02"),
+            []string{models.StatusDibatalkan, models.StatusTidakTerlaksana, models.StatusDraft}).
+        Order("jam_mulai ASC").Find(&candidates).Error
+    if err != nil {
+        return nil, err
+    }
+    if len(candidates) == 0 {
+        return conflicts, nil
+    }
+
+    roles, err := creatorRoles(db, append(candidates, lesson))
+    if err != nil {
+        return nil, err
+    }
+
+    for _, other := range candidates {
+        if conflict, ok := scheduleConflict(lesson, other, roles); ok {
+            conflicts = append(conflicts, conflict)
+        }
+    }
+    return conflicts, nil
+}
+
+// scheduleConflict memeriksa apakah other bentrok dengan lesson memakai aturan yang sama
+// dengan findScheduleConflicts, untuk lesson yang belum tersimpan di database
+func scheduleConflict(lesson, other models.DailyLesson, roles map[uint]models.UserRole) (ScheduleConflict, bool) {
+    if lesson.Status == models.StatusDibatalkan || lesson.Status == models.StatusTidakTerlaksana {
+        return ScheduleConflict{}, false
+    }
+    switch other.Status {
+    case models.StatusDibatalkan, models.StatusTidakTerlaksana, models.StatusDraft:
+        return ScheduleConflict{}, false
+    }
+    if lesson.TanggalMengajar.Format("2006-01-02") != other.TanggalMengajar.Format("2006-01-02") {
+        return ScheduleConflict{}, false
+    }
+
+    start, okStart := parseClock(lesson.JamMulai)
+    end, okEnd := parseClock(lesson.JamSelesai)
+    otherStart, okOtherStart := parseClock(other.JamMulai)
+    otherEnd, okOtherEnd := parseClock(other.JamSelesai)
+    if !okStart || !okEnd || end <= start || !okOtherStart || !okOtherEnd || start >= otherEnd || otherStart >= end {
+        return ScheduleConflict{}, false
+    }
+
+    conflictType := ""
+    if sharesTeacher(lessonTeacherKeys(lesson, roles), lessonTeacherKeys(other, roles)) {
+        conflictType = "guru"
+    } else if strings.EqualFold(other.Kelas, lesson.Kelas) {
+        conflictType = "kelas"
+    } else {
+        return ScheduleConflict{}, false
+    }
+
+    return ScheduleConflict{
+        LessonID:      other.ID,
+        Type:          conflictType,
+        NamaGuru:      other.NamaGuru,
+        MataPelajaran: other.MataPelajaran,
+        Kelas:         other.Kelas,
+        JamMulai:      other.JamMulai,
+        JamSelesai:    other.JamSelesai,
+    }, true
+}
+
+// creatorRoles memuat role pembuat setiap lesson, dipakai untuk menentukan guru yang mengajar
+func creatorRoles(db *gorm.DB, lessons []models.DailyLesson) (map[uint]models.UserRole, error) {
+    ids := make([]uint, len(lessons))
+    for i, lesson := range lessons {
+        ids[i] = lesson.CreatedByID
+    }
+
+    var users []models.User
+    if err := db.Select("id", "role").Where("id IN ?", ids).Find(&users).Error; err != nil {
+        return nil, err
+    }
+
+    roles := map[uint]models.UserRole{}
+    for _, user := range users {
+        roles[user.ID] = user.Role
+    }
+    return roles, nil
+}
+
+// lessonTeacherKeys mengembalikan identitas guru yang benar-benar mengajar lesson: guru
+// pengganti jika ada, atau pembuat lesson jika ia guru. Lesson yang diisi admin/supervisor
+// untuk guru lain dikenali dari nama gurunya.
+func lessonTeacherKeys(lesson models.DailyLesson, roles map[uint]models.UserRole) []string {
+    if lesson.GuruPenggantiID != nil {
+        keys := []string{fmt.Sprintf("user:%d", *lesson.GuruPenggantiID)}
+        if name := strings.TrimSpace(lesson.GuruPengganti); name != "" {
+            keys = append(keys, "nama:"+strings.ToLower(name))
+        }
+        return keys
+    }
+
+    var keys []string
+    if roles[lesson.CreatedByID] == models.RoleTeacher {
+        keys = append(keys, fmt.Sprintf("user:%d", lesson.CreatedByID))
+    }
+    if name := strings.TrimSpace(lesson.NamaGuru); name != "" {
+        keys = append(keys, "nama:"+strings.ToLower(name))
+    }
+    return keys
+}
+
+func sharesTeacher(a, b []string) bool {
+    for _, x := range a {
+        for _, y := range b {
+            if x == y {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// checkScheduleConflicts menjalankan deteksi bentrok jadwal sebelum lesson disimpan.
+// Jika ada bentrok tanpa override_conflict=true, response 409 sudah dikirim dan ok bernilai false.
+// Dengan override, bentrok dicatat di activity log dan dikembalikan untuk deskripsi history.
+func checkScheduleConflicts(c *fiber.Ctx, lesson models.DailyLesson) ([]ScheduleConflict, bool, error) {
+    conflicts, err := findScheduleConflicts(database.DB, lesson)
+    if err != nil {
+        return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not check schedule conflicts",
+        })
+    }
+
+    if len(conflicts) == 0 {
+        return conflicts, true, nil
+    }
+
+    if !c.QueryBool("override_conflict", false) {
+        return nil, false, c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error":           "Jadwal bentrok dengan catatan mengajar lain. Gunakan override_conflict=true untuk tetap menyimpan",
+            "conflicting_ids": conflictIDs(conflicts),
+            "conflicts":       conflicts,
+        })
+    }
+
+    userEmail := c.Locals("email").(string)
+    activityDescription := fmt.Sprintf("Mengabaikan bentrok jadwal %s - %s (%s) %s %s-%s dengan lesson %s",
+        lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru, lesson.TanggalMengajar.Format("2006-01-02"),
+        lesson.JamMulai, lesson.JamSelesai, conflictIDList(conflicts))
+    createActivity(userEmail, "override_conflict", activityDescription)
+
+    return conflicts, true, nil
+}
+
+// conflictHistoryNote menambahkan keterangan override bentrok pada deskripsi history
+func conflictHistoryNote(description string, conflicts []ScheduleConflict) string {
+    if len(conflicts) == 0 {
+        return description
+    }
+    return fmt.Sprintf("%s (bentrok jadwal diabaikan: lesson %s)", description, conflictIDList(conflicts))
+}
+
+func conflictIDs(conflicts []ScheduleConflict) []uint {
+    ids := make([]uint, len(conflicts))
+    for i, conflict := range conflicts {
+        ids[i] = conflict.LessonID
+    }
+    return ids
+}
+
+func conflictIDList(conflicts []ScheduleConflict) string {
+    ids := make([]string, len(conflicts))
+    for i, conflict := range conflicts {
+        ids[i] = fmt.Sprintf("#%d", conflict.LessonID)
+    }
+    return strings.Join(ids, ", ")
+}
+
+// applyScheduleUpdate menerapkan field jadwal dari UpdateLessonRequest.updates ke salinan lesson
+// agar bentrok bisa diperiksa sebelum perubahan disimpan
+func applyScheduleUpdate(lesson models.DailyLesson, updateData map[string]interface{}) models.DailyLesson {
+    if value, ok := updateData["jam_mulai"].(string); ok {
+        lesson.JamMulai = value
+    }
+    if value, ok := updateData["jam_selesai"].(string); ok {
+        lesson.JamSelesai = value
+    }
+    if value, ok := updateData["kelas"].(string); ok {
+        lesson.Kelas = value
+    }
+    if value, ok := updateData["status"].(string); ok {
+        lesson.Status = value
+    }
+    if value, ok := updateData["tanggal_mengajar"].(time.Time); ok {
+        lesson.TanggalMengajar = value
+    }
+    return lesson
+}
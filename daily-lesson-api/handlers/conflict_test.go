@@ -0,0 +1,120 @@
+package handlers
+
+import (
+    "testing"
+
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestFindScheduleConflicts(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "admin", models.RoleAdmin)
+    guruA := createTestUser(t, "guru-a", models.RoleTeacher)
+    guruB := createTestUser(t, "guru-b", models.RoleTeacher)
+    date := testDate("2026-10-05")
+
+    // Admin mengisi jadwal dua guru berbeda di kelas berbeda pada jam yang sama
+    adminLesson := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: date,
+        JamMulai: "07:00", JamSelesai: "08:30", CreatedByID: admin.ID,
+    })
+    // Lesson guru A yang diisi guru B sebagai pengganti
+    substituted := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru-a", Kelas: "8A", TanggalMengajar: date,
+        JamMulai: "09:00", JamSelesai: "10:00", CreatedByID: guruA.ID,
+        GuruPengganti: "guru-b", GuruPenggantiID: &guruB.ID,
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru-b", Kelas: "9A", TanggalMengajar: date,
+        JamMulai: "11:00", JamSelesai: "12:00", CreatedByID: guruB.ID, Status: models.StatusDraft,
+    })
+
+    tests := []struct {
+        name   string
+        lesson models.DailyLesson
+        want   map[uint]string
+    }{
+        {
+            name: "admin entry for another teacher is not a teacher conflict",
+            lesson: models.DailyLesson{
+                NamaGuru: "Pak Budi", Kelas: "7B", TanggalMengajar: date,
+                JamMulai: "07:30", JamSelesai: "08:00", CreatedByID: admin.ID,
+            },
+            want: map[uint]string{},
+        },
+        {
+            name: "admin entry for the same teacher name conflicts",
+            lesson: models.DailyLesson{
+                NamaGuru: "bu ani", Kelas: "7C", TanggalMengajar: date,
+                JamMulai: "08:00", JamSelesai: "09:00", CreatedByID: admin.ID,
+            },
+            want: map[uint]string{adminLesson.ID: "guru"},
+        },
+        {
+            name: "same class with a different teacher conflicts",
+            lesson: models.DailyLesson{
+                NamaGuru: "guru-a", Kelas: "7a", TanggalMengajar: date,
+                JamMulai: "08:00", JamSelesai: "09:00", CreatedByID: guruA.ID,
+            },
+            want: map[uint]string{adminLesson.ID: "kelas"},
+        },
+        {
+            name: "substitute timetable is checked",
+            lesson: models.DailyLesson{
+                NamaGuru: "guru-b", Kelas: "9B", TanggalMengajar: date,
+                JamMulai: "09:30", JamSelesai: "10:30", CreatedByID: guruB.ID,
+            },
+            want: map[uint]string{substituted.ID: "guru"},
+        },
+        {
+            name: "original teacher is free while substituted",
+            lesson: models.DailyLesson{
+                NamaGuru: "guru-a", Kelas: "9C", TanggalMengajar: date,
+                JamMulai: "09:30", JamSelesai: "10:30", CreatedByID: guruA.ID,
+            },
+            want: map[uint]string{},
+        },
+        {
+            name: "draft lessons are ignored",
+            lesson: models.DailyLesson{
+                NamaGuru: "guru-b", Kelas: "9A", TanggalMengajar: date,
+                JamMulai: "11:00", JamSelesai: "12:00", CreatedByID: guruB.ID,
+            },
+            want: map[uint]string{},
+        },
+        {
+            name: "cancelled lesson is never checked",
+            lesson: models.DailyLesson{
+                NamaGuru: "bu ani", Kelas: "7A", TanggalMengajar: date, Status: models.StatusDibatalkan,
+                JamMulai: "07:00", JamSelesai: "08:30", CreatedByID: admin.ID,
+            },
+            want: map[uint]string{},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if tt.lesson.Status == "" {
+                tt.lesson.Status = models.StatusTerlaksana
+            }
+            conflicts, err := findScheduleConflicts(database.DB, tt.lesson)
+            if err != nil {
+                t.Fatalf("findScheduleConflicts: %v", err)
+            }
+
+            got := map[uint]string{}
+            for _, conflict := range conflicts {
+                got[conflict.LessonID] = conflict.Type
+            }
+            if len(got) != len(tt.want) {
+                t.Fatalf("conflicts = %v, want %v", got, tt.want)
+            }
+            for id, conflictType := range tt.want {
+                if got[id] != conflictType {
+                    t.Fatalf("conflicts = %v, want %v", got, tt.want)
+                }
+            }
+        })
+    }
+}
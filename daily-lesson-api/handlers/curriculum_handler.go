@@ -0,0 +1,537 @@
+package handlers
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+type TujuanRequest struct {
+    Kode      string `json:"kode"`
+    Deskripsi string `json:"deskripsi"`
+    Urutan    int    `json:"urutan"`
+}
+
+type CapaianRequest struct {
+    MataPelajaran      string          `json:"mata_pelajaran"`
+    Fase               string          `json:"fase"`
+    Elemen             string          `json:"elemen"`
+    Deskripsi          string          `json:"deskripsi"`
+    TujuanPembelajaran []TujuanRequest `json:"tujuan_pembelajaran"`
+}
+
+type LessonObjectivesRequest struct {
+    TujuanPembelajaranIDs []uint `json:"tujuan_pembelajaran_ids"`
+}
+
+// faseByTingkat memetakan tingkat kelas ke fase Kurikulum Merdeka
+var faseByTingkat = map[string]string{
+    "1": "A", "2": "A", "3": "B", "4": "B", "5": "C", "6": "C",
+    "7": "D", "8": "D", "9": "D", "10": "E", "11": "F", "12": "F",
+    "I": "A", "II": "A", "III": "B", "IV": "B", "V": "C", "VI": "C",
+    "VII": "D", "VIII": "D", "IX": "D", "X": "E", "XI": "F", "XII": "F",
+}
+
+// faseForKelas menebak fase dari tingkat di awal nama kelas, misalnya "XI RPL 1" menjadi F
+func faseForKelas(kelas string) string {
+    fields := strings.Fields(strings.ToUpper(kelas))
+    if len(fields) == 0 {
+        return ""
+    }
+    return faseByTingkat[strings.Trim(fields[0], ".-")]
+}
+
+// validFase menerima fase A sampai F
+func validFase(fase string) bool {
+    return len(fase) == 1 && fase >= "A" && fase <= "F"
+}
+
+// GetCapaianPembelajaran menampilkan CP beserta TP-nya, bisa difilter per mata pelajaran dan fase
+func GetCapaianPembelajaran(c *fiber.Ctx) error {
+    var capaian []models.CapaianPembelajaran
+
+    query := database.DB.Preload("TujuanPembelajaran", func(db *gorm.DB) *gorm.DB {
+        return db.Order("urutan ASC, id ASC")
+    })
+    if mapel := c.Query("mata_pelajaran"); mapel != "" {
+        query = query.Where("lower(mata_pelajaran) = lower(?)", mapel)
+    }
+    if fase := c.Query("fase"); fase != "" {
+        query = query.Where("fase = ?", strings.ToUpper(fase))
+    } else if kelas := c.Query("kelas"); kelas != "" {
+        query = query.Where("fase = ?", faseForKelas(kelas))
+    }
+
+    if err := query.Order("mata_pelajaran ASC, fase ASC, id ASC").Find(&capaian).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch curriculum",
+        })
+    }
+
+    return c.JSON(capaian)
+}
+
+// CreateCapaianPembelajaran menambahkan CP baru sekaligus daftar TP-nya
+func CreateCapaianPembelajaran(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req CapaianRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    req.MataPelajaran = strings.TrimSpace(req.MataPelajaran)
+    req.Fase = strings.ToUpper(strings.TrimSpace(req.Fase))
+    if req.MataPelajaran == "" || !validFase(req.Fase) || strings.TrimSpace(req.Deskripsi) == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "mata_pelajaran, fase (A-F) dan deskripsi wajib diisi",
+        })
+    }
+
+    capaian := models.CapaianPembelajaran{
+        MataPelajaran: req.MataPelajaran,
+        Fase:          req.Fase,
+        Elemen:        strings.TrimSpace(req.Elemen),
+        Deskripsi:     strings.TrimSpace(req.Deskripsi),
+        CreatedByID:   userID,
+    }
+    for i, tujuan := range req.TujuanPembelajaran {
+        if strings.TrimSpace(tujuan.Deskripsi) == "" {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("tujuan_pembelajaran[%d]: deskripsi wajib diisi", i),
+            })
+        }
+        if tujuan.Urutan == 0 {
+            tujuan.Urutan = i + 1
+        }
+        capaian.TujuanPembelajaran = append(capaian.TujuanPembelajaran, models.TujuanPembelajaran{
+            Kode:      strings.TrimSpace(tujuan.Kode),
+            Deskripsi: strings.TrimSpace(tujuan.Deskripsi),
+            Urutan:    tujuan.Urutan,
+        })
+    }
+
+    if err := database.DB.Create(&capaian).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create curriculum",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Menambahkan CP %s fase %s dengan %d TP", capaian.MataPelajaran, capaian.Fase, len(capaian.TujuanPembelajaran))
+    createActivity(userEmail, "create", activityDescription)
+
+    return c.Status(fiber.StatusCreated).JSON(capaian)
+}
+
+// UpdateCapaianPembelajaran mengubah mata pelajaran, fase, elemen atau deskripsi CP
+func UpdateCapaianPembelajaran(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var capaian models.CapaianPembelajaran
+    var req CapaianRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&capaian, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Capaian pembelajaran not found",
+        })
+    }
+
+    capaian.MataPelajaran = strings.TrimSpace(valueOr(req.MataPelajaran, capaian.MataPelajaran))
+    capaian.Fase = strings.ToUpper(strings.TrimSpace(valueOr(req.Fase, capaian.Fase)))
+    capaian.Elemen = strings.TrimSpace(valueOr(req.Elemen, capaian.Elemen))
+    capaian.Deskripsi = strings.TrimSpace(valueOr(req.Deskripsi, capaian.Deskripsi))
+    if !validFase(capaian.Fase) {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "fase harus salah satu dari A-F",
+        })
+    }
+
+    if err := database.DB.Save(&capaian).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update curriculum",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Memperbarui CP %s fase %s", capaian.MataPelajaran, capaian.Fase))
+
+    return c.JSON(capaian)
+}
+
+// DeleteCapaianPembelajaran menghapus CP beserta seluruh TP-nya
+func DeleteCapaianPembelajaran(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var capaian models.CapaianPembelajaran
+
+    if err := database.DB.First(&capaian, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Capaian pembelajaran not found",
+        })
+    }
+
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("capaian_id = ?", capaian.ID).Delete(&models.TujuanPembelajaran{}).Error; err != nil {
+            return err
+        }
+        return tx.Delete(&capaian).Error
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete curriculum",
+        })
+    }
+
+    createActivity(userEmail, "delete", fmt.Sprintf("Menghapus CP %s fase %s", capaian.MataPelajaran, capaian.Fase))
+
+    return c.JSON(fiber.Map{
+        "message": "Capaian pembelajaran deleted successfully",
+    })
+}
+
+// CreateTujuanPembelajaran menambahkan TP ke CP yang sudah ada
+func CreateTujuanPembelajaran(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var capaian models.CapaianPembelajaran
+    var req TujuanRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&capaian, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Capaian pembelajaran not found",
+        })
+    }
+
+    if strings.TrimSpace(req.Deskripsi) == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "deskripsi wajib diisi",
+        })
+    }
+
+    if req.Urutan == 0 {
+        var count int64
+        database.DB.Model(&models.TujuanPembelajaran{}).Where("capaian_id = ?", capaian.ID).Count(&count)
+        req.Urutan = int(count) + 1
+    }
+
+    tujuan := models.TujuanPembelajaran{
+        CapaianID: capaian.ID,
+        Kode:      strings.TrimSpace(req.Kode),
+        Deskripsi: strings.TrimSpace(req.Deskripsi),
+        Urutan:    req.Urutan,
+    }
+    if err := database.DB.Create(&tujuan).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create learning objective",
+        })
+    }
+
+    createActivity(userEmail, "create", fmt.Sprintf("Menambahkan TP %s pada CP %s fase %s", tujuan.Kode, capaian.MataPelajaran, capaian.Fase))
+
+    return c.Status(fiber.StatusCreated).JSON(tujuan)
+}
+
+// UpdateTujuanPembelajaran mengubah kode, deskripsi atau urutan TP
+func UpdateTujuanPembelajaran(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var tujuan models.TujuanPembelajaran
+    var req TujuanRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&tujuan, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Tujuan pembelajaran not found",
+        })
+    }
+
+    tujuan.Kode = strings.TrimSpace(valueOr(req.Kode, tujuan.Kode))
+    tujuan.Deskripsi = strings.TrimSpace(valueOr(req.Deskripsi, tujuan.Deskripsi))
+    if req.Urutan != 0 {
+        tujuan.Urutan = req.Urutan
+    }
+
+    if err := database.DB.Save(&tujuan).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update learning objective",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Memperbarui TP %s", tujuan.Kode))
+
+    return c.JSON(tujuan)
+}
+
+// DeleteTujuanPembelajaran menghapus TP; tanda TP pada lesson lama tidak lagi ditampilkan
+func DeleteTujuanPembelajaran(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var tujuan models.TujuanPembelajaran
+
+    if err := database.DB.First(&tujuan, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Tujuan pembelajaran not found",
+        })
+    }
+
+    if err := database.DB.Delete(&tujuan).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete learning objective",
+        })
+    }
+
+    createActivity(userEmail, "delete", fmt.Sprintf("Menghapus TP %s", tujuan.Kode))
+
+    return c.JSON(fiber.Map{
+        "message": "Tujuan pembelajaran deleted successfully",
+    })
+}
+
+// SetLessonObjectives mengganti daftar TP yang dicapai pada satu lesson.
+// TP harus berasal dari CP dengan mata pelajaran yang sama dengan lesson.
+func SetLessonObjectives(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+    var req LessonObjectivesRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.Preload("TujuanPembelajaran").First(&lesson, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Access denied",
+        })
+    }
+
+    tujuan := []models.TujuanPembelajaran{}
+    if len(req.TujuanPembelajaranIDs) > 0 {
+        if err := database.DB.Preload("Capaian").Where("id IN ?", req.TujuanPembelajaranIDs).Find(&tujuan).Error; err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not fetch learning objectives",
+            })
+        }
+    }
+
+    found := map[uint]bool{}
+    for _, tp := range tujuan {
+        found[tp.ID] = true
+        if tp.Capaian == nil || !strings.EqualFold(tp.Capaian.MataPelajaran, lesson.MataPelajaran) {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("TP %d bukan tujuan pembelajaran %s", tp.ID, lesson.MataPelajaran),
+            })
+        }
+    }
+    for _, id := range req.TujuanPembelajaranIDs {
+        if !found[id] {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("TP %d tidak ditemukan", id),
+            })
+        }
+    }
+
+    // Replace ikut mengubah lesson.TujuanPembelajaran, jadi snapshot diambil lebih dulu
+    if lesson.TujuanPembelajaran == nil {
+        lesson.TujuanPembelajaran = []models.TujuanPembelajaran{}
+    }
+    before := lesson.Snapshot()
+    if err := database.DB.Model(&lesson).Association("TujuanPembelajaran").Replace(tujuan); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not save learning objectives",
+        })
+    }
+
+    lesson.TujuanPembelajaran = tujuan
+    if changes := models.DiffSnapshots(before, lesson.Snapshot()); len(changes) > 0 {
+        description := fmt.Sprintf("TP lesson diperbarui (%d TP)", len(tujuan))
+        recordLessonHistory(lesson, &before, "UPDATE_OBJECTIVES", description, userID)
+    }
+
+    activityDescription := fmt.Sprintf("Menandai %d TP pada lesson: %s - %s (%s)", len(tujuan), lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru)
+    createActivity(userEmail, "update", activityDescription)
+
+    for i := range tujuan {
+        tujuan[i].Capaian = nil
+    }
+    return c.JSON(fiber.Map{
+        "lesson_id":           lesson.ID,
+        "tujuan_pembelajaran": tujuan,
+    })
+}
+
+// ObjectiveCoverage adalah ketercapaian satu TP pada satu kelas
+type ObjectiveCoverage struct {
+    ID              uint   `json:"id"`
+    Kode            string `json:"kode"`
+    Deskripsi       string `json:"deskripsi"`
+    Elemen          string `json:"elemen"`
+    JumlahPertemuan int64  `json:"jumlah_pertemuan"`
+    Terakhir        string `json:"terakhir"`
+}
+
+// ClassCoverage adalah ketercapaian TP satu mata pelajaran di satu kelas
+type ClassCoverage struct {
+    Kelas         string              `json:"kelas"`
+    MataPelajaran string              `json:"mata_pelajaran"`
+    Fase          string              `json:"fase"`
+    TotalTP       int                 `json:"total_tp"`
+    Tercapai      int                 `json:"tercapai"`
+    Persentase    float64             `json:"persentase"`
+    Tujuan        []ObjectiveCoverage `json:"tujuan_pembelajaran"`
+}
+
+type coveragePair struct {
+    Kelas         string
+    MataPelajaran string
+}
+
+type objectiveCount struct {
+    TujuanPembelajaranID uint
+    Total                int64
+    Terakhir             string
+}
+
+// tahunAjaranRange mengembalikan rentang tanggal satu tahun ajaran (1 Juli sampai 30 Juni)
+func tahunAjaranRange(tahunAjaran string) (string, string) {
+    start, _ := strconv.Atoi(tahunAjaran[:4])
+    return fmt.Sprintf("%04d-07-01", start), fmt.Sprintf("%04d-06-30", start+1)
+}
+
+// GetCurriculumCoverage menampilkan TP yang sudah dan belum dicapai per kelas dan mata pelajaran
+// dalam satu tahun ajaran. Hanya lesson terlaksana yang dihitung; guru hanya melihat lesson miliknya.
+func GetCurriculumCoverage(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+
+    tahunAjaran := c.Query("tahun_ajaran", models.TahunAjaranOf(time.Now()))
+    if !validTahunAjaran(tahunAjaran) {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "tahun_ajaran harus berformat 2025/2026",
+        })
+    }
+    startDate, endDate := tahunAjaranRange(tahunAjaran)
+
+    lessonsInRange := func() *gorm.DB {
+        query := database.DB.Model(&models.DailyLesson{}).
+            Where("date(daily_lessons.tanggal_mengajar) BETWEEN ? AND ? AND daily_lessons.status = ?",
+                startDate, endDate, models.StatusTerlaksana)
+        if userRole == "teacher" {
+            query = query.Where("daily_lessons.created_by_id = ? OR daily_lessons.guru_pengganti_id = ?", userID, userID)
+        }
+        return query
+    }
+
+    pairsQuery := lessonsInRange().
+        Select("min(kelas) AS kelas, min(mata_pelajaran) AS mata_pelajaran").
+        Group("lower(kelas), lower(mata_pelajaran)").Order("kelas ASC, mata_pelajaran ASC")
+    if kelas := c.Query("kelas"); kelas != "" {
+        pairsQuery = pairsQuery.Where("lower(kelas) = lower(?)", kelas)
+    }
+    if mapel := c.Query("mata_pelajaran"); mapel != "" {
+        pairsQuery = pairsQuery.Where("lower(mata_pelajaran) = lower(?)", mapel)
+    }
+
+    var pairs []coveragePair
+    if err := pairsQuery.Scan(&pairs).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch curriculum coverage",
+        })
+    }
+
+    report := make([]ClassCoverage, 0, len(pairs))
+    for _, pair := range pairs {
+        row := ClassCoverage{
+            Kelas:         pair.Kelas,
+            MataPelajaran: pair.MataPelajaran,
+            Fase:          faseForKelas(pair.Kelas),
+            Tujuan:        []ObjectiveCoverage{},
+        }
+
+        var capaian []models.CapaianPembelajaran
+        if err := database.DB.Preload("TujuanPembelajaran", func(db *gorm.DB) *gorm.DB {
+            return db.Order("urutan ASC, id ASC")
+        }).Where("lower(mata_pelajaran) = lower(?) AND fase = ?", row.MataPelajaran, row.Fase).
+            Order("id ASC").Find(&capaian).Error; err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not fetch curriculum coverage",
+            })
+        }
+
+        var counts []objectiveCount
+        if err := lessonsInRange().
+            Joins("JOIN lesson_objectives ON lesson_objectives.lesson_id = daily_lessons.id").
+            Select("lesson_objectives.tujuan_pembelajaran_id, count(DISTINCT daily_lessons.id) AS total, max(date(daily_lessons.tanggal_mengajar)) AS terakhir").
+            Where("lower(daily_lessons.kelas) = lower(?) AND lower(daily_lessons.mata_pelajaran) = lower(?)", row.Kelas, row.MataPelajaran).
+            Group("lesson_objectives.tujuan_pembelajaran_id").Scan(&counts).Error; err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not fetch curriculum coverage",
+            })
+        }
+        countByObjective := map[uint]objectiveCount{}
+        for _, count := range counts {
+            countByObjective[count.TujuanPembelajaranID] = count
+        }
+
+        for _, cp := range capaian {
+            for _, tp := range cp.TujuanPembelajaran {
+                count := countByObjective[tp.ID]
+                row.Tujuan = append(row.Tujuan, ObjectiveCoverage{
+                    ID:              tp.ID,
+                    Kode:            tp.Kode,
+                    Deskripsi:       tp.Deskripsi,
+                    Elemen:          cp.Elemen,
+                    JumlahPertemuan: count.Total,
+                    Terakhir:        count.Terakhir,
+                })
+                row.TotalTP++
+                if count.Total > 0 {
+                    row.Tercapai++
+                }
+            }
+        }
+        if row.TotalTP > 0 {
+            row.Persentase = float64(row.Tercapai*10000/row.TotalTP) / 100
+        }
+        report = append(report, row)
+    }
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat ketercapaian TP tahun ajaran %s", tahunAjaran))
+
+    return c.JSON(fiber.Map{
+        "tahun_ajaran": tahunAjaran,
+        "start_date":   startDate,
+        "end_date":     endDate,
+        "data":         report,
+    })
+}
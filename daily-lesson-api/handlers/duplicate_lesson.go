@@ -0,0 +1,135 @@
+package handlers
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// findDuplicateLesson mencari lesson yang kemungkinan besar sama: guru (pemilik atau nama),
+// tanggal, kelas dan mata pelajaran sama, dengan jam yang tumpang tindih atau sama-sama tidak diisi.
+// Draft hasil salin minggu tidak dihitung karena belum tentu terlaksana. db bisa berupa
+// transaksi agar baris yang belum di-commit ikut diperiksa.
+func findDuplicateLesson(db *gorm.DB, lesson models.DailyLesson) (*models.DailyLesson, error) {
+    var candidates []models.DailyLesson
+    err := db.
+        Where("date(tanggal_mengajar) = ? AND lower(kelas) = lower(?) AND lower(mata_pelajaran) = lower(?)",
+            lesson.TanggalMengajar.Format("2006-01-02"), lesson.Kelas, lesson.MataPelajaran).
+        Where("created_by_id = ? OR lower(nama_guru) = lower(?)", lesson.CreatedByID, lesson.NamaGuru).
+        Where("status <> ?", models.StatusDraft).
+        Order("id ASC").Find(&candidates).Error
+    if err != nil {
+        return nil, err
+    }
+
+    for i, other := range candidates {
+        if sameLessonTime(lesson, other) {
+            return &candidates[i], nil
+        }
+    }
+    return nil, nil
+}
+
+// isDuplicateLesson memeriksa dua lesson dengan aturan yang sama dengan findDuplicateLesson,
+// untuk lesson yang belum tersimpan di database
+func isDuplicateLesson(lesson, other models.DailyLesson) bool {
+    return other.Status != models.StatusDraft &&
+        lesson.TanggalMengajar.Format("2006-01-02") == other.TanggalMengajar.Format("2006-01-02") &&
+        strings.EqualFold(lesson.Kelas, other.Kelas) &&
+        strings.EqualFold(lesson.MataPelajaran, other.MataPelajaran) &&
+        (lesson.CreatedByID == other.CreatedByID || strings.EqualFold(lesson.NamaGuru, other.NamaGuru)) &&
+        sameLessonTime(lesson, other)
+}
+
+// sameLessonTime membandingkan jam dua lesson. Jam hanya dibandingkan jika keduanya punya jam;
+// lesson tanpa jam hanya dianggap sama dengan lesson lain yang juga tanpa jam.
+func sameLessonTime(lesson, other models.DailyLesson) bool {
+    start, okStart := parseClock(lesson.JamMulai)
+    end, okEnd := parseClock(lesson.JamSelesai)
+    otherStart, okOtherStart := parseClock(other.JamMulai)
+    otherEnd, okOtherEnd := parseClock(other.JamSelesai)
+
+    timed := okStart && okEnd
+    otherTimed := okOtherStart && okOtherEnd
+    if timed && otherTimed {
+        return start < otherEnd && otherStart < end
+    }
+    return !timed && !otherTimed
+}
+
+// mergeDuplicateLesson melengkapi lesson lama dengan isi lesson baru: field kosong diisi,
+// catatan yang berbeda digabung, status dan bukti yang sudah ada tidak diubah
+func mergeDuplicateLesson(existing *models.DailyLesson, incoming models.DailyLesson) {
+    fill := func(target *string, value string) {
+        if *target == "" && value != "" {
+            *target = value
+        }
+    }
+
+    fill(&existing.PokokMateri, incoming.PokokMateri)
+    fill(&existing.BuktiMengajar, incoming.BuktiMengajar)
+    fill(&existing.JamMulai, incoming.JamMulai)
+    fill(&existing.JamSelesai, incoming.JamSelesai)
+
+    if incoming.Catatan != "" && existing.Catatan != incoming.Catatan {
+        if existing.Catatan == "" {
+            existing.Catatan = incoming.Catatan
+        } else {
+            existing.Catatan = existing.Catatan + "\n" + incoming.Catatan
+        }
+    }
+}
+
+// handleDuplicateLesson menolak lesson duplikat dengan 409, atau menggabungkannya ke lesson
+// lama jika on_duplicate=merge. Nilai handled true berarti response sudah dikirim.
+func handleDuplicateLesson(c *fiber.Ctx, lesson models.DailyLesson) (bool, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+
+    duplicate, err := findDuplicateLesson(database.DB, lesson)
+    if err != nil {
+        return true, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not check duplicate lessons",
+        })
+    }
+    if duplicate == nil {
+        return false, nil
+    }
+
+    if c.Query("on_duplicate") != "merge" {
+        return true, c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error":        "Catatan mengajar yang sama sudah ada. Gunakan on_duplicate=merge untuk menggabungkan",
+            "duplicate_id": duplicate.ID,
+            "duplicate":    duplicate,
+        })
+    }
+
+    if userRole == "teacher" && duplicate.CreatedByID != userID {
+        return true, c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error":        "Catatan mengajar yang sama sudah dibuat pengguna lain dan tidak dapat digabung",
+            "duplicate_id": duplicate.ID,
+        })
+    }
+
+    before := duplicate.Snapshot()
+    mergeDuplicateLesson(duplicate, lesson)
+
+    if err := database.DB.Save(duplicate).Error; err != nil {
+        return true, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update lesson record",
+        })
+    }
+
+    recordLessonHistory(*duplicate, &before, "MERGE", "Catatan mengajar duplikat digabungkan", userID)
+
+    activityDescription := fmt.Sprintf("Menggabungkan lesson duplikat: %s - %s (%s)", duplicate.MataPelajaran, duplicate.Kelas, duplicate.NamaGuru)
+    createActivity(userEmail, "update", activityDescription)
+
+    c.Set("X-Lesson-Merged", "true")
+    return true, c.JSON(duplicate)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+    "testing"
+
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestFindDuplicateLesson(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    date := testDate("2026-10-05")
+
+    timed := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: date,
+        JamMulai: "07:00", JamSelesai: "08:00", CreatedByID: guru.ID,
+    })
+    untimed := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "8A", TanggalMengajar: date, CreatedByID: guru.ID,
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "9A", TanggalMengajar: date,
+        JamMulai: "07:00", JamSelesai: "08:00", CreatedByID: guru.ID, Status: models.StatusDraft,
+    })
+
+    tests := []struct {
+        name   string
+        lesson models.DailyLesson
+        want   uint
+    }{
+        {
+            name:   "overlapping time is a duplicate",
+            lesson: models.DailyLesson{Kelas: "7a", JamMulai: "07:30", JamSelesai: "08:30"},
+            want:   timed.ID,
+        },
+        {
+            name:   "later period is not a duplicate",
+            lesson: models.DailyLesson{Kelas: "7A", JamMulai: "08:00", JamSelesai: "09:00"},
+        },
+        {
+            name:   "untimed lesson does not match a timed one",
+            lesson: models.DailyLesson{Kelas: "7A"},
+        },
+        {
+            name:   "timed lesson does not match an untimed one",
+            lesson: models.DailyLesson{Kelas: "8A", JamMulai: "10:00", JamSelesai: "11:00"},
+        },
+        {
+            name:   "both untimed is a duplicate",
+            lesson: models.DailyLesson{Kelas: "8A"},
+            want:   untimed.ID,
+        },
+        {
+            name:   "draft is not a duplicate",
+            lesson: models.DailyLesson{Kelas: "9A", JamMulai: "07:00", JamSelesai: "08:00"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            tt.lesson.NamaGuru = "guru"
+            tt.lesson.MataPelajaran = "Matematika"
+            tt.lesson.TanggalMengajar = date
+            tt.lesson.CreatedByID = guru.ID
+
+            duplicate, err := findDuplicateLesson(database.DB, tt.lesson)
+            if err != nil {
+                t.Fatalf("findDuplicateLesson: %v", err)
+            }
+
+            var got uint
+            if duplicate != nil {
+                got = duplicate.ID
+            }
+            if got != tt.want {
+                t.Fatalf("duplicate = %d, want %d", got, tt.want)
+            }
+        })
+    }
+}
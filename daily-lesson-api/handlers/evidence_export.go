@@ -0,0 +1,266 @@
+package handlers
+
+import (
+    "archive/zip"
+    "bufio"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+var manifestHeader = []string{
+    "id", "tanggal_mengajar", "jam_mulai", "jam_selesai", "nama_guru", "mata_pelajaran",
+    "kelas", "pokok_materi", "status", "catatan", "verifikasi_bukti", "bukti_duplikat", "file_bukti",
+}
+
+// ExportEvidenceZip mengirimkan ZIP berisi seluruh bukti mengajar satu bulan untuk guru, departemen
+// atau mapel tertentu, disusun per tanggal/kelas/mapel beserta manifest.csv. Filter departemen
+// memakai departemen akun guru pembuat lesson atau guru penggantinya. File ditulis langsung ke
+// response satu per satu sehingga tidak perlu menampung seluruh isi ZIP di memori.
+func ExportEvidenceZip(c *fiber.Ctx) error {
+    // Nilai query disalin karena dipakai lagi di dalam stream writer setelah handler selesai
+    month := c.Query("month")
+    guru := strings.Clone(c.Query("guru"))
+    mapel := strings.Clone(c.Query("mapel"))
+    departemen := strings.TrimSpace(strings.Clone(c.Query("departemen")))
+    userEmail := c.Locals("email").(string)
+
+    start, err := time.Parse("2006-01", month)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Parameter month wajib diisi dengan format YYYY-MM",
+        })
+    }
+
+    if guru == "" && mapel == "" && departemen == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Isi parameter guru, departemen atau mapel",
+        })
+    }
+
+    end := start.AddDate(0, 1, 0)
+    buildQuery := func() *gorm.DB {
+        query := database.DB.Model(&models.DailyLesson{}).
+            Where("tanggal_mengajar >= ? AND tanggal_mengajar < ?", start, end)
+        if guru != "" {
+            query = query.Where("nama_guru LIKE ?", "%"+guru+"%")
+        }
+        if mapel != "" {
+            query = query.Where("mata_pelajaran LIKE ?", "%"+mapel+"%")
+        }
+        if departemen != "" {
+            members := database.DB.Model(&models.User{}).Select("id").Where("lower(departemen) = lower(?)", departemen)
+            query = query.Where("created_by_id IN (?) OR guru_pengganti_id IN (?)", members, members)
+        }
+        return query.Order("tanggal_mengajar ASC, jam_mulai ASC, id ASC")
+    }
+
+    var total int64
+    if err := buildQuery().Count(&total).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lessons",
+        })
+    }
+
+    if total == 0 {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Tidak ada catatan mengajar untuk filter tersebut",
+        })
+    }
+
+    label := sanitizeArchiveName(mapel)
+    if guru != "" {
+        label = sanitizeArchiveName(guru)
+    } else if departemen != "" {
+        label = sanitizeArchiveName(departemen)
+    }
+    filename := fmt.Sprintf("bukti-mengajar_%s_%s.zip", label, start.Format("2006-01"))
+
+    c.Set(fiber.HeaderContentType, "application/zip")
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+    // Activity baru dicatat setelah byte terakhir terkirim agar ekspor yang gagal tidak tercatat
+    activityDescription := fmt.Sprintf("Mengekspor bukti mengajar %s bulan %s", label, start.Format("2006-01"))
+    c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+        zw := zip.NewWriter(w)
+
+        if err := writeEvidenceManifest(zw, buildQuery()); err != nil {
+            log.Printf("Failed to write evidence manifest: %v", err)
+            return
+        }
+
+        if err := writeEvidenceFiles(zw, w, buildQuery()); err != nil {
+            log.Printf("Failed to write evidence files: %v", err)
+            return
+        }
+
+        if err := zw.Close(); err != nil {
+            log.Printf("Failed to finish evidence archive: %v", err)
+            return
+        }
+        if err := w.Flush(); err != nil {
+            log.Printf("Failed to send evidence archive: %v", err)
+            return
+        }
+
+        createActivity(userEmail, "export", activityDescription)
+    })
+
+    return nil
+}
+
+// writeEvidenceManifest menulis manifest.csv dengan membaca baris lesson satu per satu
+func writeEvidenceManifest(zw *zip.Writer, query *gorm.DB) error {
+    entry, err := zw.Create("manifest.csv")
+    if err != nil {
+        return err
+    }
+
+    writer := csv.NewWriter(entry)
+    if err := writer.Write(manifestHeader); err != nil {
+        return err
+    }
+
+    rows, err := query.Rows()
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var lesson models.DailyLesson
+        if err := database.DB.ScanRows(rows, &lesson); err != nil {
+            return err
+        }
+
+        file := ""
+        if isUploadedEvidence(lesson.BuktiMengajar) {
+            file = evidenceArchivePath(lesson)
+        }
+
+        if err := writer.Write([]string{
+            fmt.Sprint(lesson.ID),
+            lesson.TanggalMengajar.Format("2006-01-02"),
+            lesson.JamMulai,
+            lesson.JamSelesai,
+            lesson.NamaGuru,
+            lesson.MataPelajaran,
+            lesson.Kelas,
+            lesson.PokokMateri,
+            lesson.Status,
+            lesson.Catatan,
+            lesson.VerifikasiBukti,
+            fmt.Sprint(lesson.BuktiDuplikat),
+            file,
+        }); err != nil {
+            return err
+        }
+    }
+
+    if err := rows.Err(); err != nil {
+        return err
+    }
+
+    writer.Flush()
+    return writer.Error()
+}
+
+// writeEvidenceFiles menyalin setiap file bukti ke dalam ZIP lalu mem-flush response
+func writeEvidenceFiles(zw *zip.Writer, w *bufio.Writer, query *gorm.DB) error {
+    rows, err := query.Rows()
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var lesson models.DailyLesson
+        if err := database.DB.ScanRows(rows, &lesson); err != nil {
+            return err
+        }
+
+        if !isUploadedEvidence(lesson.BuktiMengajar) {
+            continue
+        }
+
+        if err := copyEvidenceFile(zw, lesson); err != nil {
+            log.Printf("Skipping evidence for lesson %d: %v", lesson.ID, err)
+            continue
+        }
+
+        if err := zw.Flush(); err != nil {
+            return err
+        }
+        if err := w.Flush(); err != nil {
+            return err
+        }
+    }
+
+    return rows.Err()
+}
+
+func copyEvidenceFile(zw *zip.Writer, lesson models.DailyLesson) error {
+    src, err := os.Open(lesson.BuktiMengajar)
+    if err != nil {
+        return err
+    }
+    defer src.Close()
+
+    info, err := src.Stat()
+    if err != nil {
+        return err
+    }
+
+    header, err := zip.FileInfoHeader(info)
+    if err != nil {
+        return err
+    }
+    header.Name = evidenceArchivePath(lesson)
+    header.Method = zip.Store // foto JPG/PNG sudah terkompresi
+
+    dst, err := zw.CreateHeader(header)
+    if err != nil {
+        return err
+    }
+
+    _, err = io.Copy(dst, src)
+    return err
+}
+
+// evidenceArchivePath menyusun lokasi file di dalam ZIP: tanggal/kelas/mapel/namafile
+func evidenceArchivePath(lesson models.DailyLesson) string {
+    return strings.Join([]string{
+        lesson.TanggalMengajar.Format("2006-01-02"),
+        sanitizeArchiveName(lesson.Kelas),
+        sanitizeArchiveName(lesson.MataPelajaran),
+        filepath.Base(lesson.BuktiMengajar),
+    }, "/")
+}
+
+// sanitizeArchiveName membuang karakter yang tidak aman untuk nama folder/file
+func sanitizeArchiveName(name string) string {
+    name = strings.TrimSpace(name)
+    var b strings.Builder
+    for _, r := range name {
+        switch {
+        case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+            b.WriteRune(r)
+        case r == ' ' || r == '.':
+            b.WriteRune('_')
+        }
+    }
+    if b.Len() == 0 {
+        return "lainnya"
+    }
+    return b.String()
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestExportEvidenceZipByDepartemen(t *testing.T) {
+    setupTestDB(t)
+    mipa := createTestUser(t, "Guru MIPA", models.RoleTeacher)
+    ips := createTestUser(t, "Guru IPS", models.RoleTeacher)
+    database.DB.Model(&mipa).Update("departemen", "MIPA")
+    database.DB.Model(&ips).Update("departemen", "IPS")
+
+    own := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru MIPA", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: mipa.ID})
+    substituted := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru IPS", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: ips.ID, GuruPenggantiID: &mipa.ID})
+    createTestLesson(t, models.DailyLesson{NamaGuru: "Guru IPS", Kelas: "7C", TanggalMengajar: testDate("2026-10-07"), CreatedByID: ips.ID, MataPelajaran: "Sejarah"})
+
+    app := fiber.New()
+    app.Get("/reports/evidence-export", func(c *fiber.Ctx) error {
+        c.Locals("email", "supervisor@sekolah.test")
+        return ExportEvidenceZip(c)
+    })
+
+    resp, err := app.Test(httptest.NewRequest("GET", "/reports/evidence-export?month=2026-10&departemen=mipa", nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+    body, _ := io.ReadAll(resp.Body)
+
+    archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+    if err != nil {
+        t.Fatalf("read zip: %v", err)
+    }
+    manifest, err := archive.Open("manifest.csv")
+    if err != nil {
+        t.Fatalf("open manifest: %v", err)
+    }
+    rows, err := csv.NewReader(manifest).ReadAll()
+    if err != nil {
+        t.Fatalf("read manifest: %v", err)
+    }
+    if len(rows) != 3 || rows[1][0] != fmt.Sprint(own.ID) || rows[2][0] != fmt.Sprint(substituted.ID) {
+        t.Errorf("manifest rows = %v, want lessons %d and %d", rows, own.ID, substituted.ID)
+    }
+
+    var activities int64
+    database.DB.Model(&models.Activity{}).Where("action = ?", "export").Count(&activities)
+    if activities != 1 {
+        t.Errorf("export activities = %d, want 1 after a completed stream", activities)
+    }
+}
@@ -0,0 +1,466 @@
+package handlers
+
+import (
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+    "daily-lesson-api/utils"
+)
+
+var allowedEvidenceExtensions = map[string]bool{
+    ".jpg":  true,
+    ".jpeg": true,
+    ".png":  true,
+}
+
+// UploadEvidence menyimpan foto bukti mengajar lalu memverifikasi waktu dan lokasi dari EXIF.
+// File baru dihapus lagi jika penyimpanan gagal, dan file lama dihapus setelah berhasil.
+func UploadEvidence(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+
+    if err := database.DB.First(&lesson, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat mengubah data sendiri",
+        })
+    }
+
+    file, err := c.FormFile("file")
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "File bukti mengajar wajib diunggah (field: file)",
+        })
+    }
+
+    ext := strings.ToLower(filepath.Ext(file.Filename))
+    if !allowedEvidenceExtensions[ext] {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format file tidak didukung. Gunakan JPG atau PNG",
+        })
+    }
+
+    dir, err := utils.UploadDir("evidence")
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not prepare upload directory",
+        })
+    }
+
+    path := filepath.Join(dir, fmt.Sprintf("%d_%d%s", lesson.ID, time.Now().UnixNano(), ext))
+    if err := c.SaveFile(file, path); err != nil {
+        removeEvidenceFile(path)
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not save evidence file",
+        })
+    }
+
+    meta, err := utils.ExtractEvidenceMetadata(path)
+    if err != nil {
+        removeEvidenceFile(path)
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not read evidence file",
+        })
+    }
+
+    before := lesson.Snapshot()
+    lesson.BuktiMengajar = path
+    verifyEvidence(&lesson, meta)
+    hash := evidenceHash(path)
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := detectDuplicateEvidence(tx, &lesson, hash); err != nil {
+            return err
+        }
+        return tx.Save(&lesson).Error
+    })
+    if err != nil {
+        removeEvidenceFile(path)
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update lesson record",
+        })
+    }
+    removeEvidenceFile(before.BuktiMengajar)
+
+    recordLessonHistory(lesson, &before, "UPLOAD_EVIDENCE", evidenceHistoryDescription(lesson), userID)
+
+    activityDescription := fmt.Sprintf("Mengunggah bukti mengajar: %s - %s (%s)", lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru)
+    createActivity(userEmail, "upload", activityDescription)
+
+    return c.JSON(lesson)
+}
+
+// GetEvidence mengirimkan file bukti mengajar yang sudah diunggah
+func GetEvidence(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var lesson models.DailyLesson
+
+    if err := database.DB.First(&lesson, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat melihat data sendiri",
+        })
+    }
+
+    if !isUploadedEvidence(lesson.BuktiMengajar) {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Bukti mengajar belum diunggah",
+        })
+    }
+
+    return c.SendFile(lesson.BuktiMengajar)
+}
+
+// GetDuplicateEvidenceReport menampilkan lesson yang bukti fotonya sama/mirip dengan lesson lain,
+// dikelompokkan per guru
+func GetDuplicateEvidenceReport(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var lessons []models.DailyLesson
+
+    query := database.DB.Where("bukti_duplikat = ?", true)
+
+    if guru := c.Query("guru"); guru != "" {
+        query = query.Where("nama_guru LIKE ?", "%"+guru+"%")
+    }
+
+    if err := query.Order("nama_guru ASC, tanggal_mengajar ASC").Find(&lessons).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch duplicate evidence report",
+        })
+    }
+
+    // Ambil lesson asal agar supervisor bisa membandingkan kedua entri
+    var originalIDs []uint
+    for _, lesson := range lessons {
+        if lesson.BuktiDuplikatDariID != nil {
+            originalIDs = append(originalIDs, *lesson.BuktiDuplikatDariID)
+        }
+    }
+
+    originals := map[uint]models.DailyLesson{}
+    if len(originalIDs) > 0 {
+        var found []models.DailyLesson
+        database.DB.Unscoped().Where("id IN ?", originalIDs).Find(&found)
+        for _, lesson := range found {
+            originals[lesson.ID] = lesson
+        }
+    }
+
+    type duplicateEntry struct {
+        Lesson      models.DailyLesson  `json:"lesson"`
+        DuplicateOf *models.DailyLesson `json:"duplicate_of"`
+        Distance    int                 `json:"distance"`
+    }
+
+    type teacherDuplicates struct {
+        NamaGuru   string           `json:"nama_guru"`
+        Total      int              `json:"total"`
+        Duplicates []duplicateEntry `json:"duplicates"`
+    }
+
+    report := []teacherDuplicates{}
+    for _, lesson := range lessons {
+        entry := duplicateEntry{Lesson: lesson, Distance: lesson.BuktiJarakHash}
+        if lesson.BuktiDuplikatDariID != nil {
+            if original, ok := originals[*lesson.BuktiDuplikatDariID]; ok {
+                entry.DuplicateOf = &original
+            }
+        }
+
+        if len(report) == 0 || report[len(report)-1].NamaGuru != lesson.NamaGuru {
+            report = append(report, teacherDuplicates{NamaGuru: lesson.NamaGuru})
+        }
+        group := &report[len(report)-1]
+        group.Duplicates = append(group.Duplicates, entry)
+        group.Total++
+    }
+
+    createActivity(userEmail, "view_report", "Melihat laporan bukti mengajar duplikat")
+
+    return c.JSON(report)
+}
+
+// isUploadedEvidence memastikan BuktiMengajar menunjuk ke file di folder upload evidence,
+// karena field ini juga bisa diisi teks bebas lewat UpdateLesson
+func isUploadedEvidence(path string) bool {
+    if path == "" || !allowedEvidenceExtensions[strings.ToLower(filepath.Ext(path))] {
+        return false
+    }
+
+    dir, err := utils.UploadDir("evidence")
+    if err != nil {
+        return false
+    }
+
+    rel, err := filepath.Rel(dir, path)
+    return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// removeEvidenceFile menghapus file bukti yang sudah tidak dipakai. File tidak dihapus jika
+// masih dirujuk lesson lain (termasuk yang ada di trash), karena bukti_mengajar bisa diisi
+// path yang sama lewat UpdateLesson atau penggabungan duplikat.
+func removeEvidenceFile(path string) {
+    if !isUploadedEvidence(path) {
+        return
+    }
+
+    var count int64
+    if err := database.DB.Unscoped().Model(&models.DailyLesson{}).Where("bukti_mengajar = ?", path).Count(&count).Error; err != nil || count > 0 {
+        return
+    }
+    if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+        log.Printf("Failed to remove evidence %s: %v", path, err)
+    }
+}
+
+// verifyEvidence membandingkan metadata foto dengan jadwal mengajar dan geofence sekolah
+func verifyEvidence(lesson *models.DailyLesson, meta utils.EvidenceMetadata) {
+    lesson.BuktiDiambilPada = meta.TakenAt
+    lesson.BuktiLatitude = meta.Latitude
+    lesson.BuktiLongitude = meta.Longitude
+
+    var mismatches []string
+    var missing []string
+
+    if meta.TakenAt == nil {
+        missing = append(missing, "waktu pengambilan foto tidak tersedia")
+    } else if msg := checkEvidenceTime(*lesson, *meta.TakenAt); msg != "" {
+        mismatches = append(mismatches, msg)
+    }
+
+    if geofence, ok := utils.SchoolGeofence(); ok {
+        if meta.Latitude == nil || meta.Longitude == nil {
+            missing = append(missing, "lokasi GPS tidak tersedia")
+        } else if !geofence.Contains(*meta.Latitude, *meta.Longitude) {
+            distance := utils.DistanceMeters(geofence.Latitude, geofence.Longitude, *meta.Latitude, *meta.Longitude)
+            mismatches = append(mismatches, fmt.Sprintf("foto diambil %.0f m dari sekolah (batas %.0f m)", distance, geofence.RadiusMeters))
+        }
+    }
+
+    switch {
+    case len(mismatches) > 0:
+        lesson.VerifikasiBukti = models.VerifikasiTidakSesuai
+        lesson.CatatanVerifikasi = strings.Join(append(mismatches, missing...), "; ")
+    case len(missing) > 0:
+        lesson.VerifikasiBukti = models.VerifikasiTanpaMetadata
+        lesson.CatatanVerifikasi = strings.Join(missing, "; ")
+    default:
+        lesson.VerifikasiBukti = models.VerifikasiSesuai
+        lesson.CatatanVerifikasi = ""
+    }
+}
+
+// refreshEvidenceChecks menjalankan ulang verifikasi EXIF dan deteksi duplikat, dipakai
+// ketika BuktiMengajar berubah tanpa lewat UploadEvidence (misalnya saat revert).
+// tx harus transaksi yang sama dengan penyimpanan lesson.
+func refreshEvidenceChecks(tx *gorm.DB, lesson *models.DailyLesson) error {
+    if isUploadedEvidence(lesson.BuktiMengajar) {
+        if meta, err := utils.ExtractEvidenceMetadata(lesson.BuktiMengajar); err == nil {
+            verifyEvidence(lesson, meta)
+            return detectDuplicateEvidence(tx, lesson, evidenceHash(lesson.BuktiMengajar))
+        }
+    }
+
+    lesson.BuktiDiambilPada = nil
+    lesson.BuktiLatitude = nil
+    lesson.BuktiLongitude = nil
+    lesson.VerifikasiBukti = models.VerifikasiBelum
+    lesson.CatatanVerifikasi = ""
+    lesson.BuktiHash = ""
+    lesson.BuktiDuplikat = false
+    lesson.BuktiDuplikatDariID = nil
+    lesson.BuktiJarakHash = 0
+    if lesson.ID == 0 {
+        return nil
+    }
+    return clearDuplicateMarks(tx, lesson.ID)
+}
+
+// evidenceHash menghitung perceptual hash foto; string kosong jika foto tidak bisa dibaca
+func evidenceHash(path string) string {
+    hash, err := utils.ImageDifferenceHash(path)
+    if err != nil {
+        log.Printf("Failed to hash evidence %s: %v", path, err)
+        return ""
+    }
+    return hash
+}
+
+// evidenceMatch adalah lesson lain yang hash fotonya mirip
+type evidenceMatch struct {
+    ID       uint
+    Distance int
+    Flagged  bool
+}
+
+// similarEvidence mencari lesson dalam rentang tanggal yang hash fotonya mirip dengan hash,
+// diurutkan dari yang paling mirip. Rentang dibatasi agar tidak memindai seluruh tabel.
+func similarEvidence(tx *gorm.DB, date time.Time, hash string, exclude ...uint) ([]evidenceMatch, error) {
+    window := time.Duration(utils.DuplicateHashWindowDays()) * 24 * time.Hour
+    var others []models.DailyLesson
+    err := tx.Select("id", "bukti_hash", "bukti_duplikat").
+        Where("bukti_hash <> '' AND id NOT IN ?", exclude).
+        Where("date(tanggal_mengajar) BETWEEN ? AND ?",
+            date.Add(-window).Format("2006-01-02"), date.Add(window).Format("2006-01-02")).
+        Order("id ASC").Find(&others).Error
+    if err != nil {
+        return nil, err
+    }
+
+    threshold := utils.DuplicateHashThreshold()
+    matches := []evidenceMatch{}
+    for _, other := range others {
+        distance := utils.HashDistance(hash, other.BuktiHash)
+        if distance < 0 || distance > threshold {
+            continue
+        }
+        matches = append(matches, evidenceMatch{ID: other.ID, Distance: distance, Flagged: other.BuktiDuplikat})
+    }
+    sort.SliceStable(matches, func(i, j int) bool { return matches[i].Distance < matches[j].Distance })
+    return matches, nil
+}
+
+// detectDuplicateEvidence menandai lesson jika hash fotonya mirip dengan bukti lesson lain.
+// Lesson lain yang cocok dan belum bertanda ikut ditandai agar kedua lesson muncul di laporan
+// duplikat. Semua perubahan lewat tx agar ikut batal jika lesson gagal disimpan.
+func detectDuplicateEvidence(tx *gorm.DB, lesson *models.DailyLesson, hash string) error {
+    lesson.BuktiHash = hash
+    lesson.BuktiDuplikat = false
+    lesson.BuktiDuplikatDariID = nil
+    lesson.BuktiJarakHash = 0
+
+    // Tanda duplikat yang menunjuk ke foto lama lesson ini sudah tidak berlaku
+    if lesson.ID != 0 {
+        if err := clearDuplicateMarks(tx, lesson.ID); err != nil {
+            return err
+        }
+    }
+    if hash == "" {
+        return nil
+    }
+
+    matches, err := similarEvidence(tx, lesson.TanggalMengajar, hash, lesson.ID)
+    if err != nil {
+        return err
+    }
+    for _, match := range matches {
+        if match.Flagged || lesson.ID == 0 {
+            continue
+        }
+        if err := markDuplicateEvidence(tx, match.ID, &lesson.ID, match.Distance); err != nil {
+            return err
+        }
+    }
+
+    if len(matches) > 0 {
+        bestID := matches[0].ID
+        lesson.BuktiDuplikat = true
+        lesson.BuktiDuplikatDariID = &bestID
+        lesson.BuktiJarakHash = matches[0].Distance
+    }
+    return nil
+}
+
+// clearDuplicateMarks menghitung ulang tanda duplikat lesson lain yang ditandai karena foto
+// lessonID. Lesson yang masih mirip dengan foto lesson ketiga tetap ditandai dan dialihkan ke sana.
+func clearDuplicateMarks(tx *gorm.DB, lessonID uint) error {
+    var marked []models.DailyLesson
+    if err := tx.Unscoped().Select("id", "tanggal_mengajar", "bukti_hash").
+        Where("bukti_duplikat_dari_id = ?", lessonID).Find(&marked).Error; err != nil {
+        return err
+    }
+
+    for _, other := range marked {
+        var matches []evidenceMatch
+        if other.BuktiHash != "" {
+            var err error
+            if matches, err = similarEvidence(tx, other.TanggalMengajar, other.BuktiHash, other.ID, lessonID); err != nil {
+                return err
+            }
+        }
+
+        var err error
+        if len(matches) > 0 {
+            err = markDuplicateEvidence(tx, other.ID, &matches[0].ID, matches[0].Distance)
+        } else {
+            err = markDuplicateEvidence(tx, other.ID, nil, 0)
+        }
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// markDuplicateEvidence menyimpan tanda duplikat lesson; dariID nil berarti tanda dihapus
+func markDuplicateEvidence(tx *gorm.DB, lessonID uint, dariID *uint, distance int) error {
+    return tx.Unscoped().Model(&models.DailyLesson{}).Where("id = ?", lessonID).Updates(map[string]interface{}{
+        "bukti_duplikat":         dariID != nil,
+        "bukti_duplikat_dari_id": dariID,
+        "bukti_jarak_hash":       distance,
+    }).Error
+}
+
+// evidenceHistoryDescription merangkum hasil verifikasi untuk history lesson
+func evidenceHistoryDescription(lesson models.DailyLesson) string {
+    description := fmt.Sprintf("Bukti mengajar diunggah (%s)", lesson.VerifikasiBukti)
+    if lesson.BuktiDuplikat && lesson.BuktiDuplikatDariID != nil {
+        description += fmt.Sprintf(", mirip dengan bukti lesson ID %d", *lesson.BuktiDuplikatDariID)
+    }
+    return description
+}
+
+// checkEvidenceTime mengembalikan pesan jika waktu foto di luar tanggal/jam mengajar
+func checkEvidenceTime(lesson models.DailyLesson, takenAt time.Time) string {
+    date := lesson.TanggalMengajar
+    dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, takenAt.Location())
+
+    start := dayStart
+    end := dayStart.Add(24 * time.Hour)
+    if clock, ok := parseClock(lesson.JamMulai); ok {
+        start = dayStart.Add(clock)
+    }
+    if clock, ok := parseClock(lesson.JamSelesai); ok {
+        end = dayStart.Add(clock)
+    }
+
+    tolerance := utils.EvidenceTimeTolerance()
+    if takenAt.Before(start.Add(-tolerance)) || takenAt.After(end.Add(tolerance)) {
+        return fmt.Sprintf("foto diambil %s, di luar jadwal mengajar %s %s-%s",
+            takenAt.Format("2006-01-02 15:04"), date.Format("2006-01-02"), lesson.JamMulai, lesson.JamSelesai)
+    }
+    return ""
+}
+
+// parseClock mengubah jam format HH:MM menjadi durasi sejak tengah malam
+func parseClock(value string) (time.Duration, bool) {
+    t, err := time.Parse("15:04", strings.TrimSpace(value))
+    if err != nil {
+        return 0, false
+    }
+    return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "image"
+    "image/color"
+    "image/png"
+    "mime/multipart"
+    "net/http/httptest"
+    "os"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// testEvidencePNG membuat foto uji; pola berbeda menghasilkan hash yang jauh berbeda
+func testEvidencePNG(t *testing.T, pattern int) []byte {
+    t.Helper()
+
+    img := image.NewGray(image.Rect(0, 0, 64, 64))
+    for y := 0; y < 64; y++ {
+        for x := 0; x < 64; x++ {
+            value := uint8(x * 4)
+            switch pattern {
+            case 1:
+                value = uint8(255 - x*4)
+            case 2:
+                value = uint8(((x / 8) + (y / 8)) % 2 * 255)
+            }
+            img.SetGray(x, y, color.Gray{Y: value})
+        }
+    }
+
+    var buf bytes.Buffer
+    if err := png.Encode(&buf, img); err != nil {
+        t.Fatalf("encode png: %v", err)
+    }
+    return buf.Bytes()
+}
+
+func evidenceApp(userID uint) *fiber.App {
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleAdmin))
+        c.Locals("email", "admin@sekolah.test")
+        return c.Next()
+    })
+    app.Post("/lessons/:id/evidence", UploadEvidence)
+    return app
+}
+
+func uploadTestEvidence(t *testing.T, app *fiber.App, lessonID uint, photo []byte) models.DailyLesson {
+    t.Helper()
+
+    var body bytes.Buffer
+    form := multipart.NewWriter(&body)
+    part, _ := form.CreateFormFile("file", "bukti.png")
+    part.Write(photo)
+    form.Close()
+
+    req := httptest.NewRequest("POST", fmt.Sprintf("/lessons/%d/evidence", lessonID), &body)
+    req.Header.Set("Content-Type", form.FormDataContentType())
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("upload: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("upload status = %d, want 200", resp.StatusCode)
+    }
+
+    var lesson models.DailyLesson
+    database.DB.First(&lesson, lessonID)
+    return lesson
+}
+
+func setupEvidenceDir(t *testing.T) {
+    t.Helper()
+    t.Setenv("UPLOAD_DIR", t.TempDir())
+}
+
+func TestUploadEvidenceRemovesReplacedFile(t *testing.T) {
+    setupTestDB(t)
+    setupEvidenceDir(t)
+    admin := createTestUser(t, "Admin", models.RoleAdmin)
+    lesson := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: admin.ID})
+    app := evidenceApp(admin.ID)
+
+    first := uploadTestEvidence(t, app, lesson.ID, testEvidencePNG(t, 0))
+    second := uploadTestEvidence(t, app, lesson.ID, testEvidencePNG(t, 1))
+
+    if _, err := os.Stat(first.BuktiMengajar); !os.IsNotExist(err) {
+        t.Errorf("replaced evidence %s still on disk", first.BuktiMengajar)
+    }
+    if _, err := os.Stat(second.BuktiMengajar); err != nil {
+        t.Errorf("current evidence missing: %v", err)
+    }
+
+    // File yang masih dirujuk lesson lain tidak ikut dihapus
+    shared := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: admin.ID, BuktiMengajar: second.BuktiMengajar})
+    uploadTestEvidence(t, app, lesson.ID, testEvidencePNG(t, 2))
+    if _, err := os.Stat(shared.BuktiMengajar); err != nil {
+        t.Errorf("evidence shared with lesson %d was removed: %v", shared.ID, err)
+    }
+}
+
+func TestDuplicateEvidenceMarksAreRecomputed(t *testing.T) {
+    setupTestDB(t)
+    setupEvidenceDir(t)
+    admin := createTestUser(t, "Admin", models.RoleAdmin)
+    app := evidenceApp(admin.ID)
+
+    var lessons []models.DailyLesson
+    for i, kelas := range []string{"7A", "7B", "7C"} {
+        lesson := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: kelas, TanggalMengajar: testDate("2026-10-05").AddDate(0, 0, i), CreatedByID: admin.ID})
+        lessons = append(lessons, lesson)
+    }
+    photo := testEvidencePNG(t, 0)
+    for _, lesson := range lessons {
+        uploadTestEvidence(t, app, lesson.ID, photo)
+    }
+
+    // Lesson pertama ganti foto; dua lesson lain masih memakai foto yang sama satu sama lain
+    first := uploadTestEvidence(t, app, lessons[0].ID, testEvidencePNG(t, 1))
+    if first.BuktiDuplikat {
+        t.Errorf("lesson %d still flagged after uploading a different photo", first.ID)
+    }
+    for _, lesson := range lessons[1:] {
+        var current models.DailyLesson
+        database.DB.First(&current, lesson.ID)
+        if !current.BuktiDuplikat || current.BuktiDuplikatDariID == nil || *current.BuktiDuplikatDariID == first.ID {
+            t.Errorf("lesson %d mark = %v dari %v, want flagged against the remaining lesson", current.ID, current.BuktiDuplikat, current.BuktiDuplikatDariID)
+        }
+    }
+}
+
+func TestDuplicateEvidenceMarksRollBackWithLesson(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "Admin", models.RoleAdmin)
+    other := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: admin.ID, BuktiHash: "ffffffffffffffff"})
+    lesson := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: admin.ID})
+
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := detectDuplicateEvidence(tx, &lesson, "ffffffffffffffff"); err != nil {
+            return err
+        }
+        return errors.New("save failed")
+    })
+    if err == nil {
+        t.Fatal("transaction should fail")
+    }
+
+    var current models.DailyLesson
+    database.DB.First(&current, other.ID)
+    if current.BuktiDuplikat {
+        t.Errorf("lesson %d flagged although the uploading lesson was never saved", other.ID)
+    }
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+    "bufio"
+    "encoding/csv"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "github.com/xuri/excelize/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// exportHeaders adalah judul kolom ekspor. Judul ini juga dikenali oleh ImportLessons
+// sehingga file hasil ekspor bisa diimpor kembali.
+var exportHeaders = []string{
+    "No", "Tanggal", "Jam Mulai", "Jam Selesai", "Nama Guru", "Mata Pelajaran",
+    "Kelas", "Pokok Materi", "Status", "Catatan", "Verifikasi Bukti", "Guru Pengganti",
+}
+
+// exportSummaryRow adalah hasil GROUP BY untuk sheet ringkasan
+type exportSummaryRow struct {
+    Label string
+    Total int64
+}
+
+// ExportLessons mengekspor daftar lesson dengan filter yang sama seperti GetLessons
+func ExportLessons(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    buildQuery := func() *gorm.DB {
+        return filterLessons(c, database.DB.Model(&models.DailyLesson{}))
+    }
+
+    return exportLessonData(c, buildQuery, "catatan-mengajar", "Daftar Catatan Mengajar", func() {
+        createActivity(userEmail, "export", "Mengekspor daftar lessons")
+    })
+}
+
+// ExportTeacherReport mengekspor laporan guru dengan filter yang sama seperti GetTeacherReport
+func ExportTeacherReport(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    title := "Laporan Mengajar Guru"
+    if guru := c.Query("guru"); guru != "" {
+        title = fmt.Sprintf("Laporan Mengajar %s", guru)
+    }
+
+    activityDescription := teacherReportDescription(c, "Mengekspor laporan guru")
+    return exportLessonData(c, func() *gorm.DB { return teacherReportQuery(c) }, "laporan-guru", title, func() {
+        createActivity(userEmail, "export", activityDescription)
+    })
+}
+
+// exportLessonData memilih format ekspor berdasarkan parameter format (csv atau xlsx).
+// exported dipanggil setelah seluruh file berhasil dikirim, untuk mencatat aktivitas ekspor.
+func exportLessonData(c *fiber.Ctx, buildQuery func() *gorm.DB, filename, title string, exported func()) error {
+    filename = fmt.Sprintf("%s_%s", filename, time.Now().Format("20060102"))
+
+    switch c.Query("format", "csv") {
+    case "csv":
+        return streamLessonsCSV(c, buildQuery, filename+".csv", exported)
+    case "xlsx":
+        return writeLessonsXLSX(c, buildQuery, filename+".xlsx", title, exported)
+    default:
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format ekspor harus csv atau xlsx",
+        })
+    }
+}
+
+// streamLessonsCSV menulis CSV baris demi baris langsung ke response. Query dijalankan
+// sebelum streaming dimulai karena Ctx (termasuk nilai query string) tidak boleh dipakai
+// lagi setelah handler selesai.
+func streamLessonsCSV(c *fiber.Ctx, buildQuery func() *gorm.DB, filename string, exported func()) error {
+    rows, err := buildQuery().Order("tanggal_mengajar ASC, jam_mulai ASC, id ASC").Rows()
+    if err != nil {
+        return exportFailed(c, err)
+    }
+
+    c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+    c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+        defer rows.Close()
+
+        // BOM agar Excel membaca file sebagai UTF-8
+        w.WriteString("\xef\xbb\xbf")
+
+        writer := csv.NewWriter(w)
+        writer.Write(exportHeaders)
+
+        number := 0
+        for rows.Next() {
+            var lesson models.DailyLesson
+            if err := database.DB.ScanRows(rows, &lesson); err != nil {
+                log.Printf("Failed to export lessons: %v", err)
+                return
+            }
+
+            number++
+            writer.Write([]string{
+                fmt.Sprint(number),
+                lesson.TanggalMengajar.Format("02/01/2006"),
+                lesson.JamMulai,
+                lesson.JamSelesai,
+                lesson.NamaGuru,
+                lesson.MataPelajaran,
+                lesson.Kelas,
+                lesson.PokokMateri,
+                lesson.Status,
+                lesson.Catatan,
+                lesson.VerifikasiBukti,
+                lesson.GuruPengganti,
+            })
+
+            if number%500 == 0 {
+                writer.Flush()
+                if err := writer.Error(); err != nil {
+                    log.Printf("Failed to export lessons: %v", err)
+                    return
+                }
+                if err := w.Flush(); err != nil {
+                    log.Printf("Failed to send lesson export: %v", err)
+                    return
+                }
+            }
+        }
+        if err := rows.Err(); err != nil {
+            log.Printf("Failed to export lessons: %v", err)
+            return
+        }
+
+        writer.Flush()
+        if err := writer.Error(); err != nil {
+            log.Printf("Failed to export lessons: %v", err)
+            return
+        }
+        if err := w.Flush(); err != nil {
+            log.Printf("Failed to send lesson export: %v", err)
+            return
+        }
+        exported()
+    })
+
+    return nil
+}
+
+// writeLessonsXLSX membuat workbook dengan sheet data (StreamWriter, tanggal sebagai sel tanggal)
+// dan sheet ringkasan per status, mata pelajaran dan kelas
+func writeLessonsXLSX(c *fiber.Ctx, buildQuery func() *gorm.DB, filename, title string, exported func()) error {
+    f := excelize.NewFile()
+    defer f.Close()
+
+    const dataSheet = "Catatan Mengajar"
+    const summarySheet = "Ringkasan"
+
+    f.SetSheetName("Sheet1", dataSheet)
+    if _, err := f.NewSheet(summarySheet); err != nil {
+        return exportFailed(c, err)
+    }
+
+    headerStyle, _ := f.NewStyle(&excelize.Style{
+        Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+        Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"1F4E78"}},
+        Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+        Border:    exportBorders(),
+    })
+    titleStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+    dateFormat := "dd/mm/yyyy"
+    dateStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat, Border: exportBorders()})
+    cellStyle, _ := f.NewStyle(&excelize.Style{
+        Alignment: &excelize.Alignment{Vertical: "top", WrapText: true},
+        Border:    exportBorders(),
+    })
+
+    sw, err := f.NewStreamWriter(dataSheet)
+    if err != nil {
+        return exportFailed(c, err)
+    }
+
+    widths := []float64{5, 12, 10, 10, 24, 22, 12, 40, 14, 30, 18, 24}
+    for i, width := range widths {
+        sw.SetColWidth(i+1, i+1, width)
+    }
+
+    sw.SetRow("A1", []interface{}{excelize.Cell{Value: title, StyleID: titleStyle}})
+    sw.SetRow("A2", []interface{}{fmt.Sprintf("Dicetak: %s", time.Now().Format("02/01/2006 15:04"))})
+
+    header := make([]interface{}, len(exportHeaders))
+    for i, value := range exportHeaders {
+        header[i] = excelize.Cell{Value: value, StyleID: headerStyle}
+    }
+    sw.SetRow("A4", header)
+
+    rows, err := buildQuery().Order("tanggal_mengajar ASC, jam_mulai ASC, id ASC").Rows()
+    if err != nil {
+        return exportFailed(c, err)
+    }
+    defer rows.Close()
+
+    number := 0
+    for rows.Next() {
+        var lesson models.DailyLesson
+        if err := database.DB.ScanRows(rows, &lesson); err != nil {
+            return exportFailed(c, err)
+        }
+
+        number++
+        cell, _ := excelize.CoordinatesToCellName(1, number+4)
+        sw.SetRow(cell, []interface{}{
+            excelize.Cell{Value: number, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.TanggalMengajar, StyleID: dateStyle},
+            excelize.Cell{Value: lesson.JamMulai, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.JamSelesai, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.NamaGuru, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.MataPelajaran, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.Kelas, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.PokokMateri, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.Status, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.Catatan, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.VerifikasiBukti, StyleID: cellStyle},
+            excelize.Cell{Value: lesson.GuruPengganti, StyleID: cellStyle},
+        })
+    }
+
+    if err := rows.Err(); err != nil {
+        return exportFailed(c, err)
+    }
+    if err := sw.Flush(); err != nil {
+        return exportFailed(c, err)
+    }
+
+    // Sheet ringkasan dihitung dengan GROUP BY sehingga tidak perlu menyimpan data di memori
+    f.SetColWidth(summarySheet, "A", "A", 30)
+    f.SetColWidth(summarySheet, "B", "B", 12)
+    f.SetCellValue(summarySheet, "A1", title)
+    f.SetCellStyle(summarySheet, "A1", "A1", titleStyle)
+    f.SetCellValue(summarySheet, "A3", "Total Catatan Mengajar")
+    f.SetCellValue(summarySheet, "B3", number)
+
+    row := 5
+    for _, group := range []struct {
+        title  string
+        column string
+    }{
+        {"Status", "status"},
+        {"Mata Pelajaran", "mata_pelajaran"},
+        {"Kelas", "kelas"},
+        {"Nama Guru", "nama_guru"},
+    } {
+        var summary []exportSummaryRow
+        if err := buildQuery().Select(group.column + " AS label, count(*) AS total").
+            Group(group.column).Order("total DESC").Scan(&summary).Error; err != nil {
+            return exportFailed(c, err)
+        }
+
+        start, _ := excelize.CoordinatesToCellName(1, row)
+        end, _ := excelize.CoordinatesToCellName(2, row)
+        f.SetSheetRow(summarySheet, start, &[]interface{}{group.title, "Jumlah"})
+        f.SetCellStyle(summarySheet, start, end, headerStyle)
+        row++
+
+        for _, item := range summary {
+            cell, _ := excelize.CoordinatesToCellName(1, row)
+            f.SetSheetRow(summarySheet, cell, &[]interface{}{item.Label, item.Total})
+            row++
+        }
+        row++
+    }
+
+    c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+    if err := f.Write(c.Response().BodyWriter()); err != nil {
+        return exportFailed(c, err)
+    }
+    exported()
+    return nil
+}
+
+func exportBorders() []excelize.Border {
+    return []excelize.Border{
+        {Type: "left", Color: "BFBFBF", Style: 1},
+        {Type: "right", Color: "BFBFBF", Style: 1},
+        {Type: "top", Color: "BFBFBF", Style: 1},
+        {Type: "bottom", Color: "BFBFBF", Style: 1},
+    }
+}
+
+func exportFailed(c *fiber.Ctx, err error) error {
+    log.Printf("Failed to export lessons: %v", err)
+    return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+        "error": "Could not export lessons",
+    })
+}
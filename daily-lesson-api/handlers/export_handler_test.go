@@ -0,0 +1,140 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/csv"
+    "io"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "github.com/xuri/excelize/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func exportTestLessons(t *testing.T, userID uint, role models.UserRole, query string) []byte {
+    t.Helper()
+
+    app := fiber.New()
+    app.Get("/lessons/export", func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(role))
+        c.Locals("email", "guru@sekolah.test")
+        return ExportLessons(c)
+    })
+
+    resp, err := app.Test(httptest.NewRequest("GET", "/lessons/export"+query, nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+    body, _ := io.ReadAll(resp.Body)
+    return body
+}
+
+func exportActivityCount() int64 {
+    var count int64
+    database.DB.Model(&models.Activity{}).Where("action = ?", "export").Count(&count)
+    return count
+}
+
+func TestExportLessonsCSV(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    other := createTestUser(t, "lain", models.RoleTeacher)
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID,
+        PokokMateri: "Desimal, persen",
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        PokokMateri: "Pecahan",
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "lain", Kelas: "7C", TanggalMengajar: testDate("2026-10-05"), CreatedByID: other.ID,
+    })
+
+    body := exportTestLessons(t, guru.ID, models.RoleTeacher, "")
+    if !bytes.HasPrefix(body, []byte("\xef\xbb\xbf")) {
+        t.Fatalf("export does not start with a UTF-8 BOM")
+    }
+
+    rows, err := csv.NewReader(bytes.NewReader(body[3:])).ReadAll()
+    if err != nil {
+        t.Fatalf("read csv: %v", err)
+    }
+    if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(exportHeaders, ",") {
+        t.Fatalf("rows = %v, want header and the teacher's two lessons", rows)
+    }
+    if rows[1][1] != "05/10/2026" || rows[1][7] != "Pecahan" || rows[2][7] != "Desimal, persen" {
+        t.Errorf("rows = %v, want lessons ordered by date", rows[1:])
+    }
+
+    if count := exportActivityCount(); count != 1 {
+        t.Errorf("export activities = %d, want 1 after a completed stream", count)
+    }
+}
+
+func TestExportLessonsXLSX(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "admin", models.RoleAdmin)
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: admin.ID,
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: admin.ID,
+        Status: models.StatusDibatalkan,
+    })
+
+    body := exportTestLessons(t, admin.ID, models.RoleAdmin, "?format=xlsx")
+    f, err := excelize.OpenReader(bytes.NewReader(body))
+    if err != nil {
+        t.Fatalf("open xlsx: %v", err)
+    }
+    defer f.Close()
+
+    rows, err := f.GetRows("Catatan Mengajar")
+    if err != nil {
+        t.Fatalf("read data sheet: %v", err)
+    }
+    if len(rows) != 6 || rows[3][0] != "No" || rows[4][6] != "7A" || rows[5][8] != models.StatusDibatalkan {
+        t.Fatalf("data rows = %v, want header at row 4 followed by both lessons", rows)
+    }
+
+    total, _ := f.GetCellValue("Ringkasan", "B3")
+    if total != "2" {
+        t.Errorf("summary total = %q, want 2", total)
+    }
+
+    if count := exportActivityCount(); count != 1 {
+        t.Errorf("export activities = %d, want 1", count)
+    }
+}
+
+func TestExportLessonsRejectsUnknownFormat(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "admin", models.RoleAdmin)
+
+    app := fiber.New()
+    app.Get("/lessons/export", func(c *fiber.Ctx) error {
+        c.Locals("userID", admin.ID)
+        c.Locals("role", string(models.RoleAdmin))
+        c.Locals("email", "admin@sekolah.test")
+        return ExportLessons(c)
+    })
+
+    resp, err := app.Test(httptest.NewRequest("GET", "/lessons/export?format=pdf", nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusBadRequest {
+        t.Fatalf("status = %d, want 400", resp.StatusCode)
+    }
+    if count := exportActivityCount(); count != 0 {
+        t.Errorf("export activities = %d, want none for a rejected export", count)
+    }
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+    "encoding/csv"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "github.com/xuri/excelize/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// GradebookRow adalah nilai satu siswa pada semua penilaian dalam buku nilai. Nilai berurutan
+// sesuai daftar penilaian; nil berarti belum dinilai. RataRata dihitung pada skala 0-100.
+type GradebookRow struct {
+    StudentID  uint       `json:"student_id"`
+    NIS        string     `json:"nis"`
+    Nama       string     `json:"nama"`
+    Nilai      []*float64 `json:"nilai"`
+    RataRata   *float64   `json:"rata_rata"`
+    DiBawahKKM int        `json:"di_bawah_kkm"`
+}
+
+// Gradebook adalah buku nilai harian satu kelas dan mata pelajaran dalam satu tahun ajaran
+type Gradebook struct {
+    Kelas         string              `json:"kelas"`
+    MataPelajaran string              `json:"mata_pelajaran"`
+    TahunAjaran   string              `json:"tahun_ajaran"`
+    Assessments   []models.Assessment `json:"assessments"`
+    Data          []GradebookRow      `json:"data"`
+}
+
+// buildGradebook menyusun buku nilai. Guru hanya melihat penilaian pada lesson miliknya;
+// penilaian pada lesson yang sudah dihapus tidak dihitung.
+func buildGradebook(c *fiber.Ctx, kelas, mataPelajaran, tahunAjaran string) (Gradebook, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    gradebook := Gradebook{Kelas: kelas, MataPelajaran: mataPelajaran, TahunAjaran: tahunAjaran}
+    startDate, endDate := tahunAjaranRange(tahunAjaran)
+
+    query := database.DB.Model(&models.Assessment{}).
+        Joins("JOIN daily_lessons ON daily_lessons.id = assessments.lesson_id AND daily_lessons.deleted_at IS NULL").
+        Where("lower(assessments.kelas) = lower(?) AND lower(assessments.mata_pelajaran) = lower(?)", kelas, mataPelajaran).
+        Where("date(assessments.tanggal) BETWEEN ? AND ?", startDate, endDate)
+    if userRole == "teacher" {
+        query = query.Where("daily_lessons.created_by_id = ? OR daily_lessons.guru_pengganti_id = ?", userID, userID)
+    }
+    if err := query.Order("assessments.tanggal ASC, assessments.id ASC").Find(&gradebook.Assessments).Error; err != nil {
+        return gradebook, err
+    }
+
+    column := map[uint]int{}
+    assessmentIDs := []uint{}
+    for i, assessment := range gradebook.Assessments {
+        column[assessment.ID] = i
+        assessmentIDs = append(assessmentIDs, assessment.ID)
+    }
+
+    var scores []models.AssessmentScore
+    if err := database.DB.Where("assessment_id IN ?", assessmentIDs).Find(&scores).Error; err != nil {
+        return gradebook, err
+    }
+    scoresByStudent := map[uint][]models.AssessmentScore{}
+    studentIDs := []uint{}
+    for _, score := range scores {
+        if _, ok := scoresByStudent[score.StudentID]; !ok {
+            studentIDs = append(studentIDs, score.StudentID)
+        }
+        scoresByStudent[score.StudentID] = append(scoresByStudent[score.StudentID], score)
+    }
+
+    var students []models.Student
+    if err := database.DB.Unscoped().Where("id IN ?", studentIDs).
+        Or("lower(kelas) = lower(?) AND aktif = ? AND deleted_at IS NULL", kelas, true).
+        Order("nama ASC").Find(&students).Error; err != nil {
+        return gradebook, err
+    }
+
+    gradebook.Data = make([]GradebookRow, 0, len(students))
+    for _, student := range students {
+        row := GradebookRow{
+            StudentID: student.ID,
+            NIS:       student.NIS,
+            Nama:      student.Nama,
+            Nilai:     make([]*float64, len(gradebook.Assessments)),
+        }
+
+        total := 0.0
+        for _, score := range scoresByStudent[student.ID] {
+            nilai := score.Nilai
+            assessment := gradebook.Assessments[column[score.AssessmentID]]
+            row.Nilai[column[score.AssessmentID]] = &nilai
+            total += nilai / assessment.NilaiMaksimal * 100
+            if nilai < assessment.KKM {
+                row.DiBawahKKM++
+            }
+        }
+        if count := len(scoresByStudent[student.ID]); count > 0 {
+            rataRata := roundScore(total / float64(count))
+            row.RataRata = &rataRata
+        }
+        gradebook.Data = append(gradebook.Data, row)
+    }
+
+    return gradebook, nil
+}
+
+// gradebookHeaders adalah judul kolom ekspor buku nilai
+func gradebookHeaders(gradebook Gradebook) []string {
+    headers := []string{"No", "NIS", "Nama"}
+    for _, assessment := range gradebook.Assessments {
+        headers = append(headers, fmt.Sprintf("%s %s (%g)", assessment.Tanggal.Format("02/01"), assessment.Judul, assessment.NilaiMaksimal))
+    }
+    return append(headers, "Rata-rata (0-100)", "Di Bawah KKM")
+}
+
+// GetGradebook menampilkan buku nilai harian per kelas dan mata pelajaran. Dengan format=csv
+// atau xlsx, buku nilai diunduh sebagai file.
+func GetGradebook(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    kelas := strings.TrimSpace(c.Query("kelas"))
+    mataPelajaran := strings.TrimSpace(c.Query("mata_pelajaran"))
+
+    if kelas == "" || mataPelajaran == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "kelas dan mata_pelajaran wajib diisi",
+        })
+    }
+
+    tahunAjaran := c.Query("tahun_ajaran", models.TahunAjaranOf(time.Now()))
+    if !validTahunAjaran(tahunAjaran) {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "tahun_ajaran harus berformat 2025/2026",
+        })
+    }
+
+    format := c.Query("format", "json")
+    if format != "json" && format != "csv" && format != "xlsx" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format ekspor harus csv atau xlsx",
+        })
+    }
+
+    gradebook, err := buildGradebook(c, kelas, mataPelajaran, tahunAjaran)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch gradebook",
+        })
+    }
+
+    description := fmt.Sprintf("buku nilai %s - %s tahun ajaran %s", mataPelajaran, kelas, tahunAjaran)
+    filename := fmt.Sprintf("buku-nilai_%s_%s_%s", strings.ReplaceAll(kelas, " ", "-"),
+        strings.ReplaceAll(mataPelajaran, " ", "-"), time.Now().Format("20060102"))
+
+    switch format {
+    case "csv":
+        createActivity(userEmail, "export", "Mengekspor "+description)
+        return writeGradebookCSV(c, gradebook, filename+".csv")
+    case "xlsx":
+        createActivity(userEmail, "export", "Mengekspor "+description)
+        return writeGradebookXLSX(c, gradebook, filename+".xlsx")
+    }
+
+    createActivity(userEmail, "view_report", "Melihat "+description)
+    return c.JSON(gradebook)
+}
+
+// formatScore menulis nilai tanpa nol berlebih; kosong jika belum dinilai
+func formatScore(value *float64) string {
+    if value == nil {
+        return ""
+    }
+    return fmt.Sprintf("%g", *value)
+}
+
+func writeGradebookCSV(c *fiber.Ctx, gradebook Gradebook, filename string) error {
+    c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+    w := c.Response().BodyWriter()
+    // BOM agar Excel membaca file sebagai UTF-8
+    w.Write([]byte("\xef\xbb\xbf"))
+
+    writer := csv.NewWriter(w)
+    writer.Write(gradebookHeaders(gradebook))
+    for i, row := range gradebook.Data {
+        record := []string{fmt.Sprint(i + 1), row.NIS, row.Nama}
+        for _, nilai := range row.Nilai {
+            record = append(record, formatScore(nilai))
+        }
+        record = append(record, formatScore(row.RataRata), fmt.Sprint(row.DiBawahKKM))
+        writer.Write(record)
+    }
+    writer.Flush()
+    return writer.Error()
+}
+
+// writeGradebookXLSX membuat workbook buku nilai; nilai di bawah KKM diberi warna merah
+func writeGradebookXLSX(c *fiber.Ctx, gradebook Gradebook, filename string) error {
+    f := excelize.NewFile()
+    defer f.Close()
+
+    sheet := "Buku Nilai"
+    f.SetSheetName("Sheet1", sheet)
+
+    headerStyle, _ := f.NewStyle(&excelize.Style{
+        Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+        Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"1F4E78"}},
+        Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+        Border:    exportBorders(),
+    })
+    titleStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+    cellStyle, _ := f.NewStyle(&excelize.Style{Border: exportBorders()})
+    lowStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "C00000"}, Border: exportBorders()})
+
+    f.SetCellValue(sheet, "A1", fmt.Sprintf("Buku Nilai Harian %s - %s", gradebook.MataPelajaran, gradebook.Kelas))
+    f.SetCellStyle(sheet, "A1", "A1", titleStyle)
+    f.SetCellValue(sheet, "A2", "Tahun Ajaran "+gradebook.TahunAjaran)
+
+    headers := gradebookHeaders(gradebook)
+    f.SetSheetRow(sheet, "A4", &headers)
+    lastColumn, _ := excelize.ColumnNumberToName(len(headers))
+    f.SetCellStyle(sheet, "A4", lastColumn+"4", headerStyle)
+    f.SetRowHeight(sheet, 4, 45)
+    f.SetColWidth(sheet, "A", "A", 5)
+    f.SetColWidth(sheet, "B", "B", 14)
+    f.SetColWidth(sheet, "C", "C", 30)
+    if len(headers) > 3 {
+        first, _ := excelize.ColumnNumberToName(4)
+        f.SetColWidth(sheet, first, lastColumn, 14)
+    }
+
+    for i, row := range gradebook.Data {
+        number := i + 5
+        values := []interface{}{i + 1, row.NIS, row.Nama}
+        for _, nilai := range row.Nilai {
+            if nilai == nil {
+                values = append(values, nil)
+                continue
+            }
+            values = append(values, *nilai)
+        }
+        if row.RataRata != nil {
+            values = append(values, *row.RataRata)
+        } else {
+            values = append(values, nil)
+        }
+        values = append(values, row.DiBawahKKM)
+
+        start, _ := excelize.CoordinatesToCellName(1, number)
+        end, _ := excelize.CoordinatesToCellName(len(headers), number)
+        f.SetSheetRow(sheet, start, &values)
+        f.SetCellStyle(sheet, start, end, cellStyle)
+
+        for j, nilai := range row.Nilai {
+            if nilai != nil && *nilai < gradebook.Assessments[j].KKM {
+                cell, _ := excelize.CoordinatesToCellName(j+4, number)
+                f.SetCellStyle(sheet, cell, cell, lowStyle)
+            }
+        }
+    }
+
+    c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+    if err := f.Write(c.Response().BodyWriter()); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not export gradebook",
+        })
+    }
+    return nil
+}
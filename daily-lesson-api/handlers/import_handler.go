@@ -0,0 +1,381 @@
+package handlers
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+    "daily-lesson-api/utils"
+)
+
+const maxImportRows = 2000
+
+// lessonStatuses adalah status lesson yang diterima saat import
+var lessonStatuses = map[string]bool{
+    models.StatusTerlaksana:      true,
+    models.StatusDibatalkan:      true,
+    models.StatusDitunda:         true,
+    models.StatusDraft:           true,
+    models.StatusTidakTerlaksana: true,
+}
+
+// importColumnAliases memetakan judul kolom (setelah NormalizeHeader) ke field CreateLessonRequest
+var importColumnAliases = map[string]string{
+    "nama_guru":        "nama_guru",
+    "guru":             "nama_guru",
+    "mata_pelajaran":   "mata_pelajaran",
+    "mapel":            "mata_pelajaran",
+    "kelas":            "kelas",
+    "pokok_materi":     "pokok_materi",
+    "materi":           "pokok_materi",
+    "bukti_mengajar":   "bukti_mengajar",
+    "bukti":            "bukti_mengajar",
+    "tanggal_mengajar": "tanggal_mengajar",
+    "tanggal":          "tanggal_mengajar",
+    "jam_mulai":        "jam_mulai",
+    "mulai":            "jam_mulai",
+    "jam_selesai":      "jam_selesai",
+    "selesai":          "jam_selesai",
+    "status":           "status",
+    "catatan":          "catatan",
+    "keterangan":       "catatan",
+}
+
+// ImportRowError berisi kesalahan validasi untuk satu baris file (nomor baris sesuai spreadsheet)
+type ImportRowError struct {
+    Row    int      `json:"row"`
+    Errors []string `json:"errors"`
+}
+
+// ImportLessons mengimpor banyak catatan mengajar sekaligus dari file CSV/XLSX.
+// Baris yang duplikat atau bentrok jadwal (tanpa override_conflict=true) dilaporkan sebagai error.
+// Dengan dry_run=true hanya validasi yang dijalankan tanpa menulis ke database; tanpa dry_run
+// seluruh baris valid disimpan dalam satu transaksi beserta history-nya.
+func ImportLessons(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    dryRun := c.QueryBool("dry_run", false)
+
+    file, err := c.FormFile("file")
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "File CSV/XLSX wajib diunggah (field: file)",
+        })
+    }
+
+    src, err := file.Open()
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Could not read uploaded file",
+        })
+    }
+    defer src.Close()
+
+    rows, err := utils.ReadSpreadsheet(file.Filename, src)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    // Baris judul tidak harus di baris pertama (file ekspor XLSX diawali judul laporan)
+    headerIndex := findHeaderRow(rows, importColumnAliases)
+    if len(rows) < headerIndex+2 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "File harus berisi baris judul kolom dan minimal satu baris data",
+        })
+    }
+
+    if len(rows)-headerIndex-1 > maxImportRows {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": fmt.Sprintf("Maksimal %d baris per import", maxImportRows),
+        })
+    }
+
+    columns := map[int]string{}
+    found := map[string]bool{}
+    for index, header := range rows[headerIndex] {
+        if field, ok := importColumnAliases[utils.NormalizeHeader(header)]; ok {
+            columns[index] = field
+            found[field] = true
+        }
+    }
+
+    var missing []string
+    for _, field := range []string{"nama_guru", "mata_pelajaran", "kelas", "pokok_materi", "tanggal_mengajar"} {
+        if !found[field] {
+            missing = append(missing, field)
+        }
+    }
+    if len(missing) > 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": fmt.Sprintf("Kolom wajib tidak ditemukan: %s", strings.Join(missing, ", ")),
+        })
+    }
+
+    rowErrors := []ImportRowError{}
+    var lessons []models.DailyLesson
+    var rowNumbers []int
+
+    for i, row := range rows[headerIndex+1:] {
+        rowNumber := headerIndex + i + 2
+        if isBlankRow(row) {
+            continue
+        }
+
+        values := map[string]string{}
+        for index, field := range columns {
+            if index < len(row) {
+                values[field] = strings.TrimSpace(row[index])
+            }
+        }
+
+        req := CreateLessonRequest{
+            NamaGuru:        values["nama_guru"],
+            MataPelajaran:   values["mata_pelajaran"],
+            Kelas:           values["kelas"],
+            PokokMateri:     values["pokok_materi"],
+            BuktiMengajar:   values["bukti_mengajar"],
+            TanggalMengajar: values["tanggal_mengajar"],
+            JamMulai:        values["jam_mulai"],
+            JamSelesai:      values["jam_selesai"],
+            Status:          values["status"],
+            Catatan:         values["catatan"],
+        }
+
+        lesson, errs := validateImportedLesson(req)
+        if len(errs) > 0 {
+            rowErrors = append(rowErrors, ImportRowError{Row: rowNumber, Errors: errs})
+            continue
+        }
+
+        lesson.CreatedByID = userID
+        lessons = append(lessons, lesson)
+        rowNumbers = append(rowNumbers, rowNumber)
+    }
+
+    action := "IMPORT"
+    if userRole == "admin" {
+        action = "IMPORT_ADMIN"
+    }
+    overrideConflict := c.QueryBool("override_conflict", false)
+
+    // Semua lesson import dibuat oleh user yang sama, jadi role cukup dimuat sekali
+    roles, err := creatorRoles(database.DB, []models.DailyLesson{{CreatedByID: userID}})
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not check schedule conflicts",
+        })
+    }
+
+    // Setiap baris diperiksa terhadap database dan terhadap baris valid sebelumnya di file yang
+    // sama tanpa menulis apa pun, sehingga dry run tidak perlu membuka transaksi tulis
+    var accepted []importedLesson
+    for i, lesson := range lessons {
+        duplicate, err := findDuplicateLesson(database.DB, lesson)
+        if err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not check duplicate lessons",
+            })
+        }
+        duplicateRef := ""
+        if duplicate != nil {
+            duplicateRef = fmt.Sprintf("lesson #%d", duplicate.ID)
+        } else {
+            for _, other := range accepted {
+                if isDuplicateLesson(lesson, other.lesson) {
+                    duplicateRef = fmt.Sprintf("baris %d", other.row)
+                    break
+                }
+            }
+        }
+        if duplicateRef != "" {
+            rowErrors = append(rowErrors, ImportRowError{Row: rowNumbers[i], Errors: []string{
+                fmt.Sprintf("catatan mengajar yang sama sudah ada (%s)", duplicateRef),
+            }})
+            continue
+        }
+
+        conflicts, err := findScheduleConflicts(database.DB, lesson)
+        if err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not check schedule conflicts",
+            })
+        }
+        var conflictRows []int
+        for j, other := range accepted {
+            if _, ok := scheduleConflict(lesson, other.lesson, roles); ok {
+                conflictRows = append(conflictRows, j)
+            }
+        }
+        if (len(conflicts) > 0 || len(conflictRows) > 0) && !overrideConflict {
+            refs := make([]string, 0, len(conflicts)+len(conflictRows))
+            for _, conflict := range conflicts {
+                refs = append(refs, fmt.Sprintf("lesson #%d", conflict.LessonID))
+            }
+            for _, j := range conflictRows {
+                refs = append(refs, fmt.Sprintf("baris %d", accepted[j].row))
+            }
+            rowErrors = append(rowErrors, ImportRowError{Row: rowNumbers[i], Errors: []string{
+                fmt.Sprintf("jadwal bentrok dengan %s", strings.Join(refs, ", ")),
+            }})
+            continue
+        }
+
+        accepted = append(accepted, importedLesson{
+            lesson: lesson, row: rowNumbers[i], conflicts: conflicts, conflictRows: conflictRows,
+        })
+    }
+
+    sort.Slice(rowErrors, func(i, j int) bool {
+        return rowErrors[i].Row < rowErrors[j].Row
+    })
+    result := fiber.Map{
+        "dry_run":      dryRun,
+        "total_rows":   len(accepted) + len(rowErrors),
+        "valid_rows":   len(accepted),
+        "invalid_rows": len(rowErrors),
+        "errors":       rowErrors,
+        "imported":     0,
+    }
+
+    if dryRun || len(accepted) == 0 {
+        return c.JSON(result)
+    }
+
+    // Seluruh baris valid disimpan dalam satu transaksi beserta history-nya
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        for i := range accepted {
+            item := &accepted[i]
+            if err := tx.Create(&item.lesson).Error; err != nil {
+                return err
+            }
+
+            // Baris yang bentrok dengan baris sebelumnya sudah tersimpan dan punya ID
+            conflicts := item.conflicts
+            for _, j := range item.conflictRows {
+                conflicts = append(conflicts, ScheduleConflict{LessonID: accepted[j].lesson.ID})
+            }
+            description := conflictHistoryNote(fmt.Sprintf("Catatan mengajar diimpor dari %s", file.Filename), conflicts)
+            history := newLessonHistory(item.lesson, nil, action, description, userID)
+            if err := tx.Create(&history).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not import lesson records",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Mengimpor %d lesson dari %s", len(accepted), file.Filename)
+    createActivity(userEmail, "import", activityDescription)
+
+    result["imported"] = len(accepted)
+    return c.JSON(result)
+}
+
+// importedLesson adalah baris import yang lolos validasi beserta bentrok jadwal yang diabaikan,
+// baik dengan lesson di database maupun dengan baris sebelumnya (indeks di daftar baris valid)
+type importedLesson struct {
+    lesson       models.DailyLesson
+    row          int
+    conflicts    []ScheduleConflict
+    conflictRows []int
+}
+
+// validateImportedLesson memeriksa satu baris import dan mengubahnya menjadi DailyLesson
+func validateImportedLesson(req CreateLessonRequest) (models.DailyLesson, []string) {
+    var errs []string
+
+    required := []struct{ field, value string }{
+        {"nama_guru", req.NamaGuru},
+        {"mata_pelajaran", req.MataPelajaran},
+        {"kelas", req.Kelas},
+        {"pokok_materi", req.PokokMateri},
+        {"tanggal_mengajar", req.TanggalMengajar},
+    }
+    for _, r := range required {
+        if r.value == "" {
+            errs = append(errs, fmt.Sprintf("%s wajib diisi", r.field))
+        }
+    }
+
+    var tanggal time.Time
+    if req.TanggalMengajar != "" {
+        parsed, err := utils.ParseSpreadsheetDate(req.TanggalMengajar)
+        if err != nil {
+            errs = append(errs, err.Error())
+        }
+        tanggal = parsed
+    }
+
+    jamMulai, err := utils.ParseSpreadsheetClock(req.JamMulai)
+    if err != nil {
+        errs = append(errs, "jam_mulai: "+err.Error())
+    }
+    jamSelesai, err := utils.ParseSpreadsheetClock(req.JamSelesai)
+    if err != nil {
+        errs = append(errs, "jam_selesai: "+err.Error())
+    }
+    if jamMulai != "" && jamSelesai != "" && jamSelesai <= jamMulai {
+        errs = append(errs, "jam_selesai harus setelah jam_mulai")
+    }
+
+    status := strings.ToLower(req.Status)
+    if status == "" {
+        status = models.StatusTerlaksana
+    }
+    if !lessonStatuses[status] {
+        errs = append(errs, fmt.Sprintf("status %q tidak dikenal", req.Status))
+    }
+
+    lesson := models.DailyLesson{
+        NamaGuru:        req.NamaGuru,
+        MataPelajaran:   req.MataPelajaran,
+        Kelas:           req.Kelas,
+        PokokMateri:     req.PokokMateri,
+        BuktiMengajar:   req.BuktiMengajar,
+        TanggalMengajar: tanggal,
+        JamMulai:        jamMulai,
+        JamSelesai:      jamSelesai,
+        Status:          status,
+        Catatan:         req.Catatan,
+    }
+
+    return lesson, errs
+}
+
+// findHeaderRow mencari baris judul kolom di 10 baris pertama, yaitu baris pertama
+// yang memuat minimal tiga judul kolom yang dikenali di aliases
+func findHeaderRow(rows [][]string, aliases map[string]string) int {
+    for i := 0; i < len(rows) && i < 10; i++ {
+        matches := 0
+        for _, header := range rows[i] {
+            if _, ok := aliases[utils.NormalizeHeader(header)]; ok {
+                matches++
+            }
+        }
+        if matches >= 3 {
+            return i
+        }
+    }
+    return 0
+}
+
+func isBlankRow(row []string) bool {
+    for _, value := range row {
+        if strings.TrimSpace(value) != "" {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "mime/multipart"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+type importTestResult struct {
+    DryRun      bool             `json:"dry_run"`
+    ValidRows   int              `json:"valid_rows"`
+    InvalidRows int              `json:"invalid_rows"`
+    Imported    int              `json:"imported"`
+    Errors      []ImportRowError `json:"errors"`
+}
+
+func importTestLessons(t *testing.T, userID uint, query, csv string) importTestResult {
+    t.Helper()
+
+    app := fiber.New()
+    app.Post("/lessons/import", func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleTeacher))
+        c.Locals("email", "guru@sekolah.test")
+        return ImportLessons(c)
+    })
+
+    var body bytes.Buffer
+    writer := multipart.NewWriter(&body)
+    part, _ := writer.CreateFormFile("file", "lessons.csv")
+    part.Write([]byte(csv))
+    writer.Close()
+
+    req := httptest.NewRequest("POST", "/lessons/import"+query, &body)
+    req.Header.Set("Content-Type", writer.FormDataContentType())
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+
+    var result importTestResult
+    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    return result
+}
+
+func TestImportLessonsValidatesRowsAgainstFileAndDatabase(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    existing := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        JamMulai: "07:00", JamSelesai: "08:00",
+    })
+
+    // Baris 3 bentrok dengan lesson di database, baris 4 duplikat baris 2,
+    // baris 5 bentrok jam dengan baris 2 di kelas lain
+    csv := "guru,mapel,kelas,materi,tanggal,mulai,selesai\n" +
+        "guru,IPA,7B,Sel,2026-10-06,07:00,08:00\n" +
+        "guru,IPA,7C,Sel,2026-10-05,07:30,08:30\n" +
+        "guru,IPA,7B,Sel,2026-10-06,07:00,08:00\n" +
+        "guru,IPS,7D,Peta,2026-10-06,07:30,08:30\n" +
+        "guru,IPS,7D,Peta,2026-10-07,07:30,08:30\n"
+    wantErrors := []ImportRowError{
+        {Row: 3, Errors: []string{fmt.Sprintf("jadwal bentrok dengan lesson #%d", existing.ID)}},
+        {Row: 4, Errors: []string{"catatan mengajar yang sama sudah ada (baris 2)"}},
+        {Row: 5, Errors: []string{"jadwal bentrok dengan baris 2"}},
+    }
+
+    t.Run("dry run writes nothing", func(t *testing.T) {
+        result := importTestLessons(t, guru.ID, "?dry_run=true", csv)
+        if !result.DryRun || result.ValidRows != 2 || result.Imported != 0 || !reflect.DeepEqual(result.Errors, wantErrors) {
+            t.Fatalf("result = %+v, want 2 valid rows and errors %+v", result, wantErrors)
+        }
+
+        var count int64
+        database.DB.Model(&models.DailyLesson{}).Count(&count)
+        if count != 1 {
+            t.Fatalf("lessons = %d after dry run, want only lesson %d", count, existing.ID)
+        }
+    })
+
+    t.Run("override saves conflicts with history", func(t *testing.T) {
+        result := importTestLessons(t, guru.ID, "?override_conflict=true", csv)
+        if result.Imported != 4 || result.InvalidRows != 1 {
+            t.Fatalf("result = %+v, want 4 imported and the duplicate rejected", result)
+        }
+
+        var lessons []models.DailyLesson
+        database.DB.Where("id <> ?", existing.ID).Order("id").Find(&lessons)
+        if len(lessons) != 4 {
+            t.Fatalf("imported lessons = %d, want 4", len(lessons))
+        }
+
+        var history models.LessonReport
+        if err := database.DB.Where("lesson_id = ? AND action = ?", lessons[2].ID, "IMPORT").First(&history).Error; err != nil {
+            t.Fatalf("import history not recorded: %v", err)
+        }
+        want := fmt.Sprintf("Catatan mengajar diimpor dari lessons.csv (bentrok jadwal diabaikan: lesson #%d)", lessons[0].ID)
+        if history.Description != want {
+            t.Fatalf("history = %q, want %q", history.Description, want)
+        }
+    })
+}
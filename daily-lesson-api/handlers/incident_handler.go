@@ -0,0 +1,573 @@
+package handlers
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// incidentCategories adalah kategori kejadian yang diterima
+var incidentCategories = map[string]bool{
+    models.IncidentKedisiplinan: true,
+    models.IncidentPerundungan:  true,
+    models.IncidentKonflik:      true,
+    models.IncidentAkademik:     true,
+    models.IncidentKesehatan:    true,
+    models.IncidentLainnya:      true,
+}
+
+// incidentSeverities adalah tingkat keparahan yang diterima
+var incidentSeverities = map[string]bool{
+    models.SeverityRingan: true,
+    models.SeveritySedang: true,
+    models.SeverityBerat:  true,
+}
+
+// followUpStatuses adalah status tindak lanjut yang diterima
+var followUpStatuses = map[string]bool{
+    models.FollowUpBaru:     true,
+    models.FollowUpDiproses: true,
+    models.FollowUpSelesai:  true,
+}
+
+type IncidentRequest struct {
+    Kategori     string  `json:"kategori"`
+    Tingkat      string  `json:"tingkat"`
+    Deskripsi    string  `json:"deskripsi"`
+    TindakanGuru string  `json:"tindakan_guru"`
+    StudentIDs   *[]uint `json:"student_ids"`
+}
+
+type FollowUpRequest struct {
+    Status  string `json:"status_tindak_lanjut"`
+    Catatan string `json:"catatan_tindak_lanjut"`
+}
+
+// incidentCountColumns menghitung jumlah kejadian per tingkat dan yang belum selesai ditindaklanjuti
+const incidentCountColumns = `count(DISTINCT incidents.id) AS total,
+    count(DISTINCT CASE WHEN incidents.tingkat = 'ringan' THEN incidents.id END) AS ringan,
+    count(DISTINCT CASE WHEN incidents.tingkat = 'sedang' THEN incidents.id END) AS sedang,
+    count(DISTINCT CASE WHEN incidents.tingkat = 'berat' THEN incidents.id END) AS berat,
+    count(DISTINCT CASE WHEN incidents.status_tindak_lanjut <> 'selesai' THEN incidents.id END) AS belum_selesai`
+
+// validateIncident menormalkan dan memeriksa kategori, tingkat dan deskripsi kejadian
+func validateIncident(req *IncidentRequest) string {
+    req.Kategori = strings.ToLower(strings.TrimSpace(req.Kategori))
+    req.Tingkat = strings.ToLower(strings.TrimSpace(req.Tingkat))
+    req.Deskripsi = strings.TrimSpace(req.Deskripsi)
+    req.TindakanGuru = strings.TrimSpace(req.TindakanGuru)
+
+    if !incidentCategories[req.Kategori] {
+        return "kategori harus salah satu dari kedisiplinan, perundungan, konflik, akademik, kesehatan, lainnya"
+    }
+    if !incidentSeverities[req.Tingkat] {
+        return "tingkat harus salah satu dari ringan, sedang, berat"
+    }
+    if req.Deskripsi == "" {
+        return "deskripsi wajib diisi"
+    }
+    return ""
+}
+
+// incidentStudents mengambil siswa yang terlibat; semua ID harus terdaftar
+func incidentStudents(ids []uint) ([]models.Student, error) {
+    students := []models.Student{}
+    if len(ids) == 0 {
+        return students, nil
+    }
+    if err := database.DB.Where("id IN ?", ids).Find(&students).Error; err != nil {
+        return nil, err
+    }
+
+    found := map[uint]bool{}
+    for _, student := range students {
+        found[student.ID] = true
+    }
+    for _, id := range ids {
+        if !found[id] {
+            return nil, fmt.Errorf("siswa %d tidak ditemukan", id)
+        }
+    }
+    return students, nil
+}
+
+// incidentRange membaca tahun_ajaran (default tahun ajaran berjalan) menjadi rentang tanggal
+func incidentRange(c *fiber.Ctx) (string, string, string, error) {
+    tahunAjaran := c.Query("tahun_ajaran", models.TahunAjaranOf(time.Now()))
+    if !validTahunAjaran(tahunAjaran) {
+        return "", "", "", fmt.Errorf("tahun_ajaran harus berformat 2025/2026")
+    }
+    start, end := tahunAjaranRange(tahunAjaran)
+    return tahunAjaran, start, end, nil
+}
+
+// loadIncident mengambil kejadian beserta siswa yang terlibat. Guru hanya boleh mengakses
+// kejadian yang dicatatnya sendiri. Jika gagal, response sudah dikirim dan ok bernilai false.
+func loadIncident(c *fiber.Ctx) (models.Incident, bool, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var incident models.Incident
+
+    if err := database.DB.Preload("Students").First(&incident, c.Params("id")).Error; err != nil {
+        return incident, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Incident not found",
+        })
+    }
+
+    if userRole == "teacher" && incident.ReportedByID != userID {
+        return incident, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat melihat data sendiri",
+        })
+    }
+
+    return incident, true, nil
+}
+
+// GetLessonIncidents menampilkan catatan kejadian pada satu lesson
+func GetLessonIncidents(c *fiber.Ctx) error {
+    lesson, ok, err := loadOwnedLesson(c)
+    if !ok {
+        return err
+    }
+
+    var incidents []models.Incident
+    if err := database.DB.Preload("Students").Where("lesson_id = ?", lesson.ID).
+        Order("id ASC").Find(&incidents).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch incidents",
+        })
+    }
+
+    return c.JSON(incidents)
+}
+
+// CreateIncident mencatat kejadian di kelas pada lesson milik guru
+func CreateIncident(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req IncidentRequest
+
+    lesson, ok, err := loadOwnedLesson(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if message := validateIncident(&req); message != "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": message,
+        })
+    }
+
+    var studentIDs []uint
+    if req.StudentIDs != nil {
+        studentIDs = *req.StudentIDs
+    }
+    students, err := incidentStudents(studentIDs)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    incident := models.Incident{
+        LessonID:           &lesson.ID,
+        Kelas:              lesson.Kelas,
+        MataPelajaran:      lesson.MataPelajaran,
+        Tanggal:            lesson.TanggalMengajar,
+        Kategori:           req.Kategori,
+        Tingkat:            req.Tingkat,
+        Deskripsi:          req.Deskripsi,
+        TindakanGuru:       req.TindakanGuru,
+        Students:           students,
+        ReportedByID:       userID,
+        StatusTindakLanjut: models.FollowUpBaru,
+    }
+
+    if err := database.DB.Create(&incident).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create incident",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Mencatat kejadian %s (%s) melibatkan %d siswa: %s - %s %s", incident.Kategori,
+        incident.Tingkat, len(students), incident.MataPelajaran, incident.Kelas, incident.Tanggal.Format("2006-01-02"))
+    createActivity(userEmail, "incident", activityDescription)
+
+    return c.Status(fiber.StatusCreated).JSON(incident)
+}
+
+// GetIncidents menampilkan catatan kejadian dengan filter kelas, kategori, tingkat, status,
+// student_id dan rentang tanggal. Guru hanya melihat kejadian yang dicatatnya.
+func GetIncidents(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+
+    pagination, err := parsePagination(c, []string{"id", "tanggal", "tingkat", "status_tindak_lanjut", "created_at"}, "tanggal", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query := database.DB.Model(&models.Incident{}).Preload("Students")
+
+    if userRole == "teacher" {
+        query = query.Where("reported_by_id = ?", userID)
+    }
+    if kelas := c.Query("kelas"); kelas != "" {
+        query = query.Where("lower(kelas) = lower(?)", kelas)
+    }
+    if kategori := c.Query("kategori"); kategori != "" {
+        query = query.Where("kategori = ?", kategori)
+    }
+    if tingkat := c.Query("tingkat"); tingkat != "" {
+        query = query.Where("tingkat = ?", tingkat)
+    }
+    if status := c.Query("status"); status != "" {
+        query = query.Where("status_tindak_lanjut = ?", status)
+    }
+    if studentID := c.Query("student_id"); studentID != "" {
+        query = query.Where("id IN (?)", database.DB.Table("incident_students").
+            Select("incident_id").Where("student_id = ?", studentID))
+    }
+    if startDate := c.Query("start_date"); startDate != "" {
+        query = query.Where("date(tanggal) >= ?", startDate)
+    }
+    if endDate := c.Query("end_date"); endDate != "" {
+        query = query.Where("date(tanggal) <= ?", endDate)
+    }
+
+    incidents, meta, err := paginate(c, query, pagination, func(incident models.Incident) uint {
+        return incident.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch incidents",
+        })
+    }
+
+    return c.JSON(paginatedResponse(incidents, meta))
+}
+
+// GetIncident menampilkan detail satu kejadian
+func GetIncident(c *fiber.Ctx) error {
+    incident, ok, err := loadIncident(c)
+    if !ok {
+        return err
+    }
+
+    return c.JSON(incident)
+}
+
+// UpdateIncident mengubah isi catatan kejadian. Hanya pencatat (atau admin) yang boleh mengubah;
+// student_ids, jika dikirim, menggantikan daftar siswa yang terlibat.
+func UpdateIncident(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var req IncidentRequest
+
+    incident, ok, err := loadIncident(c)
+    if !ok {
+        return err
+    }
+
+    if userRole != string(models.RoleAdmin) && incident.ReportedByID != userID {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat mengubah data sendiri",
+        })
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    req.Kategori = valueOr(req.Kategori, incident.Kategori)
+    req.Tingkat = valueOr(req.Tingkat, incident.Tingkat)
+    req.Deskripsi = valueOr(req.Deskripsi, incident.Deskripsi)
+    req.TindakanGuru = valueOr(req.TindakanGuru, incident.TindakanGuru)
+    if message := validateIncident(&req); message != "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": message,
+        })
+    }
+
+    students := incident.Students
+    if req.StudentIDs != nil {
+        students, err = incidentStudents(*req.StudentIDs)
+        if err != nil {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": err.Error(),
+            })
+        }
+    }
+
+    incident.Kategori = req.Kategori
+    incident.Tingkat = req.Tingkat
+    incident.Deskripsi = req.Deskripsi
+    incident.TindakanGuru = req.TindakanGuru
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Omit("Students").Save(&incident).Error; err != nil {
+            return err
+        }
+        return tx.Model(&incident).Association("Students").Replace(students)
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update incident",
+        })
+    }
+    incident.Students = students
+
+    createActivity(userEmail, "update", fmt.Sprintf("Memperbarui catatan kejadian %s: %s - %s", incident.Kategori, incident.MataPelajaran, incident.Kelas))
+
+    return c.JSON(incident)
+}
+
+// FollowUpIncident mencatat tindak lanjut guru BK atas kejadian
+func FollowUpIncident(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req FollowUpRequest
+
+    incident, ok, err := loadIncident(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+    if !followUpStatuses[req.Status] {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "status_tindak_lanjut harus salah satu dari baru, diproses, selesai",
+        })
+    }
+
+    incident.StatusTindakLanjut = req.Status
+    incident.CatatanTindakLanjut = strings.TrimSpace(valueOr(req.Catatan, incident.CatatanTindakLanjut))
+    incident.DitanganiOlehID = &userID
+    incident.SelesaiPada = nil
+    if req.Status == models.FollowUpSelesai {
+        now := time.Now()
+        incident.SelesaiPada = &now
+    }
+
+    if err := database.DB.Model(&incident).Updates(map[string]interface{}{
+        "status_tindak_lanjut":  incident.StatusTindakLanjut,
+        "catatan_tindak_lanjut": incident.CatatanTindakLanjut,
+        "ditangani_oleh_id":     incident.DitanganiOlehID,
+        "selesai_pada":          incident.SelesaiPada,
+    }).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update incident",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Menindaklanjuti kejadian #%d (%s - %s): %s", incident.ID,
+        incident.Kategori, incident.Kelas, incident.StatusTindakLanjut))
+
+    return c.JSON(incident)
+}
+
+// DeleteIncident menghapus catatan kejadian. Guru hanya dapat menghapus catatannya sendiri
+// yang belum ditindaklanjuti.
+func DeleteIncident(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+
+    incident, ok, err := loadIncident(c)
+    if !ok {
+        return err
+    }
+
+    if userRole != string(models.RoleAdmin) {
+        if incident.ReportedByID != userID {
+            return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+                "error": "Anda hanya dapat mengubah data sendiri",
+            })
+        }
+        if incident.StatusTindakLanjut != models.FollowUpBaru {
+            return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+                "error": "Kejadian yang sudah ditindaklanjuti tidak dapat dihapus",
+            })
+        }
+    }
+
+    if err := database.DB.Delete(&incident).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete incident",
+        })
+    }
+
+    createActivity(userEmail, "delete", fmt.Sprintf("Menghapus catatan kejadian %s: %s - %s", incident.Kategori, incident.MataPelajaran, incident.Kelas))
+
+    return c.JSON(fiber.Map{
+        "message": "Incident deleted successfully",
+    })
+}
+
+// IncidentCount adalah jumlah kejadian per tingkat keparahan
+type IncidentCount struct {
+    Total        int64 `json:"total"`
+    Ringan       int64 `json:"ringan"`
+    Sedang       int64 `json:"sedang"`
+    Berat        int64 `json:"berat"`
+    BelumSelesai int64 `json:"belum_selesai"`
+}
+
+type studentIncidents struct {
+    StudentID uint   `json:"student_id"`
+    NIS       string `json:"nis"`
+    Nama      string `json:"nama"`
+    Kelas     string `json:"kelas"`
+    Terakhir  string `json:"terakhir"`
+    IncidentCount
+}
+
+// studentIncidentsInRange adalah query kejadian per siswa yang tanggalnya di antara start dan end
+func studentIncidentsInRange(start, end string) *gorm.DB {
+    return database.DB.Table("incident_students").
+        Joins("JOIN incidents ON incidents.id = incident_students.incident_id AND incidents.deleted_at IS NULL").
+        Where("date(incidents.tanggal) BETWEEN ? AND ?", start, end)
+}
+
+// GetStudentIncidentRecap merangkum kejadian per siswa dalam satu tahun ajaran,
+// diurutkan dari siswa dengan kejadian terbanyak
+func GetStudentIncidentRecap(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    kelas := c.Query("kelas")
+
+    tahunAjaran, start, end, err := incidentRange(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query := studentIncidentsInRange(start, end).
+        Select("incident_students.student_id, max(date(incidents.tanggal)) AS terakhir, " + incidentCountColumns).
+        Group("incident_students.student_id").
+        Order("total DESC, berat DESC")
+    if kelas != "" {
+        query = query.Where("lower(incidents.kelas) = lower(?)", kelas)
+    }
+
+    var recap []studentIncidents
+    if err := query.Scan(&recap).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch incident recap",
+        })
+    }
+
+    studentIDs := make([]uint, len(recap))
+    for i, row := range recap {
+        studentIDs[i] = row.StudentID
+    }
+    var students []models.Student
+    if err := database.DB.Unscoped().Where("id IN ?", studentIDs).Find(&students).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch incident recap",
+        })
+    }
+    studentByID := map[uint]models.Student{}
+    for _, student := range students {
+        studentByID[student.ID] = student
+    }
+    for i := range recap {
+        student := studentByID[recap[i].StudentID]
+        recap[i].NIS = student.NIS
+        recap[i].Nama = student.Nama
+        recap[i].Kelas = student.Kelas
+    }
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat rekap kejadian siswa tahun ajaran %s", tahunAjaran))
+
+    return c.JSON(fiber.Map{
+        "tahun_ajaran": tahunAjaran,
+        "kelas":        kelas,
+        "data":         recap,
+    })
+}
+
+type incidentGroupCount struct {
+    Label string `json:"label"`
+    Total int64  `json:"total"`
+}
+
+// GetStudentIncidentSummary menampilkan ringkasan dan riwayat kejadian satu siswa
+// dalam satu tahun ajaran
+func GetStudentIncidentSummary(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var student models.Student
+
+    if err := database.DB.Unscoped().First(&student, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Student not found",
+        })
+    }
+
+    tahunAjaran, start, end, err := incidentRange(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    var total IncidentCount
+    if err := studentIncidentsInRange(start, end).Select(incidentCountColumns).
+        Where("incident_students.student_id = ?", student.ID).Scan(&total).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch incident summary",
+        })
+    }
+
+    var perKategori []incidentGroupCount
+    if err := studentIncidentsInRange(start, end).
+        Select("incidents.kategori AS label, count(*) AS total").
+        Where("incident_students.student_id = ?", student.ID).
+        Group("incidents.kategori").Order("total DESC").Scan(&perKategori).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch incident summary",
+        })
+    }
+
+    var incidents []models.Incident
+    if err := database.DB.Preload("Students").
+        Where("id IN (?)", studentIncidentsInRange(start, end).Select("incidents.id").
+            Where("incident_students.student_id = ?", student.ID)).
+        Order("tanggal DESC, id DESC").Find(&incidents).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch incident summary",
+        })
+    }
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat ringkasan kejadian siswa %s tahun ajaran %s", student.Nama, tahunAjaran))
+
+    return c.JSON(fiber.Map{
+        "student":      student,
+        "tahun_ajaran": tahunAjaran,
+        "total":        total,
+        "per_kategori": perKategori,
+        "data":         incidents,
+    })
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func incidentTestApp(user models.User) *fiber.App {
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", user.ID)
+        c.Locals("role", string(user.Role))
+        c.Locals("email", user.Email)
+        return c.Next()
+    })
+    app.Post("/lessons/:id/incidents", CreateIncident)
+    app.Get("/incidents", GetIncidents)
+    app.Put("/incidents/:id/follow-up", FollowUpIncident)
+    app.Delete("/incidents/:id", DeleteIncident)
+    return app
+}
+
+func sendIncidentRequest(t *testing.T, app *fiber.App, method, path string, body interface{}) (int, []byte) {
+    t.Helper()
+
+    var reader io.Reader
+    if body != nil {
+        data, _ := json.Marshal(body)
+        reader = bytes.NewReader(data)
+    }
+    req := httptest.NewRequest(method, path, reader)
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    data, _ := io.ReadAll(resp.Body)
+    return resp.StatusCode, data
+}
+
+func TestCreateIncident(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    other := createTestUser(t, "lain", models.RoleTeacher)
+    andi := createTestStudent(t, "7001", "Andi", "7A", nil)
+    lesson := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+    })
+    path := fmt.Sprintf("/lessons/%d/incidents", lesson.ID)
+    app := incidentTestApp(guru)
+
+    missing := []uint{999}
+    for name, req := range map[string]IncidentRequest{
+        "unknown kategori": {Kategori: "tawuran", Tingkat: models.SeverityRingan, Deskripsi: "x"},
+        "unknown tingkat":  {Kategori: models.IncidentKonflik, Tingkat: "parah", Deskripsi: "x"},
+        "empty deskripsi":  {Kategori: models.IncidentKonflik, Tingkat: models.SeverityRingan, Deskripsi: "  "},
+        "unknown student":  {Kategori: models.IncidentKonflik, Tingkat: models.SeverityRingan, Deskripsi: "x", StudentIDs: &missing},
+    } {
+        if status, body := sendIncidentRequest(t, app, "POST", path, req); status != fiber.StatusBadRequest {
+            t.Errorf("%s: status = %d (%s), want 400", name, status, body)
+        }
+    }
+
+    students := []uint{andi.ID}
+    req := IncidentRequest{Kategori: " Konflik ", Tingkat: "SEDANG", Deskripsi: "Bertengkar saat diskusi", StudentIDs: &students}
+    if status, _ := sendIncidentRequest(t, incidentTestApp(other), "POST", path, req); status != fiber.StatusForbidden {
+        t.Errorf("other teacher status = %d, want 403", status)
+    }
+
+    status, body := sendIncidentRequest(t, app, "POST", path, req)
+    if status != fiber.StatusCreated {
+        t.Fatalf("status = %d (%s), want 201", status, body)
+    }
+    var incident models.Incident
+    json.Unmarshal(body, &incident)
+    if incident.Kategori != models.IncidentKonflik || incident.Tingkat != models.SeveritySedang || incident.Kelas != "7A" ||
+        incident.StatusTindakLanjut != models.FollowUpBaru || len(incident.Students) != 1 || incident.Students[0].ID != andi.ID {
+        t.Fatalf("incident = %+v, want a new 7A konflik involving Andi", incident)
+    }
+}
+
+func TestIncidentFollowUpAndDelete(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    other := createTestUser(t, "lain", models.RoleTeacher)
+    counselor := createTestUser(t, "bk", models.RoleCounselor)
+    andi := createTestStudent(t, "7101", "Andi", "7A", nil)
+    budi := createTestStudent(t, "7102", "Budi", "7B", nil)
+
+    incidents := []models.Incident{
+        {Kelas: "7A", Tanggal: testDate("2026-10-05"), Kategori: models.IncidentKonflik, Tingkat: models.SeverityRingan,
+            Deskripsi: "a", Students: []models.Student{andi}, ReportedByID: guru.ID, StatusTindakLanjut: models.FollowUpBaru},
+        {Kelas: "7B", Tanggal: testDate("2026-10-06"), Kategori: models.IncidentAkademik, Tingkat: models.SeverityBerat,
+            Deskripsi: "b", Students: []models.Student{budi}, ReportedByID: other.ID, StatusTindakLanjut: models.FollowUpBaru},
+    }
+    if err := database.DB.Create(&incidents).Error; err != nil {
+        t.Fatalf("create incidents: %v", err)
+    }
+    first := fmt.Sprintf("/incidents/%d", incidents[0].ID)
+
+    t.Run("teachers only list their own incidents", func(t *testing.T) {
+        var result struct {
+            Data []models.Incident `json:"data"`
+        }
+        _, body := sendIncidentRequest(t, incidentTestApp(guru), "GET", "/incidents", nil)
+        json.Unmarshal(body, &result)
+        if len(result.Data) != 1 || result.Data[0].ID != incidents[0].ID {
+            t.Fatalf("teacher incidents = %+v, want only incident %d", result.Data, incidents[0].ID)
+        }
+
+        _, body = sendIncidentRequest(t, incidentTestApp(counselor), "GET", fmt.Sprintf("/incidents?student_id=%d", budi.ID), nil)
+        json.Unmarshal(body, &result)
+        if len(result.Data) != 1 || result.Data[0].ID != incidents[1].ID {
+            t.Fatalf("counselor incidents = %+v, want only incident %d", result.Data, incidents[1].ID)
+        }
+    })
+
+    t.Run("follow up marks completion", func(t *testing.T) {
+        app := incidentTestApp(counselor)
+        if status, _ := sendIncidentRequest(t, app, "PUT", first+"/follow-up", FollowUpRequest{Status: "ditutup"}); status != fiber.StatusBadRequest {
+            t.Fatalf("unknown status = %d, want 400", status)
+        }
+
+        status, body := sendIncidentRequest(t, app, "PUT", first+"/follow-up", FollowUpRequest{Status: "Selesai", Catatan: " Sudah dimediasi "})
+        if status != fiber.StatusOK {
+            t.Fatalf("status = %d (%s), want 200", status, body)
+        }
+        var saved models.Incident
+        database.DB.First(&saved, incidents[0].ID)
+        if saved.StatusTindakLanjut != models.FollowUpSelesai || saved.CatatanTindakLanjut != "Sudah dimediasi" ||
+            saved.DitanganiOlehID == nil || *saved.DitanganiOlehID != counselor.ID || saved.SelesaiPada == nil {
+            t.Fatalf("incident = %+v, want it completed by the counselor", saved)
+        }
+
+        // Membuka kembali kejadian menghapus waktu selesai
+        if status, body := sendIncidentRequest(t, app, "PUT", first+"/follow-up", FollowUpRequest{Status: models.FollowUpDiproses}); status != fiber.StatusOK {
+            t.Fatalf("reopen status = %d (%s), want 200", status, body)
+        }
+        var reopened models.Incident
+        database.DB.First(&reopened, incidents[0].ID)
+        if reopened.StatusTindakLanjut != models.FollowUpDiproses || reopened.SelesaiPada != nil || reopened.CatatanTindakLanjut != "Sudah dimediasi" {
+            t.Fatalf("incident = %+v, want it reopened with the earlier note", reopened)
+        }
+    })
+
+    t.Run("followed up incidents cannot be deleted by teachers", func(t *testing.T) {
+        if status, _ := sendIncidentRequest(t, incidentTestApp(guru), "DELETE", first, nil); status != fiber.StatusConflict {
+            t.Fatalf("status = %d, want 409", status)
+        }
+        second := fmt.Sprintf("/incidents/%d", incidents[1].ID)
+        if status, _ := sendIncidentRequest(t, incidentTestApp(guru), "DELETE", second, nil); status != fiber.StatusForbidden {
+            t.Fatalf("other teacher's incident status = %d, want 403", status)
+        }
+        if status, body := sendIncidentRequest(t, incidentTestApp(other), "DELETE", second, nil); status != fiber.StatusOK {
+            t.Fatalf("own new incident status = %d (%s), want 200", status, body)
+        }
+
+        var count int64
+        database.DB.Model(&models.Incident{}).Count(&count)
+        if count != 1 {
+            t.Errorf("incidents = %d, want 1 after deleting", count)
+        }
+    })
+}
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+    "daily-lesson-api/utils"
+)
+
+var leaveTypes = map[string]bool{
+    models.LeaveIzin:  true,
+    models.LeaveSakit: true,
+    models.LeaveDinas: true,
+}
+
+var allowedAttachmentExtensions = map[string]bool{
+    ".jpg":  true,
+    ".jpeg": true,
+    ".png":  true,
+    ".pdf":  true,
+}
+
+type ReviewLeaveRequest struct {
+    Catatan string `json:"catatan"`
+}
+
+// CreateLeaveRequest menerima pengajuan izin (multipart: jenis, tanggal_mulai, tanggal_selesai,
+// alasan, file opsional). Admin/supervisor bisa mengajukan atas nama guru lewat teacher_id.
+func CreateLeaveRequest(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+
+    teacherID := userID
+    if userRole != "teacher" {
+        value, err := strconv.ParseUint(c.FormValue("teacher_id"), 10, 64)
+        if err != nil {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "teacher_id wajib diisi",
+            })
+        }
+        teacherID = uint(value)
+    }
+
+    teacher, err := findTeacher(teacherID)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Guru tidak ditemukan",
+        })
+    }
+
+    jenis := strings.ToLower(c.FormValue("jenis"))
+    if !leaveTypes[jenis] {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Jenis izin harus izin, sakit atau dinas",
+        })
+    }
+
+    mulai, errMulai := time.Parse("2006-01-02", c.FormValue("tanggal_mulai"))
+    selesai, errSelesai := time.Parse("2006-01-02", c.FormValue("tanggal_selesai"))
+    if errMulai != nil || errSelesai != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format tanggal tidak valid. Gunakan format YYYY-MM-DD",
+        })
+    }
+    if selesai.Before(mulai) {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "tanggal_selesai tidak boleh sebelum tanggal_mulai",
+        })
+    }
+
+    var overlapping int64
+    database.DB.Model(&models.LeaveRequest{}).
+        Where("teacher_id = ? AND status IN ?", teacher.ID, []string{models.LeavePending, models.LeaveApproved}).
+        Where("date(tanggal_mulai) <= ? AND date(tanggal_selesai) >= ?", selesai.Format("2006-01-02"), mulai.Format("2006-01-02")).
+        Count(&overlapping)
+    if overlapping > 0 {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Sudah ada pengajuan izin pada rentang tanggal tersebut",
+        })
+    }
+
+    leave := models.LeaveRequest{
+        TeacherID:      teacher.ID,
+        Jenis:          jenis,
+        TanggalMulai:   mulai,
+        TanggalSelesai: selesai,
+        Alasan:         c.FormValue("alasan"),
+        Status:         models.LeavePending,
+        CreatedByID:    userID,
+    }
+
+    if file, err := c.FormFile("file"); err == nil {
+        ext := strings.ToLower(filepath.Ext(file.Filename))
+        if !allowedAttachmentExtensions[ext] {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "Format lampiran tidak didukung. Gunakan JPG, PNG atau PDF",
+            })
+        }
+
+        dir, err := utils.UploadDir("leave")
+        if err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not prepare upload directory",
+            })
+        }
+
+        path := filepath.Join(dir, fmt.Sprintf("%d_%d%s", teacher.ID, time.Now().UnixNano(), ext))
+        if err := c.SaveFile(file, path); err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not save attachment",
+            })
+        }
+        leave.Lampiran = path
+    }
+
+    if err := database.DB.Create(&leave).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create leave request",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Mengajukan %s: %s (%s s.d. %s)", jenis, teacher.Name,
+        mulai.Format("2006-01-02"), selesai.Format("2006-01-02"))
+    createActivity(userEmail, "create", activityDescription)
+
+    database.DB.Preload("Teacher", userSummaryColumns).First(&leave, leave.ID)
+    return c.Status(fiber.StatusCreated).JSON(leave)
+}
+
+// GetLeaveRequests menampilkan pengajuan izin; guru hanya melihat miliknya sendiri
+func GetLeaveRequests(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+
+    pagination, err := parsePagination(c, []string{"id", "tanggal_mulai", "status", "created_at"}, "created_at", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query := database.DB.Model(&models.LeaveRequest{}).Preload("Teacher", userSummaryColumns)
+
+    if userRole == "teacher" {
+        query = query.Where("teacher_id = ?", userID)
+    } else if teacherID := c.Query("teacher_id"); teacherID != "" {
+        query = query.Where("teacher_id = ?", teacherID)
+    }
+
+    if status := c.Query("status"); status != "" {
+        query = query.Where("status = ?", status)
+    }
+
+    leaves, meta, err := paginate(c, query, pagination, func(leave models.LeaveRequest) uint {
+        return leave.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch leave requests",
+        })
+    }
+
+    return c.JSON(paginatedResponse(leaves, meta))
+}
+
+// GetLeaveAttachment mengirim file lampiran pengajuan izin
+func GetLeaveAttachment(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var leave models.LeaveRequest
+
+    if err := database.DB.First(&leave, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Leave request not found",
+        })
+    }
+
+    if userRole == "teacher" && leave.TeacherID != userID {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat melihat data sendiri",
+        })
+    }
+
+    if leave.Lampiran == "" {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Pengajuan ini tidak memiliki lampiran",
+        })
+    }
+    if _, err := os.Stat(leave.Lampiran); err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "File lampiran tidak ditemukan",
+        })
+    }
+
+    return c.SendFile(leave.Lampiran)
+}
+
+// CancelLeaveRequest membatalkan pengajuan izin yang belum diproses
+func CancelLeaveRequest(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var leave models.LeaveRequest
+
+    if err := database.DB.First(&leave, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Leave request not found",
+        })
+    }
+
+    if userRole == "teacher" && leave.TeacherID != userID {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat membatalkan pengajuan sendiri",
+        })
+    }
+
+    if leave.Status != models.LeavePending {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Hanya pengajuan yang menunggu persetujuan yang dapat dibatalkan",
+        })
+    }
+
+    leave.Status = models.LeaveCancelled
+    if err := database.DB.Save(&leave).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update leave request",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Membatalkan pengajuan izin #%d", leave.ID))
+
+    return c.JSON(leave)
+}
+
+// ApproveLeaveRequest menyetujui izin. Lesson guru pada rentang tanggal izin ditandai
+// tidak terlaksana dan setiap lesson mendapat slot guru pengganti yang terbuka.
+func ApproveLeaveRequest(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var leave models.LeaveRequest
+    var req ReviewLeaveRequest
+
+    if len(c.Body()) > 0 {
+        if err := c.BodyParser(&req); err != nil {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "Cannot parse JSON",
+            })
+        }
+    }
+
+    if err := database.DB.Preload("Teacher", userSummaryColumns).First(&leave, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Leave request not found",
+        })
+    }
+
+    if leave.Status != models.LeavePending {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Pengajuan ini sudah diproses",
+        })
+    }
+
+    now := time.Now()
+    leave.Status = models.LeaveApproved
+    leave.ReviewedByID = &userID
+    leave.ReviewedAt = &now
+    leave.CatatanReview = req.Catatan
+
+    alasan := fmt.Sprintf("Guru %s (pengajuan izin #%d)", leave.Jenis, leave.ID)
+    if leave.Alasan != "" {
+        alasan = fmt.Sprintf("Guru %s: %s (pengajuan izin #%d)", leave.Jenis, leave.Alasan, leave.ID)
+    }
+
+    var lessons []models.DailyLesson
+    substitutions := []models.Substitution{}
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        // Status dicek ulang di dalam transaksi agar izin tidak disetujui dua kali
+        result := tx.Model(&models.LeaveRequest{}).
+            Where("id = ? AND status = ?", leave.ID, models.LeavePending).
+            Updates(map[string]interface{}{
+                "status":         leave.Status,
+                "reviewed_by_id": userID,
+                "reviewed_at":    now,
+                "catatan_review": leave.CatatanReview,
+            })
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return errStatusChanged
+        }
+
+        // Draft, lesson yang dibatalkan atau sudah diisi guru pengganti tidak ikut diubah
+        if err := tx.
+            Where("created_by_id = ? AND date(tanggal_mengajar) BETWEEN ? AND ?",
+                leave.TeacherID, leave.TanggalMulai.Format("2006-01-02"), leave.TanggalSelesai.Format("2006-01-02")).
+            Where("status NOT IN ? AND guru_pengganti_id IS NULL",
+                []string{models.StatusDraft, models.StatusDibatalkan, models.StatusTidakTerlaksana}).
+            Order("tanggal_mengajar ASC, jam_mulai ASC").Find(&lessons).Error; err != nil {
+            return err
+        }
+
+        for i := range lessons {
+            lesson := &lessons[i]
+            before := lesson.Snapshot()
+
+            lesson.Status = models.StatusTidakTerlaksana
+            lesson.AlasanTidakTerlaksana = alasan
+            lesson.LeaveRequestID = &leave.ID
+            if err := tx.Save(lesson).Error; err != nil {
+                return err
+            }
+
+            history := newLessonHistory(*lesson, &before, "LEAVE", fmt.Sprintf("Catatan mengajar tidak terlaksana: %s", alasan), userID)
+            if err := tx.Create(&history).Error; err != nil {
+                return err
+            }
+
+            substitution := models.Substitution{
+                OriginalTeacherID: leave.TeacherID,
+                Tanggal:           lesson.TanggalMengajar,
+                Kelas:             lesson.Kelas,
+                MataPelajaran:     lesson.MataPelajaran,
+                JamMulai:          lesson.JamMulai,
+                JamSelesai:        lesson.JamSelesai,
+                Alasan:            alasan,
+                Status:            models.SubstitutionOpen,
+                LeaveRequestID:    &leave.ID,
+                CreatedByID:       userID,
+            }
+            if err := tx.Create(&substitution).Error; err != nil {
+                return err
+            }
+            substitutions = append(substitutions, substitution)
+        }
+        return nil
+    })
+    if errors.Is(err, errStatusChanged) {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Pengajuan ini sudah diproses",
+        })
+    }
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update leave request",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Menyetujui %s %s (%s s.d. %s), %d lesson tidak terlaksana",
+        leave.Jenis, leave.Teacher.Name, leave.TanggalMulai.Format("2006-01-02"),
+        leave.TanggalSelesai.Format("2006-01-02"), len(lessons))
+    createActivity(userEmail, "approve", activityDescription)
+
+    return c.JSON(fiber.Map{
+        "leave_request":    leave,
+        "affected_lessons": len(lessons),
+        "substitutions":    substitutions,
+    })
+}
+
+// RejectLeaveRequest menolak pengajuan izin
+func RejectLeaveRequest(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var leave models.LeaveRequest
+    var req ReviewLeaveRequest
+
+    if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Catatan) == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Catatan penolakan wajib diisi",
+        })
+    }
+
+    if err := database.DB.Preload("Teacher", userSummaryColumns).First(&leave, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Leave request not found",
+        })
+    }
+
+    if leave.Status != models.LeavePending {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Pengajuan ini sudah diproses",
+        })
+    }
+
+    now := time.Now()
+    leave.Status = models.LeaveRejected
+    leave.ReviewedByID = &userID
+    leave.ReviewedAt = &now
+    leave.CatatanReview = req.Catatan
+
+    if err := database.DB.Save(&leave).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update leave request",
+        })
+    }
+
+    createActivity(userEmail, "reject", fmt.Sprintf("Menolak pengajuan izin #%d (%s)", leave.ID, leave.Teacher.Name))
+
+    return c.JSON(leave)
+}
+
+// approvedLeaveDays mengembalikan tanggal (YYYY-MM-DD) izin yang disetujui per guru dalam rentang tanggal.
+// teacherIDs kosong menghasilkan map kosong, bukan izin seluruh guru.
+func approvedLeaveDays(teacherIDs []uint, startDate, endDate string) (map[uint]map[string]bool, error) {
+    days := map[uint]map[string]bool{}
+
+    if len(teacherIDs) == 0 {
+        return days, nil
+    }
+
+    query := database.DB.Where("status = ? AND teacher_id IN ?", models.LeaveApproved, teacherIDs)
+    if startDate != "" && endDate != "" {
+        query = query.Where("date(tanggal_mulai) <= ? AND date(tanggal_selesai) >= ?", endDate, startDate)
+    }
+
+    var leaves []models.LeaveRequest
+    if err := query.Find(&leaves).Error; err != nil {
+        return nil, err
+    }
+
+    for _, leave := range leaves {
+        if days[leave.TeacherID] == nil {
+            days[leave.TeacherID] = map[string]bool{}
+        }
+        for day := leave.TanggalMulai; !day.After(leave.TanggalSelesai); day = day.AddDate(0, 0, 1) {
+            date := day.Format("2006-01-02")
+            if (startDate == "" || date >= startDate) && (endDate == "" || date <= endDate) {
+                days[leave.TeacherID][date] = true
+            }
+        }
+    }
+    return days, nil
+}
+
+// onApprovedLeave bernilai true jika lesson jatuh pada hari izin guru aslinya
+// dan tidak diajar guru pengganti
+func onApprovedLeave(lesson models.DailyLesson, leaveDays map[uint]map[string]bool) bool {
+    if lesson.GuruPenggantiID != nil {
+        return false
+    }
+    return leaveDays[lesson.CreatedByID][lesson.TanggalMengajar.Format("2006-01-02")]
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func approveLeaveRequest(t *testing.T, userID uint, leaveID uint) (int, []byte) {
+    t.Helper()
+
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleSupervisor))
+        c.Locals("email", "supervisor@sekolah.test")
+        return c.Next()
+    })
+    app.Put("/leave-requests/:id/approve", ApproveLeaveRequest)
+
+    resp, err := app.Test(httptest.NewRequest("PUT", fmt.Sprintf("/leave-requests/%d/approve", leaveID), nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    body, _ := io.ReadAll(resp.Body)
+    return resp.StatusCode, body
+}
+
+func TestApproveLeaveRequest(t *testing.T) {
+    setupTestDB(t)
+    supervisor := createTestUser(t, "supervisor", models.RoleSupervisor)
+    guru := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    leave := models.LeaveRequest{
+        TeacherID: guru.ID, Jenis: models.LeaveSakit, Status: models.LeavePending, CreatedByID: guru.ID,
+        TanggalMulai: testDate("2026-10-05"), TanggalSelesai: testDate("2026-10-06"),
+    }
+    if err := database.DB.Create(&leave).Error; err != nil {
+        t.Fatalf("create leave: %v", err)
+    }
+    taught := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+    })
+    draft := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID,
+        Status: models.StatusDraft,
+    })
+    outside := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7C", TanggalMengajar: testDate("2026-10-07"), CreatedByID: guru.ID,
+    })
+
+    status, body := approveLeaveRequest(t, supervisor.ID, leave.ID)
+    if status != fiber.StatusOK {
+        t.Fatalf("status = %d (%s), want 200", status, body)
+    }
+    var result struct {
+        AffectedLessons int                   `json:"affected_lessons"`
+        Substitutions   []models.Substitution `json:"substitutions"`
+    }
+    json.Unmarshal(body, &result)
+    if result.AffectedLessons != 1 || len(result.Substitutions) != 1 || result.Substitutions[0].Kelas != "7A" {
+        t.Fatalf("result = %+v, want only the 7A lesson with an open substitution", result)
+    }
+
+    var saved models.LeaveRequest
+    database.DB.First(&saved, leave.ID)
+    if saved.Status != models.LeaveApproved || saved.ReviewedByID == nil || *saved.ReviewedByID != supervisor.ID || saved.ReviewedAt == nil {
+        t.Fatalf("leave = %+v, want it approved by the supervisor", saved)
+    }
+
+    wantStatus := map[uint]string{
+        taught.ID:  models.StatusTidakTerlaksana,
+        draft.ID:   models.StatusDraft,
+        outside.ID: models.StatusTerlaksana,
+    }
+    for id, want := range wantStatus {
+        var lesson models.DailyLesson
+        database.DB.First(&lesson, id)
+        if lesson.Status != want {
+            t.Errorf("lesson %s status = %q, want %q", lesson.Kelas, lesson.Status, want)
+        }
+    }
+
+    // Persetujuan kedua ditolak dan tidak membuka slot pengganti baru
+    if status, body := approveLeaveRequest(t, supervisor.ID, leave.ID); status != fiber.StatusConflict {
+        t.Fatalf("second approval status = %d (%s), want 409", status, body)
+    }
+    var substitutions int64
+    database.DB.Model(&models.Substitution{}).Count(&substitutions)
+    if substitutions != 1 {
+        t.Errorf("substitutions = %d, want 1 after a repeated approval", substitutions)
+    }
+}
+
+func TestApproveLeaveRequestRejectsProcessedLeave(t *testing.T) {
+    setupTestDB(t)
+    supervisor := createTestUser(t, "supervisor", models.RoleSupervisor)
+    guru := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+    })
+
+    for _, status := range []string{models.LeaveRejected, models.LeaveCancelled} {
+        leave := models.LeaveRequest{
+            TeacherID: guru.ID, Jenis: models.LeaveIzin, Status: status, CreatedByID: guru.ID,
+            TanggalMulai: testDate("2026-10-05"), TanggalSelesai: testDate("2026-10-05"),
+        }
+        if err := database.DB.Create(&leave).Error; err != nil {
+            t.Fatalf("create leave: %v", err)
+        }
+        if code, body := approveLeaveRequest(t, supervisor.ID, leave.ID); code != fiber.StatusConflict {
+            t.Errorf("%s: status = %d (%s), want 409", status, code, body)
+        }
+    }
+
+    var lesson models.DailyLesson
+    database.DB.First(&lesson)
+    if lesson.Status != models.StatusTerlaksana || lesson.LeaveRequestID != nil {
+        t.Errorf("lesson = %+v, want it untouched", lesson)
+    }
+}
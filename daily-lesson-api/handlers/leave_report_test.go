@@ -0,0 +1,129 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http/httptest"
+    "net/url"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func teacherReportSummary(t *testing.T, guru string) TeacherReportSummary {
+    t.Helper()
+
+    app := fiber.New()
+    app.Get("/reports/teacher/summary", func(c *fiber.Ctx) error {
+        c.Locals("email", "supervisor@sekolah.test")
+        return GetTeacherReportSummary(c)
+    })
+
+    query := url.Values{"guru": {guru}, "start_date": {"2026-10-01"}, "end_date": {"2026-10-31"}}
+    resp, err := app.Test(httptest.NewRequest("GET", "/reports/teacher/summary?"+query.Encode(), nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+
+    var summary TeacherReportSummary
+    if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    return summary
+}
+
+func TestTeacherReportCountsOnlyRequestedTeacherLeave(t *testing.T) {
+    setupTestDB(t)
+    guruA := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    guruB := createTestUser(t, "Pak Budi", models.RoleTeacher)
+
+    leave := models.LeaveRequest{
+        TeacherID: guruA.ID, Jenis: models.LeaveSakit, Status: models.LeaveApproved,
+        TanggalMulai: testDate("2026-10-05"), TanggalSelesai: testDate("2026-10-06"),
+    }
+    if err := database.DB.Create(&leave).Error; err != nil {
+        t.Fatalf("create leave: %v", err)
+    }
+
+    // Lesson guru A pada hari izin: satu tidak terlaksana, satu diisi guru B sebagai pengganti
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"),
+        CreatedByID: guruA.ID, Status: models.StatusTidakTerlaksana, LeaveRequestID: &leave.ID,
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"),
+        CreatedByID: guruA.ID, GuruPengganti: "Pak Budi", GuruPenggantiID: &guruB.ID,
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-07"), CreatedByID: guruA.ID,
+    })
+
+    t.Run("teacher on leave", func(t *testing.T) {
+        summary := teacherReportSummary(t, "Bu Ani")
+        if summary.HariIzin != 2 || summary.Izin != 1 || summary.Total != 2 || summary.Diganti != 1 {
+            t.Fatalf("summary = %+v, want hari_izin 2, izin 1, total 2, diganti 1", summary)
+        }
+    })
+
+    t.Run("substitute does not inherit leave", func(t *testing.T) {
+        summary := teacherReportSummary(t, "Pak Budi")
+        if summary.HariIzin != 0 || summary.Izin != 0 || summary.Total != 1 || summary.Diganti != 1 {
+            t.Fatalf("summary = %+v, want hari_izin 0, izin 0, total 1, diganti 1", summary)
+        }
+    })
+
+    t.Run("partial name counts the matched teacher's leave", func(t *testing.T) {
+        summary := teacherReportSummary(t, "Ani")
+        if summary.HariIzin != 2 || summary.Izin != 1 || summary.Total != 2 {
+            t.Fatalf("summary = %+v, want hari_izin 2, izin 1, total 2", summary)
+        }
+    })
+
+    t.Run("empty name covers every teacher", func(t *testing.T) {
+        summary := teacherReportSummary(t, "")
+        if summary.HariIzin != 2 || summary.Izin != 1 || summary.Total != 2 {
+            t.Fatalf("summary = %+v, want hari_izin 2, izin 1, total 2", summary)
+        }
+    })
+
+    t.Run("empty report has no leave", func(t *testing.T) {
+        summary := teacherReportSummary(t, "Bu Citra")
+        if summary != (TeacherReportSummary{}) {
+            t.Fatalf("summary = %+v, want empty", summary)
+        }
+    })
+}
+
+func TestTeacherReportReturnsLessonArray(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID,
+        Status: models.StatusDraft,
+    })
+
+    app := fiber.New()
+    app.Get("/reports/teacher", func(c *fiber.Ctx) error {
+        c.Locals("email", "supervisor@sekolah.test")
+        return GetTeacherReport(c)
+    })
+
+    resp, err := app.Test(httptest.NewRequest("GET", "/reports/teacher?guru=Ani", nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    var lessons []models.DailyLesson
+    if err := json.NewDecoder(resp.Body).Decode(&lessons); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    if len(lessons) != 1 || lessons[0].Kelas != "7A" {
+        t.Fatalf("lessons = %+v, want only the confirmed 7A lesson", lessons)
+    }
+}
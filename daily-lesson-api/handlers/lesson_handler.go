@@ -2,7 +2,10 @@ package handlers
 
 import (
     "fmt"
+    "strings"
     "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
     "daily-lesson-api/database"
     "daily-lesson-api/models"
     "time"
@@ -21,6 +24,59 @@ type CreateLessonRequest struct {
     Catatan        string `json:"catatan"`
 }
 
+// UpdateLessonRequest berisi field yang boleh diubah lewat UpdateLesson. Bukti mengajar,
+// verifikasi, dan relasi lesson tidak ikut karena punya endpoint masing-masing.
+type UpdateLessonRequest struct {
+    NamaGuru        *string `json:"nama_guru"`
+    MataPelajaran   *string `json:"mata_pelajaran"`
+    Kelas           *string `json:"kelas"`
+    PokokMateri     *string `json:"pokok_materi"`
+    TanggalMengajar *string `json:"tanggal_mengajar"`
+    JamMulai        *string `json:"jam_mulai"`
+    JamSelesai      *string `json:"jam_selesai"`
+    Status          *string `json:"status"`
+    Catatan         *string `json:"catatan"`
+}
+
+// updates mengubah request menjadi kolom yang disimpan, hanya untuk field yang dikirim
+func (req UpdateLessonRequest) updates() (map[string]interface{}, error) {
+    updates := map[string]interface{}{}
+    columns := map[string]*string{
+        "nama_guru":      req.NamaGuru,
+        "mata_pelajaran": req.MataPelajaran,
+        "kelas":          req.Kelas,
+        "pokok_materi":   req.PokokMateri,
+        "jam_mulai":      req.JamMulai,
+        "jam_selesai":    req.JamSelesai,
+        "status":         req.Status,
+        "catatan":        req.Catatan,
+    }
+    for column, value := range columns {
+        if value != nil {
+            updates[column] = *value
+        }
+    }
+    
+    if req.Status != nil && !lessonStatuses[*req.Status] {
+        return nil, fmt.Errorf("Status tidak valid")
+    }
+    
+    if req.TanggalMengajar != nil {
+        var tanggal time.Time
+        var err error
+        for _, layout := range []string{"2006-01-02", time.RFC3339} {
+            if tanggal, err = time.Parse(layout, *req.TanggalMengajar); err == nil {
+                break
+            }
+        }
+        if err != nil {
+            return nil, fmt.Errorf("Format tanggal tidak valid. Gunakan format YYYY-MM-DD")
+        }
+        updates["tanggal_mengajar"] = tanggal
+    }
+    return updates, nil
+}
+
 // createActivity untuk membuat aktivitas baru (huruf kecil untuk private function)
 func createActivity(performedBy, action, description string) error {
     activity := models.Activity{
@@ -39,6 +95,40 @@ func createActivity(performedBy, action, description string) error {
     return nil
 }
 
+// newLessonHistory menyiapkan entri history beserta perubahan per field dan snapshot
+// isi lesson setelah aksi. before bernilai nil untuk lesson yang baru dibuat.
+func newLessonHistory(lesson models.DailyLesson, before *models.LessonSnapshot, action, description string, userID uint) models.LessonReport {
+    snapshot := lesson.Snapshot()
+    previous := models.LessonSnapshot{}
+    if before != nil {
+        previous = *before
+    }
+    
+    return models.LessonReport{
+        LessonID:     lesson.ID,
+        Action:       action,
+        Description:  description,
+        PerformedBy:  userID,
+        Changes:      models.DiffSnapshots(previous, snapshot),
+        Snapshot:     &snapshot,
+    }
+}
+
+// recordLessonHistory menyimpan history lesson, kegagalan hanya dicatat ke log
+func recordLessonHistory(lesson models.DailyLesson, before *models.LessonSnapshot, action, description string, userID uint) {
+    // TP dimuat agar snapshot setelah aksi selalu lengkap dan bisa dipakai untuk revert
+    if lesson.TujuanPembelajaran == nil && lesson.ID != 0 {
+        tujuan := []models.TujuanPembelajaran{}
+        if err := database.DB.Model(&lesson).Association("TujuanPembelajaran").Find(&tujuan); err == nil {
+            lesson.TujuanPembelajaran = tujuan
+        }
+    }
+    history := newLessonHistory(lesson, before, action, description, userID)
+    if err := database.DB.Create(&history).Error; err != nil {
+        fmt.Printf("Failed to create lesson history: %v\n", err)
+    }
+}
+
 func CreateLesson(c *fiber.Ctx) error {
     var req CreateLessonRequest
     userID := c.Locals("userID").(uint)
@@ -72,6 +162,15 @@ func CreateLesson(c *fiber.Ctx) error {
         CreatedByID:    userID,
     }
     
+    if handled, err := handleDuplicateLesson(c, lesson); handled {
+        return err
+    }
+    
+    conflicts, ok, err := checkScheduleConflicts(c, lesson)
+    if !ok {
+        return err
+    }
+    
     if err := database.DB.Create(&lesson).Error; err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Could not create lesson record",
@@ -83,13 +182,7 @@ func CreateLesson(c *fiber.Ctx) error {
         action = "CREATE_ADMIN"
     }
     
-    history := models.LessonReport{
-        LessonID:     lesson.ID,
-        Action:       action,
-        Description:  "Catatan mengajar dibuat",
-        PerformedBy:  userID,
-    }
-    database.DB.Create(&history)
+    recordLessonHistory(lesson, nil, action, conflictHistoryNote("Catatan mengajar dibuat", conflicts), userID)
     
     // Catat aktivitas user
     activityDescription := fmt.Sprintf("Membuat lesson: %s - %s (%s)", req.MataPelajaran, req.Kelas, req.NamaGuru)
@@ -98,16 +191,37 @@ func CreateLesson(c *fiber.Ctx) error {
     return c.JSON(lesson)
 }
 
-func GetLessons(c *fiber.Ctx) error {
-    var lessons []models.DailyLesson
+// lessonOwnedBy bernilai true jika user adalah pemilik lesson atau guru pengganti yang mengisinya
+func lessonOwnedBy(lesson models.DailyLesson, userID uint) bool {
+    if lesson.CreatedByID == userID {
+        return true
+    }
+    return lesson.GuruPenggantiID != nil && *lesson.GuruPenggantiID == userID
+}
+
+// lessonSortColumns adalah kolom yang boleh dipakai pada parameter sort daftar lesson
+var lessonSortColumns = []string{
+    "id", "tanggal_mengajar", "jam_mulai", "nama_guru", "mata_pelajaran",
+    "kelas", "status", "created_at", "updated_at",
+}
+
+// filterLessons menerapkan pembatasan role dan filter query string pada daftar lesson
+func filterLessons(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
+    if q := c.Query("q"); q != "" {
+        query = query.Where("nama_guru LIKE ? OR mata_pelajaran LIKE ? OR kelas LIKE ?", "%"+q+"%", "%"+q+"%", "%"+q+"%")
+    }
+    
+    return filterLessonFields(c, query)
+}
+
+// filterLessonFields sama dengan filterLessons tanpa parameter q, untuk endpoint
+// yang memakai q sebagai kata kunci sendiri (pencarian full-text)
+func filterLessonFields(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
     userRole := c.Locals("role").(string)
     userID := c.Locals("userID").(uint)
-    userEmail := c.Locals("email").(string)
-    
-    query := database.DB.Preload("CreatedBy")
     
     if userRole == "teacher" {
-        query = query.Where("created_by_id = ?", userID)
+        query = query.Where("created_by_id = ? OR guru_pengganti_id = ?", userID, userID)
     }
     
     if tanggal := c.Query("tanggal"); tanggal != "" {
@@ -126,9 +240,29 @@ func GetLessons(c *fiber.Ctx) error {
         query = query.Where("kelas = ?", kelas)
     }
     
-    query = query.Order("tanggal_mengajar DESC, created_at DESC")
+    if verifikasi := c.Query("verifikasi"); verifikasi != "" {
+        query = query.Where("verifikasi_bukti = ?", verifikasi)
+    }
     
-    if err := query.Find(&lessons).Error; err != nil {
+    return query
+}
+
+func GetLessons(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    
+    pagination, err := parsePagination(c, lessonSortColumns, "tanggal_mengajar", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+    
+    query := filterLessons(c, database.DB.Model(&models.DailyLesson{}).Preload("CreatedBy"))
+    
+    lessons, meta, err := paginate(c, query, pagination, func(lesson models.DailyLesson) uint {
+        return lesson.ID
+    })
+    if err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Could not fetch lessons",
         })
@@ -141,7 +275,7 @@ func GetLessons(c *fiber.Ctx) error {
     }
     createActivity(userEmail, "view", activityDescription)
     
-    return c.JSON(lessons)
+    return c.JSON(paginatedResponse(lessons, meta))
 }
 
 func GetLesson(c *fiber.Ctx) error {
@@ -149,7 +283,7 @@ func GetLesson(c *fiber.Ctx) error {
     userEmail := c.Locals("email").(string)
     var lesson models.DailyLesson
     
-    if err := database.DB.Preload("CreatedBy").First(&lesson, id).Error; err != nil {
+    if err := database.DB.Preload("CreatedBy").Preload("TujuanPembelajaran").First(&lesson, id).Error; err != nil {
         return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
             "error": "Lesson record not found",
         })
@@ -168,44 +302,60 @@ func UpdateLesson(c *fiber.Ctx) error {
     userRole := c.Locals("role").(string)
     userEmail := c.Locals("email").(string)
     var lesson models.DailyLesson
-    var updateData map[string]interface{}
+    var req UpdateLessonRequest
     
-    if err := c.BodyParser(&updateData); err != nil {
+    if err := c.BodyParser(&req); err != nil {
         return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
             "error": "Cannot parse JSON",
         })
     }
     
+    updateData, err := req.updates()
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+    
     if err := database.DB.First(&lesson, id).Error; err != nil {
         return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
             "error": "Lesson record not found",
         })
     }
     
-    if userRole == "teacher" && lesson.CreatedByID != userID {
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
         return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
             "error": "Anda hanya dapat mengubah data sendiri",
         })
     }
     
+    conflicts, ok, err := checkScheduleConflicts(c, applyScheduleUpdate(lesson, updateData))
+    if !ok {
+        return err
+    }
+    
+    before := lesson.Snapshot()
+    
     if err := database.DB.Model(&lesson).Updates(updateData).Error; err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Could not update lesson record",
         })
     }
     
+    // Muat ulang agar diff dibandingkan dengan nilai yang benar-benar tersimpan
+    database.DB.First(&lesson, lesson.ID)
+    
     action := "UPDATE"
     if userRole == "admin" {
         action = "UPDATE_ADMIN"
     }
     
-    history := models.LessonReport{
-        LessonID:     lesson.ID,
-        Action:       action,
-        Description:  "Catatan mengajar diperbarui",
-        PerformedBy:  userID,
+    changes := models.DiffSnapshots(before, lesson.Snapshot())
+    description := "Catatan mengajar diperbarui"
+    if len(changes) > 0 {
+        description = fmt.Sprintf("Catatan mengajar diperbarui: %s", strings.Join(changes.Fields(), ", "))
     }
-    database.DB.Create(&history)
+    recordLessonHistory(lesson, &before, action, conflictHistoryNote(description, conflicts), userID)
     
     // Catat aktivitas user
     activityDescription := fmt.Sprintf("Memperbarui lesson: %s - %s (%s)", lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru)
@@ -227,7 +377,7 @@ func DeleteLesson(c *fiber.Ctx) error {
         })
     }
     
-    if userRole == "teacher" && lesson.CreatedByID != userID {
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
         return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
             "error": "Anda hanya dapat menghapus data sendiri",
         })
@@ -247,13 +397,8 @@ func DeleteLesson(c *fiber.Ctx) error {
         action = "DELETE_ADMIN"
     }
     
-    history := models.LessonReport{
-        LessonID:     lesson.ID,
-        Action:       action,
-        Description:  "Catatan mengajar dihapus",
-        PerformedBy:  userID,
-    }
-    database.DB.Create(&history)
+    before := lesson.Snapshot()
+    recordLessonHistory(lesson, &before, action, "Catatan mengajar dihapus", userID)
     
     // Catat aktivitas user
     activityDescription := fmt.Sprintf("Menghapus lesson: %s", lessonInfo)
@@ -267,12 +412,22 @@ func DeleteLesson(c *fiber.Ctx) error {
 func GetLessonHistory(c *fiber.Ctx) error {
     lessonID := c.Params("id")
     userEmail := c.Locals("email").(string)
-    var history []models.LessonReport
     
-    if err := database.DB.Where("lesson_id = ?", lessonID).
-        Preload("User").
-        Order("created_at DESC").
-        Find(&history).Error; err != nil {
+    pagination, err := parsePagination(c, []string{"id", "created_at", "action"}, "created_at", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+    
+    query := database.DB.Model(&models.LessonReport{}).
+        Where("lesson_id = ?", lessonID).
+        Preload("User")
+    
+    history, meta, err := paginate(c, query, pagination, func(entry models.LessonReport) uint {
+        return entry.ID
+    })
+    if err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Could not fetch lesson history",
         })
@@ -282,24 +437,206 @@ func GetLessonHistory(c *fiber.Ctx) error {
     activityDescription := fmt.Sprintf("Melihat history lesson ID: %s", lessonID)
     createActivity(userEmail, "view", activityDescription)
     
-    return c.JSON(history)
+    return c.JSON(paginatedResponse(history, meta))
 }
 
-func GetTeacherReport(c *fiber.Ctx) error {
+// RevertLesson mengembalikan isi lesson ke snapshot pada salah satu entri history
+func RevertLesson(c *fiber.Ctx) error {
+    id := c.Params("id")
+    historyID := c.Params("historyId")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+    var history models.LessonReport
+    
+    if err := database.DB.First(&lesson, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+    
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat mengubah data sendiri",
+        })
+    }
+    
+    if err := database.DB.Where("lesson_id = ?", lesson.ID).First(&history, historyID).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "History record not found",
+        })
+    }
+    
+    if history.Snapshot == nil {
+        return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+            "error": "History ini tidak memiliki snapshot sehingga tidak bisa dikembalikan",
+        })
+    }
+    
+    if err := database.DB.Model(&lesson).Association("TujuanPembelajaran").Find(&lesson.TujuanPembelajaran); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not revert lesson record",
+        })
+    }
+    
+    before := lesson.Snapshot()
+    lesson.ApplySnapshot(*history.Snapshot)
+    
+    // Topik program semester yang sudah dihapus tidak dipasangkan kembali
+    if lesson.PlannedTopicID != nil {
+        var count int64
+        database.DB.Model(&models.PlannedTopic{}).Where("id = ?", *lesson.PlannedTopicID).Count(&count)
+        if count == 0 {
+            lesson.PlannedTopicID = nil
+        }
+    }
+    
+    // TP yang sudah dihapus dari kurikulum dilewati
+    tujuan := lesson.TujuanPembelajaran
+    if ids := history.Snapshot.TujuanPembelajaranIDs; ids != nil {
+        tujuan = []models.TujuanPembelajaran{}
+        if len(ids) > 0 {
+            if err := database.DB.Where("id IN ?", ids).Find(&tujuan).Error; err != nil {
+                return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                    "error": "Could not revert lesson record",
+                })
+            }
+        }
+    }
+    
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if lesson.BuktiMengajar != before.BuktiMengajar {
+            if err := refreshEvidenceChecks(tx, &lesson); err != nil {
+                return err
+            }
+        }
+        if err := tx.Omit(clause.Associations).Save(&lesson).Error; err != nil {
+            return err
+        }
+        return tx.Model(&lesson).Association("TujuanPembelajaran").Replace(tujuan)
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not revert lesson record",
+        })
+    }
+    lesson.TujuanPembelajaran = tujuan
+    
+    description := fmt.Sprintf("Catatan mengajar dikembalikan ke versi history ID %d", history.ID)
+    recordLessonHistory(lesson, &before, "REVERT", description, userID)
+    
+    activityDescription := fmt.Sprintf("Mengembalikan lesson: %s - %s (%s) ke versi history ID %d", lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru, history.ID)
+    createActivity(userEmail, "update", activityDescription)
+    
+    return c.JSON(lesson)
+}
+
+// teacherReportQuery menyusun query laporan guru dari parameter guru, start_date dan end_date
+func teacherReportQuery(c *fiber.Ctx) *gorm.DB {
     guru := c.Query("guru")
     startDate := c.Query("start_date")
     endDate := c.Query("end_date")
-    userEmail := c.Locals("email").(string)
     
-    var lessons []models.DailyLesson
+    // Draft belum dikonfirmasi guru sehingga tidak masuk laporan. Lesson yang diajar
+    // guru pengganti masuk laporan guru asli maupun guru pengganti.
+    query := database.DB.Model(&models.DailyLesson{}).
+        Where("nama_guru LIKE ? OR guru_pengganti LIKE ?", "%"+guru+"%", "%"+guru+"%").
+        Where("status <> ?", models.StatusDraft)
     
-    query := database.DB.Where("nama_guru LIKE ?", "%"+guru+"%")
+    if startDate != "" && endDate != "" {
+        query = query.Where("date(tanggal_mengajar) BETWEEN ? AND ?", startDate, endDate)
+    }
     
+    return query
+}
+
+// teacherReportDescription menyusun deskripsi aktivitas untuk laporan guru
+func teacherReportDescription(c *fiber.Ctx, prefix string) string {
+    guru := c.Query("guru")
+    startDate := c.Query("start_date")
+    endDate := c.Query("end_date")
+    
+    description := prefix
+    if guru != "" {
+        description = fmt.Sprintf("%s: %s", prefix, guru)
+    }
     if startDate != "" && endDate != "" {
-        query = query.Where("tanggal_mengajar BETWEEN ? AND ?", startDate, endDate)
+        description += fmt.Sprintf(" dari %s sampai %s", startDate, endDate)
     }
+    return description
+}
+
+// TeacherReportSummary adalah rekap laporan guru. Lesson pada hari izin yang disetujui
+// tidak dihitung dalam Total dan dilaporkan terpisah pada Izin.
+type TeacherReportSummary struct {
+    Total           int `json:"total"`
+    Terlaksana      int `json:"terlaksana"`
+    TidakTerlaksana int `json:"tidak_terlaksana"`
+    Dibatalkan      int `json:"dibatalkan"`
+    Ditunda         int `json:"ditunda"`
+    Diganti         int `json:"diganti"`
+    Izin            int `json:"izin"`
+    HariIzin        int `json:"hari_izin"`
+}
+
+// reportTeacherIDs mengambil guru asli dari lesson laporan. Lesson yang diajar guru pengganti
+// dilewati agar izin guru asli tidak ikut terhitung pada laporan guru pengganti.
+func reportTeacherIDs(lessons []models.DailyLesson) []uint {
+    ids := []uint{}
+    seen := map[uint]bool{}
+    for _, lesson := range lessons {
+        if lesson.GuruPenggantiID != nil || seen[lesson.CreatedByID] {
+            continue
+        }
+        seen[lesson.CreatedByID] = true
+        ids = append(ids, lesson.CreatedByID)
+    }
+    return ids
+}
+
+// summarizeTeacherReport menghitung rekap laporan guru beserta hari izin yang disetujui
+func summarizeTeacherReport(lessons []models.DailyLesson, startDate, endDate string) (TeacherReportSummary, error) {
+    var summary TeacherReportSummary
+
+    leaveDays, err := approvedLeaveDays(reportTeacherIDs(lessons), startDate, endDate)
+    if err != nil {
+        return summary, err
+    }
+
+    for _, days := range leaveDays {
+        summary.HariIzin += len(days)
+    }
+    for _, lesson := range lessons {
+        if onApprovedLeave(lesson, leaveDays) {
+            summary.Izin++
+            continue
+        }
+        
+        summary.Total++
+        if lesson.GuruPenggantiID != nil {
+            summary.Diganti++
+        }
+        switch lesson.Status {
+        case models.StatusTerlaksana:
+            summary.Terlaksana++
+        case models.StatusTidakTerlaksana:
+            summary.TidakTerlaksana++
+        case models.StatusDibatalkan:
+            summary.Dibatalkan++
+        case models.StatusDitunda:
+            summary.Ditunda++
+        }
+    }
+    return summary, nil
+}
+
+func GetTeacherReport(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    
+    var lessons []models.DailyLesson
     
-    query = query.Order("tanggal_mengajar ASC")
+    query := teacherReportQuery(c).Order("tanggal_mengajar ASC")
     
     if err := query.Find(&lessons).Error; err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -308,14 +645,30 @@ func GetTeacherReport(c *fiber.Ctx) error {
     }
     
     // Catat aktivitas user melihat laporan guru
-    activityDescription := "Melihat laporan guru"
-    if guru != "" {
-        activityDescription = fmt.Sprintf("Melihat laporan guru: %s", guru)
+    createActivity(userEmail, "view_report", teacherReportDescription(c, "Melihat laporan guru"))
+    
+    return c.JSON(lessons)
+}
+
+// GetTeacherReportSummary mengembalikan rekap laporan guru dengan filter yang sama seperti GetTeacherReport
+func GetTeacherReportSummary(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    
+    var lessons []models.DailyLesson
+    if err := teacherReportQuery(c).Find(&lessons).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch teacher report",
+        })
     }
-    if startDate != "" && endDate != "" {
-        activityDescription += fmt.Sprintf(" dari %s sampai %s", startDate, endDate)
+    
+    summary, err := summarizeTeacherReport(lessons, c.Query("start_date"), c.Query("end_date"))
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch teacher report",
+        })
     }
-    createActivity(userEmail, "view_report", activityDescription)
     
-    return c.JSON(lessons)
-}
\ No newline at end of file
+    createActivity(userEmail, "view_report", teacherReportDescription(c, "Melihat rekap laporan guru"))
+    
+    return c.JSON(summary)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http/httptest"
+    "reflect"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestLessonHistorySnapshotRoundTrip(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    lesson := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        PokokMateri: "Pecahan", JamMulai: "07:00", JamSelesai: "08:30", Catatan: "Lancar",
+    })
+    recordLessonHistory(lesson, nil, "CREATE", "Lesson dibuat", guru.ID)
+
+    before := lesson.Snapshot()
+    planID := uint(7)
+    lesson.PokokMateri = "Desimal"
+    lesson.TanggalMengajar = testDate("2026-10-06")
+    lesson.Status = models.StatusTidakTerlaksana
+    lesson.AlasanTidakTerlaksana = "Rapat guru"
+    lesson.LessonPlanID = &planID
+    if err := database.DB.Save(&lesson).Error; err != nil {
+        t.Fatalf("save lesson: %v", err)
+    }
+    recordLessonHistory(lesson, &before, "UPDATE", "Lesson diubah", guru.ID)
+
+    var histories []models.LessonReport
+    if err := database.DB.Where("lesson_id = ?", lesson.ID).Order("id").Find(&histories).Error; err != nil {
+        t.Fatalf("load history: %v", err)
+    }
+    if len(histories) != 2 {
+        t.Fatalf("history = %d rows, want 2", len(histories))
+    }
+
+    // Diff hanya berisi field yang benar-benar berubah, dengan nilai lama dan baru
+    update := histories[1]
+    wantFields := []string{"pokok_materi", "tanggal_mengajar", "status", "alasan_tidak_terlaksana", "lesson_plan_id"}
+    if fields := update.Changes.Fields(); !reflect.DeepEqual(fields, wantFields) {
+        t.Fatalf("changed fields = %v, want %v", fields, wantFields)
+    }
+    if change := update.Changes[0]; change.OldValue != "Pecahan" || change.NewValue != "Desimal" {
+        t.Fatalf("pokok_materi change = %+v", change)
+    }
+
+    // Snapshot yang dibaca ulang dari database mengembalikan lesson ke isi semula
+    var current models.DailyLesson
+    if err := database.DB.First(&current, lesson.ID).Error; err != nil {
+        t.Fatalf("load lesson: %v", err)
+    }
+    current.ApplySnapshot(*histories[0].Snapshot)
+    if changes := models.DiffSnapshots(*histories[0].Snapshot, current.Snapshot()); len(changes) != 0 {
+        t.Fatalf("changes after apply = %+v, want none", changes)
+    }
+    if changes := models.DiffSnapshots(before, current.Snapshot()); len(changes) != 0 {
+        t.Fatalf("changes against original = %+v, want none", changes)
+    }
+}
+
+func TestDiffSnapshotsSkipsUnknownObjectives(t *testing.T) {
+    before := models.LessonSnapshot{TujuanPembelajaranIDs: []uint{1, 2}}
+
+    if changes := models.DiffSnapshots(before, models.LessonSnapshot{}); len(changes) != 0 {
+        t.Fatalf("changes = %+v, want none when TP is not loaded", changes)
+    }
+
+    changes := models.DiffSnapshots(before, models.LessonSnapshot{TujuanPembelajaranIDs: []uint{}})
+    if fields := changes.Fields(); !reflect.DeepEqual(fields, []string{"tujuan_pembelajaran_ids"}) {
+        t.Fatalf("changed fields = %v, want tujuan_pembelajaran_ids", fields)
+    }
+}
+
+func revertTestLesson(t *testing.T, userID, lessonID, historyID uint) (int, models.DailyLesson) {
+    t.Helper()
+
+    app := fiber.New()
+    app.Post("/lessons/:id/history/:historyId/revert", func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleTeacher))
+        c.Locals("email", "guru@sekolah.test")
+        return RevertLesson(c)
+    })
+
+    resp, err := app.Test(httptest.NewRequest("POST", fmt.Sprintf("/lessons/%d/history/%d/revert", lessonID, historyID), nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    var lesson models.DailyLesson
+    if resp.StatusCode == fiber.StatusOK {
+        if err := json.NewDecoder(resp.Body).Decode(&lesson); err != nil {
+            t.Fatalf("decode: %v", err)
+        }
+    }
+    return resp.StatusCode, lesson
+}
+
+func TestRevertLessonKeepsSubstitution(t *testing.T) {
+    setupTestDB(t)
+    guruA := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    guruB := createTestUser(t, "Pak Budi", models.RoleTeacher)
+    guruC := createTestUser(t, "Bu Citra", models.RoleTeacher)
+
+    lesson := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guruA.ID,
+        PokokMateri: "Pecahan",
+    })
+    recordLessonHistory(lesson, nil, "CREATE", "Lesson dibuat", guruA.ID)
+
+    // Setelah snapshot CREATE, lesson diisi guru pengganti lewat alur penugasan
+    substitution := models.Substitution{
+        OriginalTeacherID: guruA.ID, SubstituteTeacherID: &guruB.ID, Tanggal: lesson.TanggalMengajar, Kelas: "7A",
+        Status: models.SubstitutionDone, LessonID: &lesson.ID, CreatedByID: guruA.ID,
+    }
+    if err := database.DB.Create(&substitution).Error; err != nil {
+        t.Fatalf("create substitution: %v", err)
+    }
+    before := lesson.Snapshot()
+    lesson.PokokMateri = "Desimal"
+    lesson.GuruPengganti = "Pak Budi"
+    lesson.GuruPenggantiID = &guruB.ID
+    lesson.SubstitutionID = &substitution.ID
+    database.DB.Save(&lesson)
+    recordLessonHistory(lesson, &before, "UPDATE", "Lesson diisi guru pengganti", guruB.ID)
+
+    var created models.LessonReport
+    database.DB.Where("lesson_id = ? AND action = ?", lesson.ID, "CREATE").First(&created)
+
+    t.Run("other teacher is forbidden", func(t *testing.T) {
+        if status, _ := revertTestLesson(t, guruC.ID, lesson.ID, created.ID); status != fiber.StatusForbidden {
+            t.Fatalf("status = %d, want 403", status)
+        }
+    })
+
+    t.Run("history of another lesson", func(t *testing.T) {
+        other := createTestLesson(t, models.DailyLesson{
+            NamaGuru: "Bu Ani", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guruA.ID,
+        })
+        if status, _ := revertTestLesson(t, guruA.ID, other.ID, created.ID); status != fiber.StatusNotFound {
+            t.Fatalf("status = %d, want 404", status)
+        }
+    })
+
+    t.Run("owner reverts content only", func(t *testing.T) {
+        status, reverted := revertTestLesson(t, guruA.ID, lesson.ID, created.ID)
+        if status != fiber.StatusOK {
+            t.Fatalf("status = %d, want 200", status)
+        }
+        if reverted.PokokMateri != "Pecahan" {
+            t.Errorf("pokok_materi = %q, want Pecahan", reverted.PokokMateri)
+        }
+
+        var current models.DailyLesson
+        database.DB.First(&current, lesson.ID)
+        if current.GuruPengganti != "Pak Budi" || current.GuruPenggantiID == nil || *current.GuruPenggantiID != guruB.ID ||
+            current.SubstitutionID == nil || *current.SubstitutionID != substitution.ID {
+            t.Errorf("substitute = %q/%v/%v, want kept after revert", current.GuruPengganti, current.GuruPenggantiID, current.SubstitutionID)
+        }
+
+        var history models.LessonReport
+        if err := database.DB.Where("lesson_id = ? AND action = ?", lesson.ID, "REVERT").First(&history).Error; err != nil {
+            t.Fatalf("revert history not recorded: %v", err)
+        }
+        if fields := history.Changes.Fields(); !reflect.DeepEqual(fields, []string{"pokok_materi"}) {
+            t.Errorf("revert changes = %v, want [pokok_materi]", fields)
+        }
+    })
+}
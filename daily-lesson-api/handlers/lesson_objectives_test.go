@@ -0,0 +1,83 @@
+package handlers
+
+import (
+    "bytes"
+    "fmt"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func lessonObjectivesApp(userID uint) *fiber.App {
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleAdmin))
+        c.Locals("email", "admin@sekolah.test")
+        return c.Next()
+    })
+    app.Put("/lessons/:id/objectives", SetLessonObjectives)
+    app.Post("/lessons/:id/revert/:historyId", RevertLesson)
+    return app
+}
+
+func sendJSON(t *testing.T, app *fiber.App, method, path, body string) {
+    t.Helper()
+
+    req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("%s %s status = %d, want 200", method, path, resp.StatusCode)
+    }
+}
+
+func TestLessonObjectivesAreRecordedAndReverted(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "Admin", models.RoleAdmin)
+    lesson := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: admin.ID})
+
+    capaian := models.CapaianPembelajaran{MataPelajaran: "Matematika", Fase: "D", Elemen: "Bilangan"}
+    if err := database.DB.Create(&capaian).Error; err != nil {
+        t.Fatalf("create capaian: %v", err)
+    }
+    tp1 := models.TujuanPembelajaran{CapaianID: capaian.ID, Kode: "TP1"}
+    tp2 := models.TujuanPembelajaran{CapaianID: capaian.ID, Kode: "TP2"}
+    database.DB.Create(&tp1)
+    database.DB.Create(&tp2)
+
+    app := lessonObjectivesApp(admin.ID)
+    path := fmt.Sprintf("/lessons/%d/objectives", lesson.ID)
+    sendJSON(t, app, "PUT", path, `{"tujuan_pembelajaran_ids":[]}`)
+    sendJSON(t, app, "PUT", path, fmt.Sprintf(`{"tujuan_pembelajaran_ids":[%d]}`, tp1.ID))
+    sendJSON(t, app, "PUT", path, fmt.Sprintf(`{"tujuan_pembelajaran_ids":[%d,%d]}`, tp2.ID, tp1.ID))
+    // Daftar yang sama tidak menambah history
+    sendJSON(t, app, "PUT", path, fmt.Sprintf(`{"tujuan_pembelajaran_ids":[%d,%d]}`, tp1.ID, tp2.ID))
+
+    var histories []models.LessonReport
+    database.DB.Where("lesson_id = ? AND action = ?", lesson.ID, "UPDATE_OBJECTIVES").Order("id ASC").Find(&histories)
+    if len(histories) != 2 {
+        t.Fatalf("got %d UPDATE_OBJECTIVES histories, want 2", len(histories))
+    }
+    changes := histories[1].Changes
+    if len(changes) != 1 || changes[0].Field != "tujuan_pembelajaran_ids" {
+        t.Fatalf("changes = %+v, want only tujuan_pembelajaran_ids", changes)
+    }
+    if fmt.Sprint(changes[0].OldValue) != fmt.Sprint([]uint{tp1.ID}) {
+        t.Errorf("old TP = %v, want [%d]", changes[0].OldValue, tp1.ID)
+    }
+
+    sendJSON(t, app, "POST", fmt.Sprintf("/lessons/%d/revert/%d", lesson.ID, histories[0].ID), "")
+
+    var tujuan []models.TujuanPembelajaran
+    database.DB.Model(&lesson).Association("TujuanPembelajaran").Find(&tujuan)
+    if len(tujuan) != 1 || tujuan[0].ID != tp1.ID {
+        t.Errorf("TP after revert = %v, want only TP %d", tujuan, tp1.ID)
+    }
+}
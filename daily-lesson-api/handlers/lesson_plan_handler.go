@@ -0,0 +1,475 @@
+package handlers
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+    "daily-lesson-api/utils"
+)
+
+var allowedPlanExtensions = map[string]bool{
+    ".pdf":  true,
+    ".doc":  true,
+    ".docx": true,
+    ".odt":  true,
+    ".ppt":  true,
+    ".pptx": true,
+}
+
+type LessonPlanRequest struct {
+    Judul         string `json:"judul"`
+    MataPelajaran string `json:"mata_pelajaran"`
+    Kelas         string `json:"kelas"`
+    Deskripsi     string `json:"deskripsi"`
+    Dibagikan     *bool  `json:"dibagikan"`
+}
+
+type LessonPlanLinkRequest struct {
+    LessonPlanID *uint `json:"lesson_plan_id"`
+}
+
+// visibleLessonPlans membatasi modul ajar yang boleh dilihat user: guru melihat miliknya
+// dan modul yang dibagikan di departemennya, admin dan supervisor melihat semuanya
+func visibleLessonPlans(c *fiber.Ctx, query *gorm.DB) (*gorm.DB, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    if userRole != "teacher" {
+        return query, nil
+    }
+
+    var user models.User
+    if err := database.DB.First(&user, userID).Error; err != nil {
+        return nil, err
+    }
+    if user.Departemen == "" {
+        return query.Where("lesson_plans.owner_id = ?", userID), nil
+    }
+    return query.Where("lesson_plans.owner_id = ? OR (lesson_plans.dibagikan = ? AND lesson_plans.departemen = ?)",
+        userID, true, user.Departemen), nil
+}
+
+// loadLessonPlan mengambil modul ajar yang boleh dilihat user; dengan owned=true guru harus pemiliknya.
+// Jika gagal, response sudah dikirim dan ok bernilai false.
+func loadLessonPlan(c *fiber.Ctx, id string, owned bool) (models.LessonPlan, bool, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var plan models.LessonPlan
+
+    query, err := visibleLessonPlans(c, database.DB.Preload("Owner", ownerSummaryColumns))
+    if err != nil {
+        return plan, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lesson plan",
+        })
+    }
+    if err := query.First(&plan, id).Error; err != nil {
+        return plan, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson plan not found",
+        })
+    }
+
+    if owned && userRole == "teacher" && plan.OwnerID != userID {
+        return plan, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat mengubah modul ajar sendiri",
+        })
+    }
+
+    return plan, true, nil
+}
+
+// saveLessonPlanDocument menyimpan file unggahan sebagai versi baru modul ajar
+func saveLessonPlanDocument(c *fiber.Ctx, tx *gorm.DB, plan *models.LessonPlan, userID uint) (models.LessonPlanDocument, error) {
+    var document models.LessonPlanDocument
+
+    file, err := c.FormFile("file")
+    if err != nil {
+        return document, fiber.NewError(fiber.StatusBadRequest, "File modul ajar wajib diunggah (field: file)")
+    }
+
+    ext := strings.ToLower(filepath.Ext(file.Filename))
+    if !allowedPlanExtensions[ext] {
+        return document, fiber.NewError(fiber.StatusBadRequest, "Format file tidak didukung. Gunakan PDF, DOC/DOCX, ODT atau PPT/PPTX")
+    }
+
+    dir, err := utils.UploadDir("lesson-plans")
+    if err != nil {
+        return document, err
+    }
+
+    plan.VersiTerakhir++
+    path := filepath.Join(dir, fmt.Sprintf("%d_v%d_%d%s", plan.ID, plan.VersiTerakhir, time.Now().UnixNano(), ext))
+    if err := c.SaveFile(file, path); err != nil {
+        return document, err
+    }
+
+    document = models.LessonPlanDocument{
+        LessonPlanID: plan.ID,
+        Versi:        plan.VersiTerakhir,
+        NamaFile:     filepath.Base(file.Filename),
+        Path:         path,
+        Ukuran:       file.Size,
+        Catatan:      c.FormValue("catatan"),
+        UploadedByID: userID,
+    }
+    if err := tx.Create(&document).Error; err != nil {
+        os.Remove(path)
+        return document, err
+    }
+    if err := tx.Model(plan).Update("versi_terakhir", plan.VersiTerakhir).Error; err != nil {
+        os.Remove(path)
+        return document, err
+    }
+    return document, nil
+}
+
+// lessonPlanUploadError mengubah error dari saveLessonPlanDocument menjadi response
+func lessonPlanUploadError(c *fiber.Ctx, err error) error {
+    if fiberErr, ok := err.(*fiber.Error); ok {
+        return c.Status(fiberErr.Code).JSON(fiber.Map{
+            "error": fiberErr.Message,
+        })
+    }
+    return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+        "error": "Could not save lesson plan document",
+    })
+}
+
+// GetLessonPlans menampilkan pustaka modul ajar yang dapat dilihat user.
+// scope=mine hanya modul milik sendiri, scope=shared hanya modul guru lain.
+func GetLessonPlans(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+
+    pagination, err := parsePagination(c, []string{"id", "judul", "mata_pelajaran", "updated_at"}, "updated_at", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query, err := visibleLessonPlans(c, database.DB.Model(&models.LessonPlan{}).Preload("Owner", ownerSummaryColumns))
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lesson plans",
+        })
+    }
+
+    switch c.Query("scope") {
+    case "mine":
+        query = query.Where("lesson_plans.owner_id = ?", userID)
+    case "shared":
+        query = query.Where("lesson_plans.owner_id <> ?", userID)
+    }
+    if mapel := c.Query("mata_pelajaran"); mapel != "" {
+        query = query.Where("lower(mata_pelajaran) = lower(?)", mapel)
+    }
+    if q := c.Query("q"); q != "" {
+        query = query.Where("judul LIKE ? OR deskripsi LIKE ?", "%"+q+"%", "%"+q+"%")
+    }
+
+    plans, meta, err := paginate(c, query, pagination, func(plan models.LessonPlan) uint {
+        return plan.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lesson plans",
+        })
+    }
+
+    return c.JSON(paginatedResponse(plans, meta))
+}
+
+// GetLessonPlan menampilkan satu modul ajar beserta seluruh versi dokumennya
+func GetLessonPlan(c *fiber.Ctx) error {
+    plan, ok, err := loadLessonPlan(c, c.Params("id"), false)
+    if !ok {
+        return err
+    }
+
+    if err := database.DB.Where("lesson_plan_id = ?", plan.ID).Order("versi DESC").Find(&plan.Documents).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lesson plan documents",
+        })
+    }
+
+    var usage int64
+    database.DB.Model(&models.DailyLesson{}).Where("lesson_plan_id = ?", plan.ID).Count(&usage)
+
+    return c.JSON(fiber.Map{
+        "lesson_plan":    plan,
+        "jumlah_dipakai": usage,
+    })
+}
+
+// CreateLessonPlan membuat modul ajar baru (multipart: judul, mata_pelajaran, kelas,
+// deskripsi, dibagikan, file) dengan dokumen pertama sebagai versi 1
+func CreateLessonPlan(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var owner models.User
+
+    if err := database.DB.First(&owner, userID).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "User not found",
+        })
+    }
+
+    plan := models.LessonPlan{
+        Judul:         strings.TrimSpace(c.FormValue("judul")),
+        MataPelajaran: strings.TrimSpace(c.FormValue("mata_pelajaran")),
+        Kelas:         strings.TrimSpace(c.FormValue("kelas")),
+        Deskripsi:     strings.TrimSpace(c.FormValue("deskripsi")),
+        Dibagikan:     c.FormValue("dibagikan") == "true",
+        OwnerID:       owner.ID,
+        Departemen:    owner.Departemen,
+    }
+    if plan.Judul == "" || plan.MataPelajaran == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "judul dan mata_pelajaran wajib diisi",
+        })
+    }
+
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&plan).Error; err != nil {
+            return err
+        }
+        document, err := saveLessonPlanDocument(c, tx, &plan, userID)
+        if err != nil {
+            return err
+        }
+        plan.Documents = []models.LessonPlanDocument{document}
+        return nil
+    })
+    if err != nil {
+        return lessonPlanUploadError(c, err)
+    }
+
+    createActivity(userEmail, "create", fmt.Sprintf("Membuat modul ajar: %s (%s)", plan.Judul, plan.MataPelajaran))
+
+    plan.Owner = owner
+    return c.Status(fiber.StatusCreated).JSON(plan)
+}
+
+// UpdateLessonPlan mengubah judul, mapel, kelas, deskripsi atau status berbagi modul ajar
+func UpdateLessonPlan(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var req LessonPlanRequest
+
+    plan, ok, err := loadLessonPlan(c, c.Params("id"), true)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    plan.Judul = strings.TrimSpace(valueOr(req.Judul, plan.Judul))
+    plan.MataPelajaran = strings.TrimSpace(valueOr(req.MataPelajaran, plan.MataPelajaran))
+    plan.Kelas = strings.TrimSpace(valueOr(req.Kelas, plan.Kelas))
+    plan.Deskripsi = strings.TrimSpace(valueOr(req.Deskripsi, plan.Deskripsi))
+    if req.Dibagikan != nil {
+        plan.Dibagikan = *req.Dibagikan
+    }
+
+    if err := database.DB.Omit("Owner").Save(&plan).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update lesson plan",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Memperbarui modul ajar: %s", plan.Judul))
+
+    return c.JSON(plan)
+}
+
+// DeleteLessonPlan menghapus modul ajar; lesson yang memakainya tetap menyimpan tautannya
+func DeleteLessonPlan(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    plan, ok, err := loadLessonPlan(c, c.Params("id"), true)
+    if !ok {
+        return err
+    }
+
+    if err := database.DB.Delete(&plan).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete lesson plan",
+        })
+    }
+
+    createActivity(userEmail, "delete", fmt.Sprintf("Menghapus modul ajar: %s", plan.Judul))
+
+    return c.JSON(fiber.Map{
+        "message": "Lesson plan deleted successfully",
+    })
+}
+
+// UploadLessonPlanDocument menambahkan versi dokumen baru pada modul ajar
+func UploadLessonPlanDocument(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var document models.LessonPlanDocument
+
+    plan, ok, err := loadLessonPlan(c, c.Params("id"), true)
+    if !ok {
+        return err
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        var err error
+        document, err = saveLessonPlanDocument(c, tx, &plan, userID)
+        return err
+    })
+    if err != nil {
+        return lessonPlanUploadError(c, err)
+    }
+
+    createActivity(userEmail, "upload", fmt.Sprintf("Mengunggah versi %d modul ajar: %s", document.Versi, plan.Judul))
+
+    return c.Status(fiber.StatusCreated).JSON(document)
+}
+
+// GetLessonPlanDocument mengirim file modul ajar; versi "latest" berarti versi terakhir
+func GetLessonPlanDocument(c *fiber.Ctx) error {
+    var document models.LessonPlanDocument
+
+    plan, ok, err := loadLessonPlan(c, c.Params("id"), false)
+    if !ok {
+        return err
+    }
+
+    versi := c.Params("version")
+    if versi == "latest" {
+        versi = fmt.Sprint(plan.VersiTerakhir)
+    }
+    if err := database.DB.Where("lesson_plan_id = ? AND versi = ?", plan.ID, versi).First(&document).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Versi dokumen tidak ditemukan",
+        })
+    }
+    if _, err := os.Stat(document.Path); err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "File modul ajar tidak ditemukan",
+        })
+    }
+
+    return c.Download(document.Path, document.NamaFile)
+}
+
+// SetLessonPlan memasangkan modul ajar yang dipakai pada lesson, atau melepasnya dengan null.
+// Modul ajar yang dibagikan di departemen guru boleh dipakai.
+func SetLessonPlan(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+    var req LessonPlanLinkRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&lesson, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat mengubah data sendiri",
+        })
+    }
+
+    description := "Melepas modul ajar"
+    if req.LessonPlanID != nil {
+        plan, ok, err := loadLessonPlan(c, fmt.Sprint(*req.LessonPlanID), false)
+        if !ok {
+            return err
+        }
+        description = fmt.Sprintf("Memakai modul ajar %s", plan.Judul)
+    }
+
+    before := lesson.Snapshot()
+    if err := database.DB.Model(&lesson).Update("lesson_plan_id", req.LessonPlanID).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update lesson record",
+        })
+    }
+    lesson.LessonPlanID = req.LessonPlanID
+    recordLessonHistory(lesson, &before, "SET_LESSON_PLAN", description, userID)
+
+    activityDescription := fmt.Sprintf("%s pada lesson: %s - %s (%s)", description, lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru)
+    createActivity(userEmail, "update", activityDescription)
+
+    return c.JSON(lesson)
+}
+
+// GetLessonsWithoutPlan menampilkan lesson terlaksana yang tidak memakai modul ajar,
+// beserta jumlahnya per guru, untuk supervisor
+func GetLessonsWithoutPlan(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    startDate, endDate, err := complianceRange(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    pagination, err := parsePagination(c, lessonSortColumns, "tanggal_mengajar", 50)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    withoutPlan := func() *gorm.DB {
+        query := database.DB.Model(&models.DailyLesson{}).
+            Where("lesson_plan_id IS NULL AND status = ? AND date(tanggal_mengajar) BETWEEN ? AND ?",
+                models.StatusTerlaksana, startDate, endDate)
+        if teacherID := c.QueryInt("teacher_id"); teacherID != 0 {
+            query = query.Where("created_by_id = ?", teacherID)
+        }
+        return query
+    }
+
+    var perTeacher []struct {
+        TeacherID uint   `json:"teacher_id"`
+        NamaGuru  string `json:"nama_guru"`
+        Total     int64  `json:"total"`
+    }
+    if err := withoutPlan().Select("created_by_id AS teacher_id, min(nama_guru) AS nama_guru, count(*) AS total").
+        Group("created_by_id").Order("total DESC").Scan(&perTeacher).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lessons without plan",
+        })
+    }
+
+    lessons, meta, err := paginate(c, withoutPlan(), pagination, func(lesson models.DailyLesson) uint {
+        return lesson.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch lessons without plan",
+        })
+    }
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat lesson tanpa modul ajar dari %s sampai %s", startDate, endDate))
+
+    response := paginatedResponse(lessons, meta)
+    response["start_date"] = startDate
+    response["end_date"] = endDate
+    response["per_guru"] = perTeacher
+    return c.JSON(response)
+}
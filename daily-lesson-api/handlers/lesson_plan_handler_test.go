@@ -0,0 +1,69 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestLessonPlanOwnerAndLessonLinkHistory(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "Guru A", models.RoleTeacher)
+    database.DB.Model(&guru).Update("departemen", "MIPA")
+    plan := models.LessonPlan{Judul: "Modul Pecahan", MataPelajaran: "Matematika", OwnerID: guru.ID, Departemen: "MIPA"}
+    if err := database.DB.Create(&plan).Error; err != nil {
+        t.Fatalf("create plan: %v", err)
+    }
+    lesson := createTestLesson(t, models.DailyLesson{NamaGuru: "Guru A", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID})
+
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", guru.ID)
+        c.Locals("role", string(models.RoleTeacher))
+        c.Locals("email", guru.Email)
+        return c.Next()
+    })
+    app.Get("/lesson-plans/:id", GetLessonPlan)
+    app.Put("/lessons/:id/lesson-plan", SetLessonPlan)
+
+    resp, err := app.Test(httptest.NewRequest("GET", fmt.Sprintf("/lesson-plans/%d", plan.ID), nil))
+    if err != nil || resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("get lesson plan: status %v, err %v", resp.StatusCode, err)
+    }
+    var body struct {
+        LessonPlan struct {
+            Owner map[string]interface{} `json:"owner"`
+        } `json:"lesson_plan"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    owner := body.LessonPlan.Owner
+    if owner["name"] != "Guru A" || owner["departemen"] != "MIPA" {
+        t.Errorf("owner = %v, want name and departemen", owner)
+    }
+    if owner["email"] != "" || owner["password"] != nil {
+        t.Errorf("owner exposes account data: %v", owner)
+    }
+
+    req := httptest.NewRequest("PUT", fmt.Sprintf("/lessons/%d/lesson-plan", lesson.ID),
+        bytes.NewBufferString(fmt.Sprintf(`{"lesson_plan_id":%d}`, plan.ID)))
+    req.Header.Set("Content-Type", "application/json")
+    if resp, err := app.Test(req); err != nil || resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("set lesson plan: status %v, err %v", resp.StatusCode, err)
+    }
+
+    var history models.LessonReport
+    if err := database.DB.Where("lesson_id = ? AND action = ?", lesson.ID, "SET_LESSON_PLAN").First(&history).Error; err != nil {
+        t.Fatalf("history not recorded: %v", err)
+    }
+    if history.Snapshot == nil || history.Snapshot.LessonPlanID == nil || *history.Snapshot.LessonPlanID != plan.ID {
+        t.Errorf("snapshot lesson_plan_id = %v, want %d", history.Snapshot, plan.ID)
+    }
+}
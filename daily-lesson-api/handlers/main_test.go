@@ -0,0 +1,76 @@
+package handlers
+
+import (
+    "testing"
+    "time"
+
+    "github.com/glebarez/sqlite"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// setupTestDB mengganti database.DB dengan SQLite in-memory yang sudah dimigrasi
+func setupTestDB(t *testing.T) {
+    t.Helper()
+
+    db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+        DisableForeignKeyConstraintWhenMigrating: true,
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatalf("open database: %v", err)
+    }
+
+    // Setiap koneksi in-memory adalah database terpisah, jadi pool dibatasi satu koneksi
+    sqlDB, err := db.DB()
+    if err != nil {
+        t.Fatalf("database handle: %v", err)
+    }
+    sqlDB.SetMaxOpenConns(1)
+
+    if err := database.Migrate(db); err != nil {
+        t.Fatalf("migrate: %v", err)
+    }
+
+    previous := database.DB
+    database.DB = db
+    t.Cleanup(func() {
+        database.DB = previous
+        sqlDB.Close()
+    })
+}
+
+func createTestUser(t *testing.T, name string, role models.UserRole) models.User {
+    t.Helper()
+
+    user := models.User{Name: name, Email: name + "@sekolah.test", Password: "x", Role: role}
+    if err := database.DB.Create(&user).Error; err != nil {
+        t.Fatalf("create user: %v", err)
+    }
+    return user
+}
+
+func createTestLesson(t *testing.T, lesson models.DailyLesson) models.DailyLesson {
+    t.Helper()
+
+    if lesson.Status == "" {
+        lesson.Status = models.StatusTerlaksana
+    }
+    if lesson.MataPelajaran == "" {
+        lesson.MataPelajaran = "Matematika"
+    }
+    if err := database.DB.Create(&lesson).Error; err != nil {
+        t.Fatalf("create lesson: %v", err)
+    }
+    return lesson
+}
+
+func testDate(value string) time.Time {
+    date, err := time.Parse("2006-01-02", value)
+    if err != nil {
+        panic(err)
+    }
+    return date
+}
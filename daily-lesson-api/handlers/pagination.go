@@ -0,0 +1,218 @@
+package handlers
+
+import (
+    "encoding/base64"
+    "fmt"
+    "net/url"
+    "strconv"
+    "strings"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+)
+
+const maxPageLimit = 100
+
+// Pagination berisi parameter halaman yang sudah divalidasi dari query string.
+// Mode cursor aktif jika parameter "cursor" dikirim (boleh kosong untuk halaman pertama);
+// pada mode ini data selalu diurutkan berdasarkan id agar cursor stabil.
+type Pagination struct {
+    Page      int
+    Limit     int
+    Sort      string
+    Order     string
+    UseCursor bool
+    Cursor    uint
+}
+
+// PaginationMeta dikirim bersama data pada response envelope
+type PaginationMeta struct {
+    Page       int    `json:"page,omitempty"`
+    Limit      int    `json:"limit"`
+    Total      int64  `json:"total"`
+    TotalPages int    `json:"total_pages,omitempty"`
+    Sort       string `json:"sort"`
+    Order      string `json:"order"`
+    NextCursor string `json:"next_cursor,omitempty"`
+    HasMore    bool   `json:"has_more"`
+}
+
+// parsePagination membaca page, limit, sort, order dan cursor. Kolom sort hanya boleh
+// berasal dari daftar sortable agar tidak bisa disisipi SQL.
+func parsePagination(c *fiber.Ctx, sortable []string, defaultSort string, defaultLimit int) (Pagination, error) {
+    p := Pagination{Page: 1, Limit: defaultLimit, Sort: defaultSort, Order: "desc"}
+
+    if page := c.Query("page"); page != "" {
+        value, err := strconv.Atoi(page)
+        if err != nil || value < 1 {
+            return p, fmt.Errorf("parameter page harus berupa angka >= 1")
+        }
+        p.Page = value
+    }
+
+    if limit := c.Query("limit"); limit != "" {
+        value, err := strconv.Atoi(limit)
+        if err != nil || value < 1 {
+            return p, fmt.Errorf("parameter limit harus berupa angka >= 1")
+        }
+        p.Limit = value
+    }
+    if p.Limit > maxPageLimit {
+        p.Limit = maxPageLimit
+    }
+
+    if sort := c.Query("sort"); sort != "" {
+        if strings.HasPrefix(sort, "-") {
+            sort = sort[1:]
+            p.Order = "desc"
+        } else if c.Query("order") == "" {
+            p.Order = "asc"
+        }
+
+        allowed := false
+        for _, column := range sortable {
+            if column == sort {
+                allowed = true
+                break
+            }
+        }
+        if !allowed {
+            return p, fmt.Errorf("sort hanya boleh salah satu dari: %s", strings.Join(sortable, ", "))
+        }
+        p.Sort = sort
+    }
+
+    if order := strings.ToLower(c.Query("order")); order != "" {
+        if order != "asc" && order != "desc" {
+            return p, fmt.Errorf("order harus asc atau desc")
+        }
+        p.Order = order
+    }
+
+    if c.Context().QueryArgs().Has("cursor") {
+        if c.Query("sort") != "" {
+            return p, fmt.Errorf("parameter sort tidak dapat digabung dengan cursor, mode cursor selalu diurutkan berdasarkan id")
+        }
+        p.UseCursor = true
+        p.Page = 0
+        p.Sort = "id"
+        if cursor := c.Query("cursor"); cursor != "" {
+            id, err := decodeCursor(cursor)
+            if err != nil {
+                return p, fmt.Errorf("cursor tidak valid")
+            }
+            p.Cursor = id
+        }
+    }
+
+    return p, nil
+}
+
+// paginate menghitung total, menerapkan urutan dan halaman, lalu mengisi header Link.
+// Query yang dikirim sudah harus berisi filter dan Model yang sesuai.
+func paginate[T any](c *fiber.Ctx, query *gorm.DB, p Pagination, idOf func(T) uint) ([]T, PaginationMeta, error) {
+    var items []T
+    meta := PaginationMeta{Page: p.Page, Limit: p.Limit, Sort: p.Sort, Order: p.Order}
+
+    if err := query.Session(&gorm.Session{}).Count(&meta.Total).Error; err != nil {
+        return nil, meta, err
+    }
+
+    if p.UseCursor {
+        if p.Cursor > 0 {
+            if p.Order == "asc" {
+                query = query.Where("id > ?", p.Cursor)
+            } else {
+                query = query.Where("id < ?", p.Cursor)
+            }
+        }
+
+        if err := query.Order("id " + p.Order).Limit(p.Limit + 1).Find(&items).Error; err != nil {
+            return nil, meta, err
+        }
+
+        if len(items) > p.Limit {
+            items = items[:p.Limit]
+            meta.HasMore = true
+            meta.NextCursor = encodeCursor(idOf(items[len(items)-1]))
+        }
+
+        links := map[string]map[string]string{}
+        if meta.HasMore {
+            links["next"] = map[string]string{"cursor": meta.NextCursor}
+        }
+        setLinkHeader(c, links)
+        return items, meta, nil
+    }
+
+    order := p.Sort + " " + p.Order
+    if p.Sort != "id" {
+        order += ", id " + p.Order
+    }
+
+    offset := (p.Page - 1) * p.Limit
+    if err := query.Order(order).Offset(offset).Limit(p.Limit).Find(&items).Error; err != nil {
+        return nil, meta, err
+    }
+
+    meta.TotalPages = int((meta.Total + int64(p.Limit) - 1) / int64(p.Limit))
+    meta.HasMore = p.Page < meta.TotalPages
+
+    links := map[string]map[string]string{
+        "first": {"page": "1"},
+    }
+    if meta.TotalPages > 0 {
+        links["last"] = map[string]string{"page": strconv.Itoa(meta.TotalPages)}
+    }
+    if p.Page > 1 {
+        links["prev"] = map[string]string{"page": strconv.Itoa(p.Page - 1)}
+    }
+    if meta.HasMore {
+        links["next"] = map[string]string{"page": strconv.Itoa(p.Page + 1)}
+    }
+    setLinkHeader(c, links)
+
+    return items, meta, nil
+}
+
+// setLinkHeader menulis header Link (RFC 8288) dengan mempertahankan query lain seperti filter
+func setLinkHeader(c *fiber.Ctx, links map[string]map[string]string) {
+    var parts []string
+    for _, rel := range []string{"first", "prev", "next", "last"} {
+        params, ok := links[rel]
+        if !ok {
+            continue
+        }
+
+        values, _ := url.ParseQuery(string(c.Context().QueryArgs().QueryString()))
+        for key, value := range params {
+            values.Set(key, value)
+        }
+        parts = append(parts, fmt.Sprintf("<%s%s?%s>; rel=\"%s\"", c.BaseURL(), c.Path(), values.Encode(), rel))
+    }
+
+    if len(parts) > 0 {
+        c.Set(fiber.HeaderLink, strings.Join(parts, ", "))
+    }
+}
+
+// paginatedResponse membungkus data dengan metadata pagination
+func paginatedResponse(data interface{}, meta PaginationMeta) fiber.Map {
+    return fiber.Map{
+        "data":       data,
+        "pagination": meta,
+    }
+}
+
+func encodeCursor(id uint) string {
+    return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCursor(cursor string) (uint, error) {
+    raw, err := base64.RawURLEncoding.DecodeString(cursor)
+    if err != nil {
+        return 0, err
+    }
+    id, err := strconv.ParseUint(string(raw), 10, 64)
+    return uint(id), err
+}
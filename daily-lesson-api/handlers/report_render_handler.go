@@ -0,0 +1,109 @@
+package handlers
+
+import (
+    "bytes"
+    "fmt"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/models"
+    "daily-lesson-api/utils"
+)
+
+// GetTeacherJurnalPDF mencetak jurnal mengajar seorang guru untuk periode tertentu dalam bentuk PDF.
+// Kop, judul dan kolom tabel mengikuti profil sekolah dan template laporan guru.
+func GetTeacherJurnalPDF(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    data, ok, err := teacherReportData(c)
+    if !ok {
+        return err
+    }
+
+    createActivity(userEmail, "view_report", teacherReportDescription(c, "Mencetak jurnal mengajar"))
+    return sendJurnalPDF(c, data)
+}
+
+// GetTeacherReportHTML menampilkan laporan guru memakai template HTML/teks yang diatur admin
+func GetTeacherReportHTML(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    data, ok, err := teacherReportData(c)
+    if !ok {
+        return err
+    }
+
+    tmpl, _ := loadReportTemplate(models.ReportTeacher)
+    createActivity(userEmail, "view_report", teacherReportDescription(c, "Mencetak laporan guru"))
+    return sendRenderedReport(c, tmpl, data)
+}
+
+// teacherReportData mengambil lesson laporan guru lalu menyusunnya dengan template dan profil sekolah.
+// Jika ok bernilai false, response error sudah dikirim dan err harus dikembalikan oleh handler.
+func teacherReportData(c *fiber.Ctx) (utils.JurnalData, bool, error) {
+    guru := c.Query("guru")
+    if guru == "" {
+        return utils.JurnalData{}, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Parameter guru wajib diisi",
+        })
+    }
+
+    var lessons []models.DailyLesson
+    if err := teacherReportQuery(c).Order("tanggal_mengajar ASC, jam_mulai ASC").Find(&lessons).Error; err != nil {
+        return utils.JurnalData{}, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch teacher report",
+        })
+    }
+
+    tmpl, _ := loadReportTemplate(models.ReportTeacher)
+    namaGuru := jurnalTeacherName(guru, lessons)
+    periode := jurnalPeriode(c.Query("start_date"), c.Query("end_date"))
+
+    data, err := reportJurnalData(tmpl, loadSchoolProfile(), namaGuru, periode, lessons)
+    if err != nil {
+        return utils.JurnalData{}, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": fmt.Sprintf("Template laporan tidak valid: %v", err),
+        })
+    }
+    return data, true, nil
+}
+
+// sendJurnalPDF merender jurnal mengajar ke PDF dan mengirimkannya
+func sendJurnalPDF(c *fiber.Ctx, data utils.JurnalData) error {
+    var buf bytes.Buffer
+    if err := utils.RenderJurnalPDF(&buf, data); err != nil {
+        fmt.Printf("Failed to render jurnal PDF: %v\n", err)
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not generate PDF",
+        })
+    }
+
+    filename := fmt.Sprintf("jurnal-mengajar_%s.pdf", sanitizeArchiveName(data.NamaGuru))
+    c.Set(fiber.HeaderContentType, "application/pdf")
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+    return c.Send(buf.Bytes())
+}
+
+// jurnalTeacherName memakai nama guru dari data jika semua lesson milik guru yang sama,
+// karena parameter guru bisa berupa potongan nama
+func jurnalTeacherName(guru string, lessons []models.DailyLesson) string {
+    if len(lessons) == 0 {
+        return guru
+    }
+    for _, lesson := range lessons[1:] {
+        if lesson.NamaGuru != lessons[0].NamaGuru {
+            return guru
+        }
+    }
+    return lessons[0].NamaGuru
+}
+
+// jurnalPeriode menulis rentang tanggal laporan dalam format Indonesia
+func jurnalPeriode(startDate, endDate string) string {
+    start, errStart := time.Parse("2006-01-02", startDate)
+    end, errEnd := time.Parse("2006-01-02", endDate)
+    if errStart != nil || errEnd != nil {
+        return "Semua tanggal"
+    }
+    return fmt.Sprintf("%s s.d. %s", utils.FormatTanggal(start), utils.FormatTanggal(end))
+}
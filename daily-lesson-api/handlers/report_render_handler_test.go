@@ -0,0 +1,83 @@
+package handlers
+
+import (
+    "bytes"
+    "io"
+    "net/http/httptest"
+    "net/url"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/models"
+)
+
+func teacherReportApp() *fiber.App {
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("email", "supervisor@sekolah.test")
+        return c.Next()
+    })
+    app.Get("/reports/teacher/pdf", GetTeacherJurnalPDF)
+    return app
+}
+
+func TestGetTeacherJurnalPDF(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        PokokMateri: "Pecahan", JamMulai: "07:00", JamSelesai: "08:30",
+    })
+    app := teacherReportApp()
+
+    t.Run("renders pdf", func(t *testing.T) {
+        query := url.Values{"guru": {"Ani"}, "start_date": {"2026-10-01"}, "end_date": {"2026-10-31"}}
+        resp, err := app.Test(httptest.NewRequest("GET", "/reports/teacher/pdf?"+query.Encode(), nil))
+        if err != nil {
+            t.Fatalf("request: %v", err)
+        }
+        if resp.StatusCode != fiber.StatusOK || resp.Header.Get("Content-Type") != "application/pdf" {
+            t.Fatalf("status = %d, content type = %q, want 200 application/pdf", resp.StatusCode, resp.Header.Get("Content-Type"))
+        }
+        // Nama file memakai nama lengkap guru dari data, bukan potongan nama di parameter
+        if disposition := resp.Header.Get("Content-Disposition"); disposition != `inline; filename="jurnal-mengajar_Bu_Ani.pdf"` {
+            t.Errorf("content disposition = %q", disposition)
+        }
+        body, _ := io.ReadAll(resp.Body)
+        if !bytes.HasPrefix(body, []byte("%PDF-")) {
+            t.Errorf("body does not start with a PDF header")
+        }
+    })
+
+    t.Run("requires guru", func(t *testing.T) {
+        resp, err := app.Test(httptest.NewRequest("GET", "/reports/teacher/pdf", nil))
+        if err != nil {
+            t.Fatalf("request: %v", err)
+        }
+        if resp.StatusCode != fiber.StatusBadRequest {
+            t.Fatalf("status = %d, want 400", resp.StatusCode)
+        }
+    })
+}
+
+func TestJurnalTeacherNameAndPeriode(t *testing.T) {
+    same := []models.DailyLesson{{NamaGuru: "Bu Ani"}, {NamaGuru: "Bu Ani"}}
+    mixed := []models.DailyLesson{{NamaGuru: "Bu Ani"}, {NamaGuru: "Bu Anita"}}
+
+    if name := jurnalTeacherName("Ani", same); name != "Bu Ani" {
+        t.Errorf("name = %q, want Bu Ani", name)
+    }
+    if name := jurnalTeacherName("Ani", mixed); name != "Ani" {
+        t.Errorf("name = %q, want the requested name for mixed teachers", name)
+    }
+    if name := jurnalTeacherName("Ani", nil); name != "Ani" {
+        t.Errorf("name = %q, want the requested name without lessons", name)
+    }
+
+    if periode := jurnalPeriode("2026-10-01", "2026-10-31"); periode != "1 Oktober 2026 s.d. 31 Oktober 2026" {
+        t.Errorf("periode = %q", periode)
+    }
+    if periode := jurnalPeriode("", "2026-10-31"); periode != "Semua tanggal" {
+        t.Errorf("periode = %q, want Semua tanggal", periode)
+    }
+}
@@ -0,0 +1,449 @@
+package handlers
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+    "daily-lesson-api/utils"
+)
+
+// defaultReportTemplates adalah template bawaan untuk setiap laporan yang mendukung template.
+// Admin bisa menimpanya; jika template di database dihapus, template bawaan dipakai lagi.
+var defaultReportTemplates = map[string]models.ReportTemplate{
+    models.ReportTeacher: {
+        Key:     models.ReportTeacher,
+        Name:    "Laporan Mengajar Guru",
+        Title:   "Jurnal Mengajar Guru",
+        Format:  models.TemplateFormatHTML,
+        Content: utils.DefaultTeacherReportHTML,
+    },
+}
+
+type SchoolProfileRequest struct {
+    SchoolName        string `json:"school_name"`
+    Address           string `json:"address"`
+    Contact           string `json:"contact"`
+    City              string `json:"city"`
+    KepalaSekolahName string `json:"kepala_sekolah_name"`
+    KepalaSekolahNIP  string `json:"kepala_sekolah_nip"`
+    SupervisorName    string `json:"supervisor_name"`
+    SupervisorNIP     string `json:"supervisor_nip"`
+}
+
+type ReportTemplateRequest struct {
+    Name    string   `json:"name"`
+    Title   string   `json:"title"`
+    Format  string   `json:"format"`
+    Content string   `json:"content"`
+    Columns []string `json:"columns"`
+}
+
+// loadSchoolProfile mengambil profil sekolah; sebelum admin mengisinya, nilai diambil dari environment
+func loadSchoolProfile() models.SchoolProfile {
+    var profile models.SchoolProfile
+    if err := database.DB.First(&profile).Error; err != nil {
+        letterhead := utils.LetterheadFromEnv()
+        profile = models.SchoolProfile{
+            SchoolName:        letterhead.SchoolName,
+            Address:           letterhead.Address,
+            Contact:           letterhead.Contact,
+            City:              letterhead.City,
+            KepalaSekolahName: os.Getenv("KEPALA_SEKOLAH_NAME"),
+            KepalaSekolahNIP:  os.Getenv("KEPALA_SEKOLAH_NIP"),
+            SupervisorName:    os.Getenv("SUPERVISOR_NAME"),
+            SupervisorNIP:     os.Getenv("SUPERVISOR_NIP"),
+        }
+    }
+    profile.HasLogo = profile.LogoPath != ""
+    return profile
+}
+
+// schoolLetterhead mengubah profil sekolah menjadi kop laporan
+func schoolLetterhead(profile models.SchoolProfile) utils.Letterhead {
+    return utils.Letterhead{
+        SchoolName: profile.SchoolName,
+        Address:    profile.Address,
+        Contact:    profile.Contact,
+        City:       profile.City,
+        LogoPath:   profile.LogoPath,
+    }
+}
+
+// reportSignatories menyusun kolom tanda tangan laporan guru
+func reportSignatories(profile models.SchoolProfile, namaGuru string) []utils.Signatory {
+    return []utils.Signatory{
+        {Title: "Guru Mata Pelajaran", Name: namaGuru},
+        {Title: "Supervisor", Name: profile.SupervisorName, NIP: profile.SupervisorNIP},
+        {Title: "Kepala Sekolah", Name: profile.KepalaSekolahName, NIP: profile.KepalaSekolahNIP},
+    }
+}
+
+// loadReportTemplate mengambil template dari database atau template bawaan.
+// Nilai kedua false jika laporan dengan key tersebut tidak mendukung template.
+func loadReportTemplate(key string) (models.ReportTemplate, bool) {
+    fallback, ok := defaultReportTemplates[key]
+    if !ok {
+        return models.ReportTemplate{}, false
+    }
+
+    var tmpl models.ReportTemplate
+    if err := database.DB.Where("`key` = ?", key).First(&tmpl).Error; err != nil {
+        fallback.IsDefault = true
+        return fallback, true
+    }
+    return tmpl, true
+}
+
+// applyTemplateRequest menimpa field template dengan isi request yang tidak kosong
+func applyTemplateRequest(tmpl *models.ReportTemplate, req ReportTemplateRequest) {
+    if req.Name != "" {
+        tmpl.Name = req.Name
+    }
+    if req.Title != "" {
+        tmpl.Title = req.Title
+    }
+    if req.Format != "" {
+        tmpl.Format = strings.ToLower(req.Format)
+    }
+    if req.Content != "" {
+        tmpl.Content = req.Content
+    }
+    if req.Columns != nil {
+        tmpl.Columns = req.Columns
+    }
+}
+
+// reportJurnalData menyusun data laporan dari template dan profil sekolah
+func reportJurnalData(tmpl models.ReportTemplate, profile models.SchoolProfile, namaGuru, periode string, lessons []models.DailyLesson) (utils.JurnalData, error) {
+    columns, err := utils.JurnalColumnsFor(tmpl.Columns)
+    if err != nil {
+        return utils.JurnalData{}, err
+    }
+
+    return utils.JurnalData{
+        Letterhead:  schoolLetterhead(profile),
+        Title:       tmpl.Title,
+        NamaGuru:    namaGuru,
+        Periode:     periode,
+        Columns:     columns,
+        Lessons:     lessons,
+        Signatories: reportSignatories(profile, namaGuru),
+        PrintedAt:   time.Now(),
+    }, nil
+}
+
+// previewJurnalData adalah contoh data untuk preview template
+func previewJurnalData(tmpl models.ReportTemplate, profile models.SchoolProfile) (utils.JurnalData, error) {
+    start := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+    lessons := []models.DailyLesson{
+        {
+            NamaGuru: "Siti Rahmawati, S.Pd.", MataPelajaran: "Matematika", Kelas: "X RPL 1",
+            PokokMateri: "Persamaan dan pertidaksamaan linear satu variabel", TanggalMengajar: start,
+            JamMulai: "07:00", JamSelesai: "08:30", Status: "terlaksana", Catatan: "Siswa aktif berdiskusi",
+            VerifikasiBukti: models.VerifikasiSesuai,
+        },
+        {
+            NamaGuru: "Siti Rahmawati, S.Pd.", MataPelajaran: "Matematika", Kelas: "X RPL 2",
+            PokokMateri: "Latihan soal persamaan linear", TanggalMengajar: start.AddDate(0, 0, 1),
+            JamMulai: "09:00", JamSelesai: "10:30", Status: "ditunda", Catatan: "Kelas mengikuti upacara",
+            VerifikasiBukti: models.VerifikasiBelum,
+        },
+    }
+
+    periode := fmt.Sprintf("%s s.d. %s", utils.FormatTanggal(start), utils.FormatTanggal(start.AddDate(0, 1, -1)))
+    return reportJurnalData(tmpl, profile, lessons[0].NamaGuru, periode, lessons)
+}
+
+// validateReportTemplate memeriksa format, kolom dan isi template dengan merendernya memakai contoh data
+func validateReportTemplate(tmpl models.ReportTemplate, profile models.SchoolProfile) error {
+    if tmpl.Format != models.TemplateFormatHTML && tmpl.Format != models.TemplateFormatText {
+        return fmt.Errorf("format template harus html atau text")
+    }
+    if strings.TrimSpace(tmpl.Content) == "" {
+        return fmt.Errorf("isi template wajib diisi")
+    }
+
+    data, err := previewJurnalData(tmpl, profile)
+    if err != nil {
+        return err
+    }
+    if err := utils.RenderReportTemplate(io.Discard, tmpl.Format, tmpl.Content, utils.NewReportView(data)); err != nil {
+        return fmt.Errorf("template tidak valid: %v", err)
+    }
+    return nil
+}
+
+// sendRenderedReport mengirim hasil template sebagai HTML atau teks biasa
+func sendRenderedReport(c *fiber.Ctx, tmpl models.ReportTemplate, data utils.JurnalData) error {
+    var buf bytes.Buffer
+    if err := utils.RenderReportTemplate(&buf, tmpl.Format, tmpl.Content, utils.NewReportView(data)); err != nil {
+        return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+            "error": fmt.Sprintf("Template tidak valid: %v", err),
+        })
+    }
+
+    if tmpl.Format == models.TemplateFormatText {
+        c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+    } else {
+        c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+    }
+    return c.Send(buf.Bytes())
+}
+
+// GetSchoolProfile menampilkan profil sekolah yang dipakai sebagai kop laporan
+func GetSchoolProfile(c *fiber.Ctx) error {
+    return c.JSON(loadSchoolProfile())
+}
+
+// UpdateSchoolProfile mengubah identitas sekolah dan pejabat penandatangan laporan
+func UpdateSchoolProfile(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req SchoolProfileRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Invalid request body",
+        })
+    }
+
+    if strings.TrimSpace(req.SchoolName) == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Nama sekolah wajib diisi",
+        })
+    }
+
+    var profile models.SchoolProfile
+    database.DB.First(&profile)
+
+    profile.SchoolName = req.SchoolName
+    profile.Address = req.Address
+    profile.Contact = req.Contact
+    profile.City = req.City
+    profile.KepalaSekolahName = req.KepalaSekolahName
+    profile.KepalaSekolahNIP = req.KepalaSekolahNIP
+    profile.SupervisorName = req.SupervisorName
+    profile.SupervisorNIP = req.SupervisorNIP
+    profile.UpdatedByID = userID
+
+    if err := database.DB.Save(&profile).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update school profile",
+        })
+    }
+
+    createActivity(userEmail, "update_profile", "Memperbarui profil sekolah")
+
+    profile.HasLogo = profile.LogoPath != ""
+    return c.JSON(profile)
+}
+
+// UploadSchoolLogo menyimpan logo sekolah untuk kop laporan (PNG/JPG)
+func UploadSchoolLogo(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+
+    file, err := c.FormFile("file")
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "File logo wajib diunggah (field: file)",
+        })
+    }
+
+    ext := strings.ToLower(filepath.Ext(file.Filename))
+    if !allowedEvidenceExtensions[ext] {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format file tidak didukung. Gunakan JPG atau PNG",
+        })
+    }
+
+    dir, err := utils.UploadDir("branding")
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not prepare upload directory",
+        })
+    }
+
+    path := filepath.Join(dir, fmt.Sprintf("logo_%d%s", time.Now().UnixNano(), ext))
+    if err := c.SaveFile(file, path); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not save logo file",
+        })
+    }
+
+    // Profil dibuat dari nilai environment jika admin belum pernah menyimpannya
+    profile := loadSchoolProfile()
+    oldLogo := profile.LogoPath
+    profile.LogoPath = path
+    profile.UpdatedByID = userID
+
+    if err := database.DB.Save(&profile).Error; err != nil {
+        os.Remove(path)
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update school profile",
+        })
+    }
+
+    if oldLogo != "" && oldLogo != path {
+        os.Remove(oldLogo)
+    }
+
+    createActivity(userEmail, "update_profile", "Mengunggah logo sekolah")
+
+    profile.HasLogo = true
+    return c.JSON(profile)
+}
+
+// GetSchoolLogo mengirim file logo sekolah
+func GetSchoolLogo(c *fiber.Ctx) error {
+    profile := loadSchoolProfile()
+    if profile.LogoPath == "" {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Logo sekolah belum diunggah",
+        })
+    }
+
+    if _, err := os.Stat(profile.LogoPath); err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "File logo tidak ditemukan",
+        })
+    }
+
+    return c.SendFile(profile.LogoPath)
+}
+
+// GetReportTemplates menampilkan template semua laporan beserta kolom yang bisa dipilih
+func GetReportTemplates(c *fiber.Ctx) error {
+    keys := make([]string, 0, len(defaultReportTemplates))
+    for key := range defaultReportTemplates {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    templates := make([]models.ReportTemplate, 0, len(keys))
+    for _, key := range keys {
+        tmpl, _ := loadReportTemplate(key)
+        templates = append(templates, tmpl)
+    }
+
+    return c.JSON(fiber.Map{
+        "data":              templates,
+        "available_columns": utils.AvailableJurnalColumns,
+    })
+}
+
+// GetReportTemplate menampilkan satu template laporan
+func GetReportTemplate(c *fiber.Ctx) error {
+    tmpl, ok := loadReportTemplate(c.Params("key"))
+    if !ok {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Template laporan tidak ditemukan",
+        })
+    }
+    return c.JSON(tmpl)
+}
+
+// UpdateReportTemplate menyimpan perubahan template setelah dicoba dengan contoh data
+func UpdateReportTemplate(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req ReportTemplateRequest
+
+    tmpl, ok := loadReportTemplate(c.Params("key"))
+    if !ok {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Template laporan tidak ditemukan",
+        })
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Invalid request body",
+        })
+    }
+
+    applyTemplateRequest(&tmpl, req)
+    if err := validateReportTemplate(tmpl, loadSchoolProfile()); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    tmpl.UpdatedByID = userID
+    if err := database.DB.Save(&tmpl).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not save report template",
+        })
+    }
+
+    createActivity(userEmail, "update_template", fmt.Sprintf("Memperbarui template laporan %s", tmpl.Key))
+
+    tmpl.IsDefault = false
+    return c.JSON(tmpl)
+}
+
+// ResetReportTemplate menghapus template buatan admin sehingga template bawaan dipakai lagi
+func ResetReportTemplate(c *fiber.Ctx) error {
+    key := c.Params("key")
+    userEmail := c.Locals("email").(string)
+
+    if _, ok := defaultReportTemplates[key]; !ok {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Template laporan tidak ditemukan",
+        })
+    }
+
+    if err := database.DB.Unscoped().Where("`key` = ?", key).Delete(&models.ReportTemplate{}).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not reset report template",
+        })
+    }
+
+    createActivity(userEmail, "update_template", fmt.Sprintf("Mengembalikan template laporan %s ke bawaan", key))
+
+    tmpl, _ := loadReportTemplate(key)
+    return c.JSON(tmpl)
+}
+
+// PreviewReportTemplate merender template dengan contoh data. Isi body (opsional) dipakai
+// sebagai perubahan yang belum disimpan; output=pdf menampilkan versi PDF.
+func PreviewReportTemplate(c *fiber.Ctx) error {
+    var req ReportTemplateRequest
+
+    tmpl, ok := loadReportTemplate(c.Params("key"))
+    if !ok {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Template laporan tidak ditemukan",
+        })
+    }
+
+    if len(c.Body()) > 0 {
+        if err := c.BodyParser(&req); err != nil {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "Invalid request body",
+            })
+        }
+        applyTemplateRequest(&tmpl, req)
+    }
+
+    data, err := previewJurnalData(tmpl, loadSchoolProfile())
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    if c.Query("output") == "pdf" {
+        return sendJurnalPDF(c, data)
+    }
+    return sendRenderedReport(c, tmpl, data)
+}
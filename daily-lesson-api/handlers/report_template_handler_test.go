@@ -0,0 +1,112 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "io"
+    "net/http/httptest"
+    "net/url"
+    "strings"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/models"
+)
+
+func reportTemplateApp(userID uint) *fiber.App {
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("email", "admin@sekolah.test")
+        return c.Next()
+    })
+    app.Get("/reports/teacher/html", GetTeacherReportHTML)
+    app.Put("/admin/report-templates/:key", UpdateReportTemplate)
+    app.Delete("/admin/report-templates/:key", ResetReportTemplate)
+    app.Post("/admin/report-templates/:key/preview", PreviewReportTemplate)
+    return app
+}
+
+func sendTemplateRequest(t *testing.T, app *fiber.App, method, path string, body interface{}) (int, string) {
+    t.Helper()
+
+    var reader io.Reader
+    if body != nil {
+        data, _ := json.Marshal(body)
+        reader = bytes.NewReader(data)
+    }
+    req := httptest.NewRequest(method, path, reader)
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    data, _ := io.ReadAll(resp.Body)
+    return resp.StatusCode, string(data)
+}
+
+func TestReportTemplateLifecycle(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "admin", models.RoleAdmin)
+    guru := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "Bu Ani", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        PokokMateri: "<b>Pecahan</b>",
+    })
+    app := reportTemplateApp(admin.ID)
+    path := "/admin/report-templates/" + models.ReportTeacher
+    reportPath := "/reports/teacher/html?" + url.Values{"guru": {"Bu Ani"}}.Encode()
+
+    t.Run("invalid templates are rejected", func(t *testing.T) {
+        for name, req := range map[string]ReportTemplateRequest{
+            "unknown column": {Columns: []string{"no", "nilai"}},
+            "unknown format": {Format: "pdf"},
+            "broken syntax":  {Content: "{{range .Rows}}"},
+        } {
+            if status, body := sendTemplateRequest(t, app, "PUT", path, req); status != fiber.StatusBadRequest {
+                t.Errorf("%s: status = %d (%s), want 400", name, status, body)
+            }
+        }
+    })
+
+    t.Run("preview does not save", func(t *testing.T) {
+        req := ReportTemplateRequest{Format: models.TemplateFormatText, Content: "PREVIEW {{.NamaGuru}}"}
+        status, body := sendTemplateRequest(t, app, "POST", path+"/preview", req)
+        if status != fiber.StatusOK || body != "PREVIEW Siti Rahmawati, S.Pd." {
+            t.Fatalf("preview = %d %q", status, body)
+        }
+        if _, report := sendTemplateRequest(t, app, "GET", reportPath, nil); strings.Contains(report, "PREVIEW") {
+            t.Fatalf("report uses an unsaved preview template")
+        }
+    })
+
+    t.Run("saved template is used and escaped", func(t *testing.T) {
+        req := ReportTemplateRequest{
+            Title:   "Jurnal Kelas",
+            Columns: []string{"kelas", "pokok_materi"},
+            Content: "<h1>{{.Title}}</h1>{{range .Rows}}<p>{{index . 0}}|{{index . 1}}</p>{{end}}",
+        }
+        if status, body := sendTemplateRequest(t, app, "PUT", path, req); status != fiber.StatusOK {
+            t.Fatalf("update status = %d (%s), want 200", status, body)
+        }
+
+        status, report := sendTemplateRequest(t, app, "GET", reportPath, nil)
+        if status != fiber.StatusOK || report != "<h1>Jurnal Kelas</h1><p>7A|&lt;b&gt;Pecahan&lt;/b&gt;</p>" {
+            t.Fatalf("report = %d %q", status, report)
+        }
+    })
+
+    t.Run("reset restores the default", func(t *testing.T) {
+        status, body := sendTemplateRequest(t, app, "DELETE", path, nil)
+        var tmpl models.ReportTemplate
+        json.Unmarshal([]byte(body), &tmpl)
+        if status != fiber.StatusOK || !tmpl.IsDefault || tmpl.Title != defaultReportTemplates[models.ReportTeacher].Title {
+            t.Fatalf("reset = %d %+v, want the default template", status, tmpl)
+        }
+
+        if _, report := sendTemplateRequest(t, app, "GET", reportPath, nil); strings.Contains(report, "Jurnal Kelas") {
+            t.Fatalf("report still uses the removed template")
+        }
+    })
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+    "fmt"
+    "strings"
+    "unicode"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// LessonSearchResult adalah lesson hasil pencarian beserta skor relevansi dan potongan teks
+// yang kata kuncinya ditandai dengan <mark>
+type LessonSearchResult struct {
+    models.DailyLesson
+    Score          float64 `json:"score"`
+    SnippetMateri  string  `json:"snippet_pokok_materi"`
+    SnippetCatatan string  `json:"snippet_catatan"`
+}
+
+// SearchLessons mencari lesson berdasarkan isi materi, catatan, mapel dan nama guru
+// menggunakan index FTS5, diurutkan dari yang paling relevan
+func SearchLessons(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    keyword := strings.TrimSpace(c.Query("q"))
+
+    match := buildMatchQuery(keyword)
+    if match == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Parameter q wajib diisi",
+        })
+    }
+
+    pagination, err := parsePagination(c, []string{"score", "id", "tanggal_mengajar"}, "score", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    // bm25 bernilai makin kecil untuk hasil yang makin relevan, jadi dibalik menjadi score.
+    // Bobot kolom: pokok_materi dan catatan lebih penting dari mapel dan nama guru.
+    query := database.DB.Table("daily_lessons").
+        Select("daily_lessons.*, s.score, s.snippet_materi, s.snippet_catatan").
+        Joins(`JOIN (
+            SELECT rowid AS lesson_id,
+                -bm25(daily_lessons_fts, 10.0, 5.0, 2.0, 1.0) AS score,
+                snippet(daily_lessons_fts, 0, '<mark>', '</mark>', '...', 16) AS snippet_materi,
+                snippet(daily_lessons_fts, 1, '<mark>', '</mark>', '...', 16) AS snippet_catatan
+            FROM daily_lessons_fts WHERE daily_lessons_fts MATCH ?
+        ) AS s ON s.lesson_id = daily_lessons.id`, match)
+    query = filterLessonFields(c, query)
+
+    results, meta, err := paginate(c, query, pagination, func(result LessonSearchResult) uint {
+        return result.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not search lessons",
+        })
+    }
+
+    createActivity(userEmail, "search", fmt.Sprintf("Mencari lesson: %s", keyword))
+
+    return c.JSON(paginatedResponse(results, meta))
+}
+
+// buildMatchQuery mengubah input bebas menjadi query FTS5 yang aman: setiap kata
+// di-quote (tanda baca dan operator FTS diabaikan) dan dicocokkan sebagai prefix
+func buildMatchQuery(keyword string) string {
+    words := strings.FieldsFunc(keyword, func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+    })
+
+    terms := make([]string, 0, len(words))
+    for _, word := range words {
+        terms = append(terms, fmt.Sprintf("\"%s\"*", word))
+    }
+    return strings.Join(terms, " ")
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http/httptest"
+    "net/url"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func searchTestLessons(t *testing.T, keyword string) []LessonSearchResult {
+    t.Helper()
+
+    app := fiber.New()
+    app.Get("/lessons/search", func(c *fiber.Ctx) error {
+        c.Locals("userID", uint(0))
+        c.Locals("role", string(models.RoleAdmin))
+        c.Locals("email", "admin@sekolah.test")
+        return SearchLessons(c)
+    })
+
+    resp, err := app.Test(httptest.NewRequest("GET", "/lessons/search?q="+url.QueryEscape(keyword), nil))
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+
+    var body struct {
+        Data []LessonSearchResult `json:"data"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+        t.Fatalf("decode: %v", err)
+    }
+    return body.Data
+}
+
+func TestSearchLessonsRanksMateriAboveCatatan(t *testing.T) {
+    setupTestDB(t)
+    database.SetupLessonSearch()
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+
+    // Kata kunci di catatan saja, di pokok materi, dan tidak ada sama sekali
+    catatan := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        PokokMateri: "Bilangan bulat", Catatan: "Lanjut pecahan minggu depan",
+    })
+    materi := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID,
+        PokokMateri: "Operasi pecahan campuran", Catatan: "Siswa aktif",
+    })
+    createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7C", TanggalMengajar: testDate("2026-10-07"), CreatedByID: guru.ID,
+        PokokMateri: "Aljabar", Catatan: "Kuis",
+    })
+
+    results := searchTestLessons(t, "pecah")
+    if len(results) != 2 {
+        t.Fatalf("results = %d, want 2", len(results))
+    }
+    if results[0].ID != materi.ID || results[1].ID != catatan.ID {
+        t.Fatalf("order = [%d %d], want [%d %d]", results[0].ID, results[1].ID, materi.ID, catatan.ID)
+    }
+    if results[0].Score <= results[1].Score {
+        t.Fatalf("scores = [%f %f], want descending", results[0].Score, results[1].Score)
+    }
+    if results[0].SnippetMateri != "Operasi <mark>pecahan</mark> campuran" {
+        t.Fatalf("snippet = %q", results[0].SnippetMateri)
+    }
+}
+
+func TestSearchLessonsExcludesDeletedLessons(t *testing.T) {
+    setupTestDB(t)
+    database.SetupLessonSearch()
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+
+    kept := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        PokokMateri: "Fotosintesis",
+    })
+    deleted := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID,
+        PokokMateri: "Fotosintesis lanjutan",
+    })
+    if err := database.DB.Delete(&deleted).Error; err != nil {
+        t.Fatalf("delete lesson: %v", err)
+    }
+
+    results := searchTestLessons(t, "fotosintesis")
+    if len(results) != 1 || results[0].ID != kept.ID {
+        t.Fatalf("results = %+v, want only lesson %d", results, kept.ID)
+    }
+}
@@ -0,0 +1,665 @@
+package handlers
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// PlannedTopicRequest adalah satu topik pada request program semester. ID diisi saat
+// mengubah topik yang sudah ada agar lesson yang terpasang ke topik itu tetap terpasang.
+type PlannedTopicRequest struct {
+    ID                   *uint  `json:"id"`
+    Topik                string `json:"topik"`
+    MingguTarget         int    `json:"minggu_target"`
+    TujuanPembelajaranID *uint  `json:"tujuan_pembelajaran_id"`
+}
+
+type SemesterPlanRequest struct {
+    MataPelajaran  string                `json:"mata_pelajaran"`
+    Kelas          string                `json:"kelas"`
+    TahunAjaran    string                `json:"tahun_ajaran"`
+    Semester       int                   `json:"semester"`
+    TanggalMulai   string                `json:"tanggal_mulai"`
+    TanggalSelesai string                `json:"tanggal_selesai"`
+    Topics         []PlannedTopicRequest `json:"topics"`
+}
+
+type LessonTopicRequest struct {
+    PlannedTopicID *uint `json:"planned_topic_id"`
+}
+
+// TopicProgress adalah ketercapaian satu topik program semester
+type TopicProgress struct {
+    ID              uint   `json:"id"`
+    Urutan          int    `json:"urutan"`
+    Topik           string `json:"topik"`
+    MingguTarget    int    `json:"minggu_target"`
+    JumlahPertemuan int    `json:"jumlah_pertemuan"`
+    Terakhir        string `json:"terakhir"`
+}
+
+// PlanProgress adalah posisi kelas pada program semester dibanding minggu berjalan.
+// GapMinggu positif berarti lebih cepat, negatif berarti tertinggal.
+type PlanProgress struct {
+    PlanID         uint            `json:"plan_id"`
+    MataPelajaran  string          `json:"mata_pelajaran"`
+    Kelas          string          `json:"kelas"`
+    TahunAjaran    string          `json:"tahun_ajaran"`
+    Semester       int             `json:"semester"`
+    MingguBerjalan int             `json:"minggu_berjalan"`
+    TotalTopik     int             `json:"total_topik"`
+    Tercapai       int             `json:"tercapai"`
+    TopikBerikut   *TopicProgress  `json:"topik_berikut"`
+    Status         string          `json:"status"`
+    GapMinggu      int             `json:"gap_minggu"`
+    Topics         []TopicProgress `json:"topics,omitempty"`
+}
+
+// validateSemesterPlan memeriksa request dan mengubahnya menjadi SemesterPlan beserta topiknya
+func validateSemesterPlan(req SemesterPlanRequest) (models.SemesterPlan, error) {
+    plan := models.SemesterPlan{
+        MataPelajaran: strings.TrimSpace(req.MataPelajaran),
+        Kelas:         strings.TrimSpace(req.Kelas),
+        TahunAjaran:   strings.TrimSpace(req.TahunAjaran),
+        Semester:      req.Semester,
+    }
+
+    if plan.MataPelajaran == "" || plan.Kelas == "" {
+        return plan, fmt.Errorf("mata_pelajaran dan kelas wajib diisi")
+    }
+    if !validTahunAjaran(plan.TahunAjaran) {
+        return plan, fmt.Errorf("tahun_ajaran harus berformat 2025/2026")
+    }
+    if plan.Semester != 1 && plan.Semester != 2 {
+        return plan, fmt.Errorf("semester harus 1 atau 2")
+    }
+
+    mulai, err := time.Parse("2006-01-02", req.TanggalMulai)
+    if err != nil {
+        return plan, fmt.Errorf("tanggal_mulai harus berformat YYYY-MM-DD")
+    }
+    selesai, err := time.Parse("2006-01-02", req.TanggalSelesai)
+    if err != nil || !selesai.After(mulai) {
+        return plan, fmt.Errorf("tanggal_selesai harus berformat YYYY-MM-DD dan setelah tanggal_mulai")
+    }
+    plan.TanggalMulai = mulai
+    plan.TanggalSelesai = selesai
+
+    if len(req.Topics) == 0 {
+        return plan, fmt.Errorf("topics wajib diisi")
+    }
+    totalMinggu := int(weekStart(selesai).Sub(weekStart(mulai)).Hours()/24/7) + 1
+    previousWeek := 0
+    for i, topic := range req.Topics {
+        topik := strings.TrimSpace(topic.Topik)
+        if topik == "" {
+            return plan, fmt.Errorf("topics[%d]: topik wajib diisi", i)
+        }
+        if topic.MingguTarget < 1 || topic.MingguTarget > totalMinggu {
+            return plan, fmt.Errorf("topics[%d]: minggu_target harus antara 1 dan %d", i, totalMinggu)
+        }
+        if topic.MingguTarget < previousWeek {
+            return plan, fmt.Errorf("topics[%d]: minggu_target tidak boleh lebih awal dari topik sebelumnya", i)
+        }
+        previousWeek = topic.MingguTarget
+
+        plannedTopic := models.PlannedTopic{
+            Urutan:               i + 1,
+            Topik:                topik,
+            MingguTarget:         topic.MingguTarget,
+            TujuanPembelajaranID: topic.TujuanPembelajaranID,
+        }
+        if topic.ID != nil {
+            plannedTopic.ID = *topic.ID
+        }
+        plan.Topics = append(plan.Topics, plannedTopic)
+    }
+
+    return plan, nil
+}
+
+// findDuplicatePlan mencari program semester lain untuk mapel, kelas, tahun ajaran dan semester yang sama
+func findDuplicatePlan(plan models.SemesterPlan) (models.SemesterPlan, bool) {
+    var existing models.SemesterPlan
+    err := database.DB.Where("lower(mata_pelajaran) = lower(?) AND lower(kelas) = lower(?) AND tahun_ajaran = ? AND semester = ? AND id <> ?",
+        plan.MataPelajaran, plan.Kelas, plan.TahunAjaran, plan.Semester, plan.ID).First(&existing).Error
+    return existing, err == nil
+}
+
+// loadOwnedPlan mengambil program semester; guru hanya boleh mengubah program buatannya.
+// Jika gagal, response sudah dikirim dan ok bernilai false.
+func loadOwnedPlan(c *fiber.Ctx) (models.SemesterPlan, bool, error) {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    var plan models.SemesterPlan
+
+    if err := database.DB.First(&plan, c.Params("id")).Error; err != nil {
+        return plan, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Semester plan not found",
+        })
+    }
+
+    if userRole == "teacher" && plan.CreatedByID != userID {
+        return plan, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Access denied",
+        })
+    }
+
+    return plan, true, nil
+}
+
+// GetSemesterPlans menampilkan daftar program semester, bisa difilter per kelas, mapel dan tahun ajaran
+func GetSemesterPlans(c *fiber.Ctx) error {
+    var plans []models.SemesterPlan
+
+    query := database.DB.Model(&models.SemesterPlan{})
+    if kelas := c.Query("kelas"); kelas != "" {
+        query = query.Where("lower(kelas) = lower(?)", kelas)
+    }
+    if mapel := c.Query("mata_pelajaran"); mapel != "" {
+        query = query.Where("lower(mata_pelajaran) = lower(?)", mapel)
+    }
+    if tahunAjaran := c.Query("tahun_ajaran"); tahunAjaran != "" {
+        query = query.Where("tahun_ajaran = ?", tahunAjaran)
+    }
+    if semester := c.QueryInt("semester"); semester != 0 {
+        query = query.Where("semester = ?", semester)
+    }
+
+    if err := query.Order("tahun_ajaran DESC, semester DESC, kelas ASC, mata_pelajaran ASC").Find(&plans).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch semester plans",
+        })
+    }
+
+    return c.JSON(plans)
+}
+
+// GetSemesterPlan menampilkan satu program semester beserta topiknya
+func GetSemesterPlan(c *fiber.Ctx) error {
+    var plan models.SemesterPlan
+
+    err := database.DB.Preload("Topics", func(db *gorm.DB) *gorm.DB {
+        return db.Order("urutan ASC")
+    }).First(&plan, c.Params("id")).Error
+    if err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Semester plan not found",
+        })
+    }
+
+    return c.JSON(plan)
+}
+
+// CreateSemesterPlan membuat program semester baru
+func CreateSemesterPlan(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var req SemesterPlanRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    plan, err := validateSemesterPlan(req)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    if existing, found := findDuplicatePlan(plan); found {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error":   "Program semester untuk mapel, kelas dan semester ini sudah ada",
+            "plan_id": existing.ID,
+        })
+    }
+
+    plan.CreatedByID = userID
+    for i := range plan.Topics {
+        plan.Topics[i].ID = 0
+    }
+    if err := database.DB.Create(&plan).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create semester plan",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Membuat program semester %s - %s semester %d %s", plan.MataPelajaran, plan.Kelas, plan.Semester, plan.TahunAjaran)
+    createActivity(userEmail, "create", activityDescription)
+
+    return c.Status(fiber.StatusCreated).JSON(plan)
+}
+
+// UpdateSemesterPlan mengganti isi program semester termasuk seluruh topiknya. Topik yang
+// dikirim dengan id diperbarui di tempat sehingga pasangan lesson-nya tetap; topik yang tidak
+// dikirim lagi dihapus dan lesson yang terpasang ke topik itu dilepas agar dicocokkan ulang.
+func UpdateSemesterPlan(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var req SemesterPlanRequest
+
+    existing, ok, err := loadOwnedPlan(c)
+    if !ok {
+        return err
+    }
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    plan, err := validateSemesterPlan(req)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+    plan.ID = existing.ID
+    plan.CreatedAt = existing.CreatedAt
+    plan.CreatedByID = existing.CreatedByID
+
+    if duplicate, found := findDuplicatePlan(plan); found {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error":   "Program semester untuk mapel, kelas dan semester ini sudah ada",
+            "plan_id": duplicate.ID,
+        })
+    }
+
+    var existingTopicIDs []uint
+    if err := database.DB.Model(&models.PlannedTopic{}).Where("plan_id = ?", plan.ID).Pluck("id", &existingTopicIDs).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update semester plan",
+        })
+    }
+    ownTopic := map[uint]bool{}
+    for _, id := range existingTopicIDs {
+        ownTopic[id] = true
+    }
+
+    kept := map[uint]bool{}
+    for i, topic := range plan.Topics {
+        if topic.ID == 0 {
+            continue
+        }
+        if !ownTopic[topic.ID] || kept[topic.ID] {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("topics[%d]: id %d bukan topik program semester ini", i, topic.ID),
+            })
+        }
+        kept[topic.ID] = true
+    }
+    removed := []uint{}
+    for _, id := range existingTopicIDs {
+        if !kept[id] {
+            removed = append(removed, id)
+        }
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if len(removed) > 0 {
+            if err := tx.Model(&models.DailyLesson{}).Where("planned_topic_id IN ?", removed).
+                Update("planned_topic_id", nil).Error; err != nil {
+                return err
+            }
+            if err := tx.Unscoped().Where("id IN ?", removed).Delete(&models.PlannedTopic{}).Error; err != nil {
+                return err
+            }
+        }
+        if err := tx.Omit(clause.Associations).Save(&plan).Error; err != nil {
+            return err
+        }
+
+        for i := range plan.Topics {
+            topic := &plan.Topics[i]
+            topic.PlanID = plan.ID
+            if topic.ID == 0 {
+                if err := tx.Create(topic).Error; err != nil {
+                    return err
+                }
+                continue
+            }
+            err := tx.Model(topic).Select("urutan", "topik", "minggu_target", "tujuan_pembelajaran_id").Updates(topic).Error
+            if err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update semester plan",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Memperbarui program semester %s - %s semester %d %s", plan.MataPelajaran, plan.Kelas, plan.Semester, plan.TahunAjaran)
+    createActivity(userEmail, "update", activityDescription)
+
+    return c.JSON(plan)
+}
+
+// DeleteSemesterPlan menghapus program semester dan melepas lesson yang terpasang ke topiknya
+func DeleteSemesterPlan(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    plan, ok, err := loadOwnedPlan(c)
+    if !ok {
+        return err
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        topics := tx.Model(&models.PlannedTopic{}).Select("id").Where("plan_id = ?", plan.ID)
+        if err := tx.Model(&models.DailyLesson{}).Where("planned_topic_id IN (?)", topics).
+            Update("planned_topic_id", nil).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("plan_id = ?", plan.ID).Delete(&models.PlannedTopic{}).Error; err != nil {
+            return err
+        }
+        return tx.Delete(&plan).Error
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete semester plan",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Menghapus program semester %s - %s semester %d %s", plan.MataPelajaran, plan.Kelas, plan.Semester, plan.TahunAjaran)
+    createActivity(userEmail, "delete", activityDescription)
+
+    return c.JSON(fiber.Map{
+        "message": "Semester plan deleted successfully",
+    })
+}
+
+// SetLessonTopic memasangkan lesson ke topik program semester secara manual, atau melepasnya dengan null
+func SetLessonTopic(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+    var req LessonTopicRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&lesson, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson record not found",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Access denied",
+        })
+    }
+
+    description := "Melepas topik program semester"
+    if req.PlannedTopicID != nil {
+        var topic models.PlannedTopic
+        var plan models.SemesterPlan
+        if err := database.DB.First(&topic, *req.PlannedTopicID).Error; err != nil {
+            return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+                "error": "Planned topic not found",
+            })
+        }
+        if err := database.DB.First(&plan, topic.PlanID).Error; err != nil ||
+            !strings.EqualFold(plan.Kelas, lesson.Kelas) || !strings.EqualFold(plan.MataPelajaran, lesson.MataPelajaran) {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "Topik bukan bagian dari program semester kelas dan mapel lesson ini",
+            })
+        }
+        description = fmt.Sprintf("Memasangkan topik program semester: %s", topic.Topik)
+    }
+
+    before := lesson.Snapshot()
+    if err := database.DB.Model(&lesson).Update("planned_topic_id", req.PlannedTopicID).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update lesson record",
+        })
+    }
+    lesson.PlannedTopicID = req.PlannedTopicID
+    recordLessonHistory(lesson, &before, "SET_TOPIC", description, userID)
+
+    activityDescription := fmt.Sprintf("%s pada lesson: %s - %s (%s)", description, lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru)
+    createActivity(userEmail, "update", activityDescription)
+
+    return c.JSON(lesson)
+}
+
+// minTopicMatchLength adalah panjang minimal teks yang dicocokkan antara topik dan pokok materi,
+// agar materi pendek seperti "bab 1" atau "ulangan" tidak cocok dengan sembarang topik
+const minTopicMatchLength = 5
+
+// matchLessonTopic mencari topik untuk lesson tanpa pasangan manual: pertama lewat TP yang ditandai,
+// lalu lewat nama topik yang muncul utuh (per kata) di pokok materi atau sebaliknya.
+// Nilai -1 berarti tidak cocok.
+func matchLessonTopic(lesson models.DailyLesson, topics []models.PlannedTopic) int {
+    for i, topic := range topics {
+        if topic.TujuanPembelajaranID == nil {
+            continue
+        }
+        for _, tp := range lesson.TujuanPembelajaran {
+            if tp.ID == *topic.TujuanPembelajaranID {
+                return i
+            }
+        }
+    }
+
+    materi := normalizeTopicText(lesson.PokokMateri)
+    if materi == "" {
+        return -1
+    }
+    for i, topic := range topics {
+        topik := normalizeTopicText(topic.Topik)
+        if containsPhrase(materi, topik) || containsPhrase(topik, materi) {
+            return i
+        }
+    }
+    return -1
+}
+
+// normalizeTopicText mengubah teks menjadi huruf kecil dengan kata dipisah satu spasi,
+// tanda baca dianggap pemisah kata
+func normalizeTopicText(text string) string {
+    words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+    return strings.Join(words, " ")
+}
+
+// containsPhrase bernilai true jika phrase muncul utuh per kata di dalam text
+func containsPhrase(text, phrase string) bool {
+    if utf8.RuneCountInString(phrase) < minTopicMatchLength {
+        return false
+    }
+    return strings.Contains(" "+text+" ", " "+phrase+" ")
+}
+
+// planProgress menghitung kemajuan program semester per tanggal asOf
+func planProgress(plan models.SemesterPlan, asOf time.Time) (PlanProgress, error) {
+    progress := PlanProgress{
+        PlanID:        plan.ID,
+        MataPelajaran: plan.MataPelajaran,
+        Kelas:         plan.Kelas,
+        TahunAjaran:   plan.TahunAjaran,
+        Semester:      plan.Semester,
+        TotalTopik:    len(plan.Topics),
+        Status:        models.ProgressSesuai,
+        Topics:        make([]TopicProgress, len(plan.Topics)),
+    }
+
+    sort.Slice(plan.Topics, func(i, j int) bool { return plan.Topics[i].Urutan < plan.Topics[j].Urutan })
+    topicIndex := map[uint]int{}
+    for i, topic := range plan.Topics {
+        topicIndex[topic.ID] = i
+        progress.Topics[i] = TopicProgress{
+            ID:           topic.ID,
+            Urutan:       topic.Urutan,
+            Topik:        topic.Topik,
+            MingguTarget: topic.MingguTarget,
+        }
+    }
+
+    if !asOf.Before(plan.TanggalMulai) {
+        progress.MingguBerjalan = int(weekStart(asOf).Sub(weekStart(plan.TanggalMulai)).Hours()/24/7) + 1
+    }
+
+    var lessons []models.DailyLesson
+    if err := database.DB.Preload("TujuanPembelajaran").
+        Where("lower(kelas) = lower(?) AND lower(mata_pelajaran) = lower(?) AND status = ?",
+            plan.Kelas, plan.MataPelajaran, models.StatusTerlaksana).
+        Where("date(tanggal_mengajar) BETWEEN ? AND ?", plan.TanggalMulai.Format("2006-01-02"), asOf.Format("2006-01-02")).
+        Order("tanggal_mengajar ASC").Find(&lessons).Error; err != nil {
+        return progress, err
+    }
+
+    for _, lesson := range lessons {
+        index := -1
+        if lesson.PlannedTopicID != nil {
+            if i, ok := topicIndex[*lesson.PlannedTopicID]; ok {
+                index = i
+            }
+        } else {
+            index = matchLessonTopic(lesson, plan.Topics)
+        }
+        if index < 0 {
+            continue
+        }
+        progress.Topics[index].JumlahPertemuan++
+        progress.Topics[index].Terakhir = lesson.TanggalMengajar.Format("2006-01-02")
+    }
+
+    lastCovered := -1
+    for i, topic := range progress.Topics {
+        if topic.JumlahPertemuan > 0 {
+            progress.Tercapai++
+            lastCovered = i
+        } else if progress.TopikBerikut == nil {
+            next := progress.Topics[i]
+            progress.TopikBerikut = &next
+        }
+    }
+
+    // Topik yang targetnya minggu ini belum dianggap tertinggal karena minggunya belum selesai
+    switch {
+    case progress.TopikBerikut != nil && progress.TopikBerikut.MingguTarget < progress.MingguBerjalan:
+        progress.Status = models.ProgressTertinggal
+        progress.GapMinggu = progress.TopikBerikut.MingguTarget - progress.MingguBerjalan
+    case lastCovered >= 0 && progress.Topics[lastCovered].MingguTarget > progress.MingguBerjalan:
+        progress.Status = models.ProgressLebihCepat
+        progress.GapMinggu = progress.Topics[lastCovered].MingguTarget - progress.MingguBerjalan
+    }
+
+    return progress, nil
+}
+
+// progressDate membaca parameter as_of (YYYY-MM-DD), default hari ini
+func progressDate(c *fiber.Ctx) (time.Time, error) {
+    asOf := c.Query("as_of")
+    if asOf == "" {
+        now := time.Now()
+        return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+    }
+    parsed, err := time.Parse("2006-01-02", asOf)
+    if err != nil {
+        return parsed, fmt.Errorf("as_of harus berformat YYYY-MM-DD")
+    }
+    return parsed, nil
+}
+
+// GetSemesterPlanProgress menampilkan kemajuan satu program semester per topik
+func GetSemesterPlanProgress(c *fiber.Ctx) error {
+    var plan models.SemesterPlan
+
+    asOf, err := progressDate(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    if err := database.DB.Preload("Topics").First(&plan, c.Params("id")).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Semester plan not found",
+        })
+    }
+
+    progress, err := planProgress(plan, asOf)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not calculate syllabus progress",
+        })
+    }
+
+    return c.JSON(progress)
+}
+
+// GetSyllabusProgressReport menampilkan ringkasan kemajuan seluruh program semester
+// pada tahun ajaran dan semester tertentu, diurutkan dari yang paling tertinggal
+func GetSyllabusProgressReport(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var plans []models.SemesterPlan
+
+    asOf, err := progressDate(c)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query := database.DB.Preload("Topics").
+        Where("date(tanggal_mulai) <= ?", asOf.Format("2006-01-02")).
+        Where("tahun_ajaran = ?", c.Query("tahun_ajaran", models.TahunAjaranOf(asOf)))
+    if semester := c.QueryInt("semester"); semester != 0 {
+        query = query.Where("semester = ?", semester)
+    }
+    if kelas := c.Query("kelas"); kelas != "" {
+        query = query.Where("lower(kelas) = lower(?)", kelas)
+    }
+    if mapel := c.Query("mata_pelajaran"); mapel != "" {
+        query = query.Where("lower(mata_pelajaran) = lower(?)", mapel)
+    }
+
+    if err := query.Find(&plans).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch semester plans",
+        })
+    }
+
+    report := make([]PlanProgress, 0, len(plans))
+    for _, plan := range plans {
+        progress, err := planProgress(plan, asOf)
+        if err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not calculate syllabus progress",
+            })
+        }
+        progress.Topics = nil
+        report = append(report, progress)
+    }
+    sort.SliceStable(report, func(i, j int) bool { return report[i].GapMinggu < report[j].GapMinggu })
+
+    createActivity(userEmail, "view_report", fmt.Sprintf("Melihat progres program semester per %s", asOf.Format("2006-01-02")))
+
+    return c.JSON(fiber.Map{
+        "as_of": asOf.Format("2006-01-02"),
+        "data":  report,
+    })
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+    "bytes"
+    "fmt"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestUpdateSemesterPlanKeepsPairingsOfSurvivingTopics(t *testing.T) {
+    setupTestDB(t)
+    admin := createTestUser(t, "Admin", models.RoleAdmin)
+
+    plan := models.SemesterPlan{
+        MataPelajaran:  "Matematika",
+        Kelas:          "7A",
+        TahunAjaran:    "2026/2027",
+        Semester:       1,
+        TanggalMulai:   testDate("2026-07-13"),
+        TanggalSelesai: testDate("2026-12-18"),
+        CreatedByID:    admin.ID,
+        Topics: []models.PlannedTopic{
+            {Urutan: 1, Topik: "Bilangan bulat", MingguTarget: 1},
+            {Urutan: 2, Topik: "Pecahan", MingguTarget: 3},
+        },
+    }
+    if err := database.DB.Create(&plan).Error; err != nil {
+        t.Fatalf("create plan: %v", err)
+    }
+    kept, removed := plan.Topics[0].ID, plan.Topics[1].ID
+    keptLesson := createTestLesson(t, models.DailyLesson{Kelas: "7A", TanggalMengajar: testDate("2026-07-14"), CreatedByID: admin.ID, PlannedTopicID: &kept})
+    removedLesson := createTestLesson(t, models.DailyLesson{Kelas: "7A", TanggalMengajar: testDate("2026-07-28"), CreatedByID: admin.ID, PlannedTopicID: &removed})
+
+    app := fiber.New()
+    app.Put("/semester-plans/:id", func(c *fiber.Ctx) error {
+        c.Locals("userID", admin.ID)
+        c.Locals("role", string(models.RoleAdmin))
+        c.Locals("email", "admin@sekolah.test")
+        return UpdateSemesterPlan(c)
+    })
+
+    body := fmt.Sprintf(`{"mata_pelajaran":"Matematika","kelas":"7A","tahun_ajaran":"2026/2027","semester":1,
+        "tanggal_mulai":"2026-07-13","tanggal_selesai":"2026-12-18",
+        "topics":[{"id":%d,"topik":"Bilangan bulat dan operasinya","minggu_target":2},{"topik":"Aljabar","minggu_target":4}]}`, kept)
+    req := httptest.NewRequest("PUT", fmt.Sprintf("/semester-plans/%d", plan.ID), bytes.NewBufferString(body))
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    if resp.StatusCode != fiber.StatusOK {
+        t.Fatalf("status = %d, want 200", resp.StatusCode)
+    }
+
+    var topics []models.PlannedTopic
+    database.DB.Where("plan_id = ?", plan.ID).Order("urutan ASC").Find(&topics)
+    if len(topics) != 2 || topics[0].ID != kept || topics[0].Topik != "Bilangan bulat dan operasinya" || topics[0].MingguTarget != 2 {
+        t.Fatalf("topics = %+v, want kept topic %d updated in place plus one new topic", topics, kept)
+    }
+
+    var lesson, released models.DailyLesson
+    database.DB.First(&lesson, keptLesson.ID)
+    if lesson.PlannedTopicID == nil || *lesson.PlannedTopicID != kept {
+        t.Errorf("lesson on surviving topic has planned_topic_id %v, want %d", lesson.PlannedTopicID, kept)
+    }
+    database.DB.First(&released, removedLesson.ID)
+    if released.PlannedTopicID != nil {
+        t.Errorf("lesson on removed topic still has planned_topic_id %d", *released.PlannedTopicID)
+    }
+
+    // Topik dari program lain tidak boleh dipakai
+    body = `{"mata_pelajaran":"Matematika","kelas":"7A","tahun_ajaran":"2026/2027","semester":1,
+        "tanggal_mulai":"2026-07-13","tanggal_selesai":"2026-12-18","topics":[{"id":9999,"topik":"Aljabar","minggu_target":1}]}`
+    req = httptest.NewRequest("PUT", fmt.Sprintf("/semester-plans/%d", plan.ID), bytes.NewBufferString(body))
+    req.Header.Set("Content-Type", "application/json")
+    if resp, _ := app.Test(req); resp.StatusCode != fiber.StatusBadRequest {
+        t.Errorf("unknown topic id status = %d, want 400", resp.StatusCode)
+    }
+}
+
+func TestMatchLessonTopic(t *testing.T) {
+    topics := []models.PlannedTopic{
+        {Topik: "Bilangan bulat"},
+        {Topik: "Pecahan dan desimal"},
+        {Topik: "Persamaan linear satu variabel"},
+    }
+
+    cases := []struct {
+        materi string
+        want   int
+    }{
+        {"Pecahan dan desimal: penjumlahan", 1},
+        {"Latihan soal persamaan linear satu variabel", 2},
+        {"Pecahan", 1},
+        {"bab", -1},
+        {"satu", -1},
+        {"Bilanganbulat", -1},
+        {"Ulangan", -1},
+        {"Bilangan", 0},
+        {"", -1},
+    }
+    for _, tc := range cases {
+        got := matchLessonTopic(models.DailyLesson{PokokMateri: tc.materi}, topics)
+        if got != tc.want {
+            t.Errorf("matchLessonTopic(%q) = %d, want %d", tc.materi, got, tc.want)
+        }
+    }
+}
@@ -0,0 +1,489 @@
+package handlers
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+var tahunAjaranPattern = regexp.MustCompile(`^(\d{4})/(\d{4})$`)
+
+type StudentRequest struct {
+    NIS          string `json:"nis"`
+    NISN         string `json:"nisn"`
+    Nama         string `json:"nama"`
+    JenisKelamin string `json:"jenis_kelamin"`
+    Kelas        string `json:"kelas"`
+    TahunAjaran  string `json:"tahun_ajaran"`
+    Aktif        *bool  `json:"aktif"`
+}
+
+type PromotionClass struct {
+    FromKelas string `json:"from_kelas"`
+    ToKelas   string `json:"to_kelas"`
+    Lulus     bool   `json:"lulus"`
+}
+
+type PromoteStudentsRequest struct {
+    FromTahunAjaran string           `json:"from_tahun_ajaran"`
+    ToTahunAjaran   string           `json:"to_tahun_ajaran"`
+    Classes         []PromotionClass `json:"classes"`
+    TinggalKelas    []uint           `json:"tinggal_kelas"`
+}
+
+// validTahunAjaran memeriksa format tahun ajaran 2025/2026
+func validTahunAjaran(value string) bool {
+    match := tahunAjaranPattern.FindStringSubmatch(value)
+    if match == nil {
+        return false
+    }
+    start, _ := strconv.Atoi(match[1])
+    end, _ := strconv.Atoi(match[2])
+    return end == start+1
+}
+
+// normalizeGender menerima L/P maupun laki-laki/perempuan
+func normalizeGender(value string) (string, bool) {
+    switch strings.ToLower(strings.TrimSpace(value)) {
+    case "l", "laki-laki", "laki laki", "pria":
+        return models.GenderLaki, true
+    case "p", "perempuan", "wanita":
+        return models.GenderPerempuan, true
+    }
+    return "", false
+}
+
+// normalizeNISN mengembalikan 10 digit NISN; nol di depan yang hilang karena sel angka XLSX ditambahkan lagi
+func normalizeNISN(value string) (string, bool) {
+    value = strings.TrimSpace(value)
+    if value == "" {
+        return "", true
+    }
+    if _, err := strconv.ParseUint(value, 10, 64); err != nil || len(value) > 10 {
+        return "", false
+    }
+    return fmt.Sprintf("%010s", value), true
+}
+
+// validateStudent menormalkan dan memeriksa data siswa dari request atau baris import
+func validateStudent(req *StudentRequest) []string {
+    var errs []string
+
+    req.NIS = strings.TrimSpace(req.NIS)
+    req.Nama = strings.TrimSpace(req.Nama)
+    req.Kelas = strings.TrimSpace(req.Kelas)
+    req.TahunAjaran = strings.TrimSpace(req.TahunAjaran)
+
+    if req.NIS == "" {
+        errs = append(errs, "nis wajib diisi")
+    } else if len(req.NIS) > 20 {
+        errs = append(errs, "nis maksimal 20 karakter")
+    }
+    if req.Nama == "" {
+        errs = append(errs, "nama wajib diisi")
+    }
+    if req.Kelas == "" {
+        errs = append(errs, "kelas wajib diisi")
+    }
+
+    nisn, ok := normalizeNISN(req.NISN)
+    if !ok {
+        errs = append(errs, "nisn harus berupa 10 digit angka")
+    }
+    req.NISN = nisn
+
+    if req.JenisKelamin != "" {
+        gender, ok := normalizeGender(req.JenisKelamin)
+        if !ok {
+            errs = append(errs, "jenis_kelamin harus L atau P")
+        }
+        req.JenisKelamin = gender
+    }
+
+    if req.TahunAjaran == "" {
+        req.TahunAjaran = models.TahunAjaranOf(time.Now())
+    } else if !validTahunAjaran(req.TahunAjaran) {
+        errs = append(errs, "tahun_ajaran harus berformat 2025/2026")
+    }
+
+    return errs
+}
+
+// valueOr mengembalikan fallback jika value kosong (field yang tidak dikirim saat update)
+func valueOr(value, fallback string) string {
+    if strings.TrimSpace(value) == "" {
+        return fallback
+    }
+    return value
+}
+
+// saveMembership mencatat kelas siswa pada satu tahun ajaran, mengganti kelas jika sudah ada
+func saveMembership(tx *gorm.DB, studentID uint, tahunAjaran, kelas string) error {
+    var membership models.ClassMembership
+    err := tx.Unscoped().Where("student_id = ? AND tahun_ajaran = ?", studentID, tahunAjaran).First(&membership).Error
+    if err != nil && err != gorm.ErrRecordNotFound {
+        return err
+    }
+
+    membership.StudentID = studentID
+    membership.TahunAjaran = tahunAjaran
+    membership.Kelas = kelas
+    membership.DeletedAt = gorm.DeletedAt{}
+    return tx.Unscoped().Save(&membership).Error
+}
+
+// GetStudents menampilkan roster siswa, bisa difilter per kelas, tahun ajaran dan nama/NIS
+func GetStudents(c *fiber.Ctx) error {
+    pagination, err := parsePagination(c, []string{"id", "nis", "nama", "kelas"}, "nama", 50)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+    if c.Query("order") == "" {
+        pagination.Order = "asc"
+    }
+
+    query := database.DB.Model(&models.Student{})
+
+    kelas := c.Query("kelas")
+    if tahunAjaran := c.Query("tahun_ajaran"); tahunAjaran != "" {
+        // Kelas dicocokkan dengan kelas pada tahun ajaran tersebut, bukan kelas terakhir
+        membership := database.DB.Model(&models.ClassMembership{}).Select("student_id").Where("tahun_ajaran = ?", tahunAjaran)
+        if kelas != "" {
+            membership = membership.Where("lower(kelas) = lower(?)", kelas)
+        }
+        query = query.Where("id IN (?)", membership)
+    } else if kelas != "" {
+        query = query.Where("lower(kelas) = lower(?)", kelas)
+    }
+    if q := c.Query("q"); q != "" {
+        query = query.Where("nama LIKE ? OR nis = ? OR nisn = ?", "%"+q+"%", q, q)
+    }
+    if !c.QueryBool("include_inactive", false) {
+        query = query.Where("aktif = ?", true)
+    }
+
+    students, meta, err := paginate(c, query, pagination, func(student models.Student) uint {
+        return student.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch students",
+        })
+    }
+
+    return c.JSON(paginatedResponse(students, meta))
+}
+
+// GetStudent menampilkan satu siswa beserta riwayat kelasnya
+func GetStudent(c *fiber.Ctx) error {
+    var student models.Student
+
+    err := database.DB.Preload("Memberships", func(db *gorm.DB) *gorm.DB {
+        return db.Order("tahun_ajaran ASC")
+    }).First(&student, c.Params("id")).Error
+    if err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Student not found",
+        })
+    }
+
+    return c.JSON(student)
+}
+
+// CreateStudent menambahkan siswa baru ke roster kelas pada tahun ajaran yang diberikan
+func CreateStudent(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    var req StudentRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if errs := validateStudent(&req); len(errs) > 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": strings.Join(errs, "; "),
+        })
+    }
+
+    var existing models.Student
+    if err := database.DB.Unscoped().Where("nis = ?", req.NIS).First(&existing).Error; err == nil {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error":      fmt.Sprintf("NIS %s sudah terdaftar", req.NIS),
+            "student_id": existing.ID,
+        })
+    }
+
+    student := models.Student{
+        NIS:          req.NIS,
+        NISN:         req.NISN,
+        Nama:         req.Nama,
+        JenisKelamin: req.JenisKelamin,
+        Kelas:        req.Kelas,
+        Aktif:        true,
+    }
+
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&student).Error; err != nil {
+            return err
+        }
+        return saveMembership(tx, student.ID, req.TahunAjaran, student.Kelas)
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create student",
+        })
+    }
+
+    createActivity(userEmail, "create", fmt.Sprintf("Menambahkan siswa: %s (%s) ke kelas %s", student.Nama, student.NIS, student.Kelas))
+
+    return c.Status(fiber.StatusCreated).JSON(student)
+}
+
+// UpdateStudent mengubah data siswa. Perubahan kelas dicatat pada tahun ajaran yang diberikan
+// (default tahun ajaran berjalan).
+func UpdateStudent(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userEmail := c.Locals("email").(string)
+    var student models.Student
+    var req StudentRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&student, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Student not found",
+        })
+    }
+
+    kelasChanged := strings.TrimSpace(req.Kelas) != "" && strings.TrimSpace(req.Kelas) != student.Kelas
+    merged := StudentRequest{
+        NIS:          valueOr(req.NIS, student.NIS),
+        NISN:         valueOr(req.NISN, student.NISN),
+        Nama:         valueOr(req.Nama, student.Nama),
+        JenisKelamin: valueOr(req.JenisKelamin, student.JenisKelamin),
+        Kelas:        valueOr(req.Kelas, student.Kelas),
+        TahunAjaran:  req.TahunAjaran,
+    }
+
+    if errs := validateStudent(&merged); len(errs) > 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": strings.Join(errs, "; "),
+        })
+    }
+
+    if merged.NIS != student.NIS {
+        var existing models.Student
+        if err := database.DB.Unscoped().Where("nis = ? AND id <> ?", merged.NIS, student.ID).First(&existing).Error; err == nil {
+            return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+                "error":      fmt.Sprintf("NIS %s sudah terdaftar", merged.NIS),
+                "student_id": existing.ID,
+            })
+        }
+    }
+
+    student.NIS = merged.NIS
+    student.NISN = merged.NISN
+    student.Nama = merged.Nama
+    student.JenisKelamin = merged.JenisKelamin
+    student.Kelas = merged.Kelas
+    if req.Aktif != nil {
+        student.Aktif = *req.Aktif
+    }
+
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Save(&student).Error; err != nil {
+            return err
+        }
+        if kelasChanged || req.TahunAjaran != "" {
+            return saveMembership(tx, student.ID, merged.TahunAjaran, student.Kelas)
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update student",
+        })
+    }
+
+    createActivity(userEmail, "update", fmt.Sprintf("Memperbarui data siswa: %s (%s)", student.Nama, student.NIS))
+
+    return c.JSON(student)
+}
+
+// DeleteStudent menghapus siswa dari roster; data kehadiran lama tetap tersimpan
+func DeleteStudent(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userEmail := c.Locals("email").(string)
+    var student models.Student
+
+    if err := database.DB.First(&student, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Student not found",
+        })
+    }
+
+    if err := database.DB.Delete(&student).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not delete student",
+        })
+    }
+
+    createActivity(userEmail, "delete", fmt.Sprintf("Menghapus siswa: %s (%s)", student.Nama, student.NIS))
+
+    return c.JSON(fiber.Map{
+        "message": "Student deleted successfully",
+    })
+}
+
+// PromoteStudents menaikkan siswa aktif ke kelas baru pada tahun ajaran berikutnya.
+// Kelas dengan lulus=true diluluskan dan siswanya dinonaktifkan, kelas lain wajib punya to_kelas;
+// siswa di tinggal_kelas tetap di kelas lamanya.
+// Siswa yang sudah punya kelas di tahun ajaran tujuan dilewati. Dengan dry_run=true tidak ada yang disimpan.
+func PromoteStudents(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    dryRun := c.QueryBool("dry_run", false)
+    var req PromoteStudentsRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if !validTahunAjaran(req.FromTahunAjaran) || !validTahunAjaran(req.ToTahunAjaran) || req.ToTahunAjaran <= req.FromTahunAjaran {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "from_tahun_ajaran dan to_tahun_ajaran harus berformat 2025/2026 dan tahun tujuan harus setelah tahun asal",
+        })
+    }
+    if len(req.Classes) == 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "classes wajib diisi",
+        })
+    }
+
+    targetByClass := map[string]string{}
+    fromClasses := []string{}
+    for _, class := range req.Classes {
+        from := strings.ToLower(strings.TrimSpace(class.FromKelas))
+        if from == "" {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "from_kelas wajib diisi",
+            })
+        }
+        if _, ok := targetByClass[from]; ok {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("Kelas %s disebut lebih dari sekali", class.FromKelas),
+            })
+        }
+        to := strings.TrimSpace(class.ToKelas)
+        if class.Lulus && to != "" {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("Kelas %s ditandai lulus sehingga to_kelas harus kosong", class.FromKelas),
+            })
+        }
+        if !class.Lulus && to == "" {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": fmt.Sprintf("to_kelas wajib diisi untuk kelas %s, atau isi lulus true untuk meluluskan kelas", class.FromKelas),
+            })
+        }
+        targetByClass[from] = to
+        fromClasses = append(fromClasses, from)
+    }
+    tinggalKelas := map[uint]bool{}
+    for _, id := range req.TinggalKelas {
+        tinggalKelas[id] = true
+    }
+
+    var memberships []models.ClassMembership
+    if err := database.DB.
+        Joins("JOIN students ON students.id = class_memberships.student_id AND students.deleted_at IS NULL AND students.aktif = ?", true).
+        Where("class_memberships.tahun_ajaran = ? AND lower(class_memberships.kelas) IN ?", req.FromTahunAjaran, fromClasses).
+        Find(&memberships).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch class memberships",
+        })
+    }
+
+    var alreadyPlaced []uint
+    if err := database.DB.Model(&models.ClassMembership{}).
+        Where("tahun_ajaran = ?", req.ToTahunAjaran).Pluck("student_id", &alreadyPlaced).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch class memberships",
+        })
+    }
+    placed := map[uint]bool{}
+    for _, id := range alreadyPlaced {
+        placed[id] = true
+    }
+
+    promoted, repeated, graduated, skipped := 0, 0, 0, []uint{}
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        for _, membership := range memberships {
+            if placed[membership.StudentID] {
+                skipped = append(skipped, membership.StudentID)
+                continue
+            }
+
+            target := targetByClass[strings.ToLower(membership.Kelas)]
+            updates := map[string]interface{}{}
+            switch {
+            case tinggalKelas[membership.StudentID]:
+                target = membership.Kelas
+                repeated++
+            case target == "":
+                updates["aktif"] = false
+                graduated++
+            default:
+                promoted++
+            }
+
+            if dryRun {
+                continue
+            }
+            if target != "" {
+                updates["kelas"] = target
+                if err := saveMembership(tx, membership.StudentID, req.ToTahunAjaran, target); err != nil {
+                    return err
+                }
+            }
+            if err := tx.Model(&models.Student{}).Where("id = ?", membership.StudentID).Updates(updates).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not promote students",
+        })
+    }
+
+    if !dryRun {
+        activityDescription := fmt.Sprintf("Kenaikan kelas %s ke %s: %d naik, %d tinggal kelas, %d lulus",
+            req.FromTahunAjaran, req.ToTahunAjaran, promoted, repeated, graduated)
+        createActivity(userEmail, "promote", activityDescription)
+    }
+
+    return c.JSON(fiber.Map{
+        "dry_run":       dryRun,
+        "naik_kelas":    promoted,
+        "tinggal_kelas": repeated,
+        "lulus":         graduated,
+        "skipped_ids":   skipped,
+    })
+}
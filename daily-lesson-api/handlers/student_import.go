@@ -0,0 +1,250 @@
+package handlers
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+    "daily-lesson-api/utils"
+)
+
+// studentColumnAliases memetakan judul kolom (setelah NormalizeHeader) ke field StudentRequest
+var studentColumnAliases = map[string]string{
+    "nis":           "nis",
+    "no_induk":      "nis",
+    "nisn":          "nisn",
+    "nama":          "nama",
+    "nama_siswa":    "nama",
+    "nama_lengkap":  "nama",
+    "jenis_kelamin": "jenis_kelamin",
+    "jk":            "jenis_kelamin",
+    "l_p":           "jenis_kelamin",
+    "gender":        "jenis_kelamin",
+    "kelas":         "kelas",
+    "rombel":        "kelas",
+    "tahun_ajaran":  "tahun_ajaran",
+}
+
+// ImportStudents mengimpor roster siswa dari file CSV/XLSX. Siswa dicocokkan berdasarkan NIS:
+// NIS yang sudah ada diperbarui (termasuk yang pernah dihapus), NIS baru ditambahkan.
+// tahun_ajaran per baris boleh kosong dan memakai query tahun_ajaran atau tahun ajaran berjalan.
+func ImportStudents(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+    dryRun := c.QueryBool("dry_run", false)
+    defaultTahunAjaran := c.Query("tahun_ajaran")
+
+    if defaultTahunAjaran != "" && !validTahunAjaran(defaultTahunAjaran) {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "tahun_ajaran harus berformat 2025/2026",
+        })
+    }
+
+    file, err := c.FormFile("file")
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "File CSV/XLSX wajib diunggah (field: file)",
+        })
+    }
+
+    src, err := file.Open()
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Could not read uploaded file",
+        })
+    }
+    defer src.Close()
+
+    rows, err := utils.ReadSpreadsheet(file.Filename, src)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    headerIndex := findHeaderRow(rows, studentColumnAliases)
+    if len(rows) < headerIndex+2 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "File harus berisi baris judul kolom dan minimal satu baris data",
+        })
+    }
+
+    if len(rows)-headerIndex-1 > maxImportRows {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": fmt.Sprintf("Maksimal %d baris per import", maxImportRows),
+        })
+    }
+
+    columns := map[int]string{}
+    found := map[string]bool{}
+    for index, header := range rows[headerIndex] {
+        if field, ok := studentColumnAliases[utils.NormalizeHeader(header)]; ok {
+            columns[index] = field
+            found[field] = true
+        }
+    }
+
+    var missing []string
+    for _, field := range []string{"nis", "nama", "kelas"} {
+        if !found[field] {
+            missing = append(missing, field)
+        }
+    }
+    if len(missing) > 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": fmt.Sprintf("Kolom wajib tidak ditemukan: %s", strings.Join(missing, ", ")),
+        })
+    }
+
+    rowErrors := []ImportRowError{}
+    var students []StudentRequest
+    rowOfNIS := map[string]int{}
+
+    for i, row := range rows[headerIndex+1:] {
+        rowNumber := headerIndex + i + 2
+        if isBlankRow(row) {
+            continue
+        }
+
+        values := map[string]string{}
+        for index, field := range columns {
+            if index < len(row) {
+                values[field] = strings.TrimSpace(row[index])
+            }
+        }
+
+        req := StudentRequest{
+            NIS:          values["nis"],
+            NISN:         values["nisn"],
+            Nama:         values["nama"],
+            JenisKelamin: values["jenis_kelamin"],
+            Kelas:        values["kelas"],
+            TahunAjaran:  valueOr(values["tahun_ajaran"], defaultTahunAjaran),
+        }
+
+        errs := validateStudent(&req)
+        if previous, ok := rowOfNIS[req.NIS]; ok && req.NIS != "" {
+            errs = append(errs, fmt.Sprintf("NIS %s sudah dipakai di baris %d", req.NIS, previous))
+        }
+        if len(errs) > 0 {
+            rowErrors = append(rowErrors, ImportRowError{Row: rowNumber, Errors: errs})
+            continue
+        }
+
+        rowOfNIS[req.NIS] = rowNumber
+        students = append(students, req)
+    }
+
+    nisList := make([]string, len(students))
+    for i, req := range students {
+        nisList[i] = req.NIS
+    }
+    var existing []models.Student
+    if err := database.DB.Unscoped().Where("nis IN ?", nisList).Find(&existing).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not import students",
+        })
+    }
+    existingByNIS := map[string]models.Student{}
+    existingIDs := make([]uint, len(existing))
+    for i, student := range existing {
+        existingByNIS[student.NIS] = student
+        existingIDs[i] = student.ID
+    }
+    latestYear, err := latestMembershipYears(existingIDs)
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not import students",
+        })
+    }
+
+    updated := 0
+    for _, req := range students {
+        if _, ok := existingByNIS[req.NIS]; ok {
+            updated++
+        }
+    }
+
+    result := fiber.Map{
+        "dry_run":      dryRun,
+        "total_rows":   len(students) + len(rowErrors),
+        "valid_rows":   len(students),
+        "invalid_rows": len(rowErrors),
+        "errors":       rowErrors,
+        "created":      len(students) - updated,
+        "updated":      updated,
+    }
+
+    if dryRun || len(students) == 0 {
+        return c.JSON(result)
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        for _, req := range students {
+            student, ok := existingByNIS[req.NIS]
+            if !ok {
+                student = models.Student{NIS: req.NIS}
+            }
+            student.Nama = req.Nama
+            // Kelas siswa hanya mengikuti baris tahun ajaran terbaru; baris tahun lalu cukup
+            // mengisi riwayat ClassMembership
+            if req.TahunAjaran >= latestYear[student.ID] {
+                student.Kelas = req.Kelas
+            }
+            student.Aktif = true
+            student.DeletedAt = gorm.DeletedAt{}
+            if req.NISN != "" {
+                student.NISN = req.NISN
+            }
+            if req.JenisKelamin != "" {
+                student.JenisKelamin = req.JenisKelamin
+            }
+
+            if err := tx.Unscoped().Save(&student).Error; err != nil {
+                return err
+            }
+            if err := saveMembership(tx, student.ID, req.TahunAjaran, req.Kelas); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not import students",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Mengimpor %d siswa dari %s (%d baru, %d diperbarui)",
+        len(students), file.Filename, len(students)-updated, updated)
+    createActivity(userEmail, "import", activityDescription)
+
+    return c.JSON(result)
+}
+
+// latestMembershipYears mengembalikan tahun ajaran terbaru pada riwayat kelas setiap siswa
+func latestMembershipYears(studentIDs []uint) (map[uint]string, error) {
+    years := map[uint]string{}
+    if len(studentIDs) == 0 {
+        return years, nil
+    }
+
+    var rows []struct {
+        StudentID   uint
+        TahunAjaran string
+    }
+    err := database.DB.Model(&models.ClassMembership{}).
+        Select("student_id, max(tahun_ajaran) AS tahun_ajaran").
+        Where("student_id IN ?", studentIDs).
+        Group("student_id").Scan(&rows).Error
+    if err != nil {
+        return nil, err
+    }
+
+    for _, row := range rows {
+        years[row.StudentID] = row.TahunAjaran
+    }
+    return years, nil
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "mime/multipart"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func createTestStudent(t *testing.T, nis, nama, kelas string, memberships map[string]string) models.Student {
+    t.Helper()
+
+    student := models.Student{NIS: nis, Nama: nama, Kelas: kelas, Aktif: true}
+    if err := database.DB.Create(&student).Error; err != nil {
+        t.Fatalf("create student: %v", err)
+    }
+    for tahunAjaran, kelas := range memberships {
+        if err := saveMembership(database.DB, student.ID, tahunAjaran, kelas); err != nil {
+            t.Fatalf("save membership: %v", err)
+        }
+    }
+    return student
+}
+
+func TestMigrateBackfillsClassMemberships(t *testing.T) {
+    setupTestDB(t)
+    current := models.TahunAjaranOf(time.Now())
+    previous := models.TahunAjaranOf(time.Now().AddDate(-1, 0, 0))
+
+    legacy := createTestStudent(t, "1001", "Lama", "7A", nil)
+    placed := createTestStudent(t, "1002", "Terdaftar", "8A", map[string]string{previous: "7B"})
+    deleted := createTestStudent(t, "1003", "Keluar", "7A", nil)
+    database.DB.Delete(&deleted)
+
+    if err := database.Migrate(database.DB); err != nil {
+        t.Fatalf("migrate: %v", err)
+    }
+
+    var memberships []models.ClassMembership
+    database.DB.Order("student_id ASC").Find(&memberships)
+    if len(memberships) != 2 {
+        t.Fatalf("memberships = %+v, want 2", memberships)
+    }
+    if memberships[0].StudentID != legacy.ID || memberships[0].TahunAjaran != current || memberships[0].Kelas != "7A" {
+        t.Fatalf("backfilled membership = %+v, want %s 7A for student %d", memberships[0], current, legacy.ID)
+    }
+    if memberships[1].StudentID != placed.ID || memberships[1].TahunAjaran != previous {
+        t.Fatalf("existing membership changed: %+v", memberships[1])
+    }
+
+    // Migrasi berikutnya tidak membuat baris ganda
+    if err := database.Migrate(database.DB); err != nil {
+        t.Fatalf("migrate again: %v", err)
+    }
+    var count int64
+    database.DB.Model(&models.ClassMembership{}).Count(&count)
+    if count != 2 {
+        t.Fatalf("memberships after second migrate = %d, want 2", count)
+    }
+}
+
+func TestLessonAttendanceUsesRosterOfLessonYear(t *testing.T) {
+    setupTestDB(t)
+    now := time.Now()
+    lastYear := now.AddDate(-1, 0, 0)
+    current := models.TahunAjaranOf(now)
+    previous := models.TahunAjaranOf(lastYear)
+
+    // Siswa A naik dari 7A ke 8A, siswa B masuk 7A tahun ini, siswa C lulus tahun lalu
+    promoted := createTestStudent(t, "2001", "Andi", "8A", map[string]string{previous: "7A", current: "8A"})
+    intake := createTestStudent(t, "2002", "Budi", "7A", map[string]string{current: "7A"})
+    graduated := createTestStudent(t, "2003", "Citra", "9A", map[string]string{previous: "7A"})
+    database.DB.Model(&graduated).Update("aktif", false)
+
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    pastLesson := createTestLesson(t, models.DailyLesson{NamaGuru: "guru", Kelas: "7A", TanggalMengajar: lastYear, CreatedByID: guru.ID})
+    currentLesson := createTestLesson(t, models.DailyLesson{NamaGuru: "guru", Kelas: "7a", TanggalMengajar: now, CreatedByID: guru.ID})
+
+    tests := []struct {
+        name   string
+        lesson models.DailyLesson
+        want   []uint
+    }{
+        {"past lesson keeps that year's class", pastLesson, []uint{promoted.ID, graduated.ID}},
+        {"current lesson uses this year's class", currentLesson, []uint{intake.ID}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            entries, err := lessonAttendance(tt.lesson)
+            if err != nil {
+                t.Fatalf("lessonAttendance: %v", err)
+            }
+            if len(entries) != len(tt.want) {
+                t.Fatalf("entries = %+v, want students %v", entries, tt.want)
+            }
+            for i, id := range tt.want {
+                if entries[i].StudentID != id {
+                    t.Fatalf("entries = %+v, want students %v", entries, tt.want)
+                }
+            }
+        })
+    }
+}
+
+func TestImportStudentsKeepsLatestKelas(t *testing.T) {
+    setupTestDB(t)
+    current := models.TahunAjaranOf(time.Now())
+    previous := models.TahunAjaranOf(time.Now().AddDate(-1, 0, 0))
+
+    student := createTestStudent(t, "3001", "Dewi", "8A", map[string]string{current: "8A"})
+
+    app := fiber.New()
+    app.Post("/students/import", func(c *fiber.Ctx) error {
+        c.Locals("email", "admin@sekolah.test")
+        return ImportStudents(c)
+    })
+
+    importCSV := func(csv string) {
+        t.Helper()
+        var body bytes.Buffer
+        writer := multipart.NewWriter(&body)
+        part, _ := writer.CreateFormFile("file", "siswa.csv")
+        part.Write([]byte(csv))
+        writer.Close()
+
+        req := httptest.NewRequest("POST", "/students/import", &body)
+        req.Header.Set("Content-Type", writer.FormDataContentType())
+        resp, err := app.Test(req)
+        if err != nil {
+            t.Fatalf("request: %v", err)
+        }
+        if resp.StatusCode != fiber.StatusOK {
+            t.Fatalf("status = %d, want 200", resp.StatusCode)
+        }
+    }
+
+    // Riwayat tahun lalu tidak boleh menimpa kelas siswa saat ini
+    importCSV("nis,nama,kelas,tahun_ajaran\n3001,Dewi,7A," + previous + "\n")
+
+    var reloaded models.Student
+    database.DB.First(&reloaded, student.ID)
+    if reloaded.Kelas != "8A" {
+        t.Fatalf("kelas = %q after importing %s, want 8A", reloaded.Kelas, previous)
+    }
+    var history models.ClassMembership
+    if err := database.DB.Where("student_id = ? AND tahun_ajaran = ?", student.ID, previous).First(&history).Error; err != nil || history.Kelas != "7A" {
+        t.Fatalf("membership %s = %+v (%v), want 7A", previous, history, err)
+    }
+
+    // Baris tahun berjalan tetap memperbarui kelas
+    importCSV("nis,nama,kelas,tahun_ajaran\n3001,Dewi,8B," + current + "\n")
+    database.DB.First(&reloaded, student.ID)
+    if reloaded.Kelas != "8B" {
+        t.Fatalf("kelas = %q after importing %s, want 8B", reloaded.Kelas, current)
+    }
+}
+
+func promoteTestStudents(t *testing.T, req PromoteStudentsRequest) (int, []byte) {
+    t.Helper()
+
+    app := fiber.New()
+    app.Post("/students/promote", func(c *fiber.Ctx) error {
+        c.Locals("email", "admin@sekolah.test")
+        return PromoteStudents(c)
+    })
+
+    data, _ := json.Marshal(req)
+    httpReq := httptest.NewRequest("POST", "/students/promote", bytes.NewReader(data))
+    httpReq.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(httpReq)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+
+    var buf bytes.Buffer
+    buf.ReadFrom(resp.Body)
+    return resp.StatusCode, buf.Bytes()
+}
+
+func TestPromoteStudentsRequiresExplicitGraduation(t *testing.T) {
+    setupTestDB(t)
+    seventh := createTestStudent(t, "4001", "Eka", "7A", map[string]string{"2025/2026": "7A"})
+    ninth := createTestStudent(t, "4002", "Fajar", "9A", map[string]string{"2025/2026": "9A"})
+
+    // to_kelas kosong tanpa lulus ditolak sehingga kelas tidak lulus tanpa sengaja
+    for name, class := range map[string]PromotionClass{
+        "empty to_kelas":      {FromKelas: "7A"},
+        "lulus with to_kelas": {FromKelas: "9A", ToKelas: "10A", Lulus: true},
+    } {
+        req := PromoteStudentsRequest{FromTahunAjaran: "2025/2026", ToTahunAjaran: "2026/2027", Classes: []PromotionClass{class}}
+        if status, body := promoteTestStudents(t, req); status != fiber.StatusBadRequest {
+            t.Errorf("%s: status = %d (%s), want 400", name, status, body)
+        }
+    }
+    var active int64
+    database.DB.Model(&models.Student{}).Where("aktif = ?", true).Count(&active)
+    if active != 2 {
+        t.Fatalf("active students = %d after rejected requests, want 2", active)
+    }
+
+    req := PromoteStudentsRequest{
+        FromTahunAjaran: "2025/2026",
+        ToTahunAjaran:   "2026/2027",
+        Classes: []PromotionClass{
+            {FromKelas: "7A", ToKelas: "8A"},
+            {FromKelas: "9A", Lulus: true},
+        },
+    }
+    status, body := promoteTestStudents(t, req)
+    if status != fiber.StatusOK {
+        t.Fatalf("status = %d (%s), want 200", status, body)
+    }
+    var result struct {
+        NaikKelas int `json:"naik_kelas"`
+        Lulus     int `json:"lulus"`
+    }
+    json.Unmarshal(body, &result)
+    if result.NaikKelas != 1 || result.Lulus != 1 {
+        t.Fatalf("result = %+v, want one promoted and one graduated", result)
+    }
+
+    var promoted, graduated models.Student
+    database.DB.First(&promoted, seventh.ID)
+    database.DB.First(&graduated, ninth.ID)
+    if promoted.Kelas != "8A" || !promoted.Aktif {
+        t.Errorf("promoted = %+v, want active in 8A", promoted)
+    }
+    if graduated.Aktif {
+        t.Errorf("graduated = %+v, want inactive", graduated)
+    }
+}
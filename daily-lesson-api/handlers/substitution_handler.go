@@ -0,0 +1,381 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// errStatusChanged menandai data yang statusnya sudah berubah sebelum transaksi sempat menulis
+var errStatusChanged = errors.New("status changed")
+
+type CreateSubstitutionRequest struct {
+    OriginalTeacherID   uint   `json:"original_teacher_id"`
+    SubstituteTeacherID *uint  `json:"substitute_teacher_id"`
+    Tanggal             string `json:"tanggal"`
+    Kelas               string `json:"kelas"`
+    MataPelajaran       string `json:"mata_pelajaran"`
+    JamMulai            string `json:"jam_mulai"`
+    JamSelesai          string `json:"jam_selesai"`
+    Alasan              string `json:"alasan"`
+}
+
+type AssignSubstitutionRequest struct {
+    SubstituteTeacherID uint `json:"substitute_teacher_id"`
+}
+
+type SubstitutionLessonRequest struct {
+    PokokMateri   string `json:"pokok_materi"`
+    BuktiMengajar string `json:"bukti_mengajar"`
+    JamMulai      string `json:"jam_mulai"`
+    JamSelesai    string `json:"jam_selesai"`
+    Status        string `json:"status"`
+    Catatan       string `json:"catatan"`
+}
+
+// findTeacher mengambil user dengan role teacher
+func findTeacher(id uint) (models.User, error) {
+    var teacher models.User
+    err := database.DB.Where("role = ?", models.RoleTeacher).First(&teacher, id).Error
+    return teacher, err
+}
+
+// CreateSubstitution mencatat jam pelajaran yang perlu diisi guru pengganti. Guru hanya bisa
+// membuat untuk dirinya sendiri; admin/supervisor memilih guru lewat original_teacher_id.
+func CreateSubstitution(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var req CreateSubstitutionRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if userRole == "teacher" || req.OriginalTeacherID == 0 {
+        req.OriginalTeacherID = userID
+    }
+
+    original, err := findTeacher(req.OriginalTeacherID)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Guru yang digantikan tidak ditemukan",
+        })
+    }
+
+    tanggal, err := time.Parse("2006-01-02", req.Tanggal)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Format tanggal tidak valid. Gunakan format YYYY-MM-DD",
+        })
+    }
+
+    if req.Kelas == "" || req.MataPelajaran == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Kelas dan mata pelajaran wajib diisi",
+        })
+    }
+
+    substitution := models.Substitution{
+        OriginalTeacherID: original.ID,
+        Tanggal:           tanggal,
+        Kelas:             req.Kelas,
+        MataPelajaran:     req.MataPelajaran,
+        JamMulai:          req.JamMulai,
+        JamSelesai:        req.JamSelesai,
+        Alasan:            req.Alasan,
+        Status:            models.SubstitutionOpen,
+        CreatedByID:       userID,
+    }
+
+    if req.SubstituteTeacherID != nil {
+        substitute, err := findTeacher(*req.SubstituteTeacherID)
+        if err != nil || substitute.ID == original.ID {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "Guru pengganti tidak valid",
+            })
+        }
+        substitution.SubstituteTeacherID = &substitute.ID
+        substitution.Status = models.SubstitutionAssigned
+    }
+
+    if err := database.DB.Create(&substitution).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create substitution",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Membuat jadwal guru pengganti: %s - %s (%s) %s",
+        substitution.MataPelajaran, substitution.Kelas, original.Name, req.Tanggal)
+    createActivity(userEmail, "create", activityDescription)
+
+    database.DB.Preload("OriginalTeacher", userSummaryColumns).Preload("SubstituteTeacher", userSummaryColumns).First(&substitution, substitution.ID)
+    return c.Status(fiber.StatusCreated).JSON(substitution)
+}
+
+// GetSubstitutions menampilkan daftar penggantian guru. Guru melihat penggantian miliknya,
+// yang ia gantikan, dan slot yang masih terbuka.
+func GetSubstitutions(c *fiber.Ctx) error {
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+
+    pagination, err := parsePagination(c, []string{"id", "tanggal", "status", "created_at"}, "tanggal", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query := database.DB.Model(&models.Substitution{}).
+        Preload("OriginalTeacher", userSummaryColumns).
+        Preload("SubstituteTeacher", userSummaryColumns)
+
+    if userRole == "teacher" {
+        query = query.Where("original_teacher_id = ? OR substitute_teacher_id = ? OR status = ?",
+            userID, userID, models.SubstitutionOpen)
+    }
+
+    if status := c.Query("status"); status != "" {
+        query = query.Where("status = ?", status)
+    }
+
+    if startDate, endDate := c.Query("start_date"), c.Query("end_date"); startDate != "" && endDate != "" {
+        query = query.Where("date(tanggal) BETWEEN ? AND ?", startDate, endDate)
+    }
+
+    substitutions, meta, err := paginate(c, query, pagination, func(s models.Substitution) uint {
+        return s.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch substitutions",
+        })
+    }
+
+    return c.JSON(paginatedResponse(substitutions, meta))
+}
+
+// AssignSubstitution menetapkan guru pengganti. Guru hanya bisa mengambil slot terbuka untuk
+// dirinya sendiri; admin/supervisor bisa menunjuk guru lain.
+func AssignSubstitution(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var substitution models.Substitution
+    var req AssignSubstitutionRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.First(&substitution, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Substitution not found",
+        })
+    }
+
+    if substitution.Status == models.SubstitutionDone || substitution.Status == models.SubstitutionCancelled {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Penggantian ini sudah selesai atau dibatalkan",
+        })
+    }
+
+    if userRole == "teacher" {
+        if substitution.Status != models.SubstitutionOpen {
+            return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+                "error": "Slot pengganti ini sudah diisi guru lain",
+            })
+        }
+        req.SubstituteTeacherID = userID
+    }
+
+    substitute, err := findTeacher(req.SubstituteTeacherID)
+    if err != nil || substitute.ID == substitution.OriginalTeacherID {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Guru pengganti tidak valid",
+        })
+    }
+
+    substitution.SubstituteTeacherID = &substitute.ID
+    substitution.Status = models.SubstitutionAssigned
+    if err := database.DB.Save(&substitution).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update substitution",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Menetapkan %s sebagai guru pengganti %s - %s %s",
+        substitute.Name, substitution.MataPelajaran, substitution.Kelas, substitution.Tanggal.Format("2006-01-02"))
+    createActivity(userEmail, "update", activityDescription)
+
+    database.DB.Preload("OriginalTeacher", userSummaryColumns).Preload("SubstituteTeacher", userSummaryColumns).First(&substitution, substitution.ID)
+    return c.JSON(substitution)
+}
+
+// CancelSubstitution membatalkan penggantian yang belum diisi catatan mengajarnya
+func CancelSubstitution(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var substitution models.Substitution
+
+    if err := database.DB.First(&substitution, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Substitution not found",
+        })
+    }
+
+    if userRole == "teacher" && substitution.OriginalTeacherID != userID {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat membatalkan penggantian milik sendiri",
+        })
+    }
+
+    if substitution.Status == models.SubstitutionDone {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Catatan mengajar pengganti sudah diisi",
+        })
+    }
+
+    substitution.Status = models.SubstitutionCancelled
+    if err := database.DB.Save(&substitution).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not update substitution",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Membatalkan guru pengganti %s - %s %s",
+        substitution.MataPelajaran, substitution.Kelas, substitution.Tanggal.Format("2006-01-02"))
+    createActivity(userEmail, "update", activityDescription)
+
+    return c.JSON(substitution)
+}
+
+// FileSubstitutionLesson dipakai guru pengganti untuk mengisi catatan mengajar atas nama guru asli
+func FileSubstitutionLesson(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var substitution models.Substitution
+    var req SubstitutionLessonRequest
+
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Cannot parse JSON",
+        })
+    }
+
+    if err := database.DB.Preload("OriginalTeacher", userSummaryColumns).Preload("SubstituteTeacher", userSummaryColumns).First(&substitution, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Substitution not found",
+        })
+    }
+
+    if substitution.SubstituteTeacherID == nil || substitution.Status != models.SubstitutionAssigned {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Penggantian ini belum memiliki guru pengganti atau sudah selesai",
+        })
+    }
+
+    if userRole == "teacher" && *substitution.SubstituteTeacherID != userID {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Hanya guru pengganti yang dapat mengisi catatan ini",
+        })
+    }
+
+    if req.PokokMateri == "" {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "Pokok materi wajib diisi",
+        })
+    }
+
+    status := req.Status
+    if status == "" {
+        status = models.StatusTerlaksana
+    }
+    if !lessonStatuses[status] {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": fmt.Sprintf("Status %q tidak dikenal", status),
+        })
+    }
+
+    lesson := models.DailyLesson{
+        NamaGuru:        substitution.OriginalTeacher.Name,
+        MataPelajaran:   substitution.MataPelajaran,
+        Kelas:           substitution.Kelas,
+        PokokMateri:     req.PokokMateri,
+        BuktiMengajar:   req.BuktiMengajar,
+        TanggalMengajar: substitution.Tanggal,
+        JamMulai:        substitution.JamMulai,
+        JamSelesai:      substitution.JamSelesai,
+        Status:          status,
+        Catatan:         req.Catatan,
+        CreatedByID:     substitution.OriginalTeacherID,
+        GuruPengganti:   substitution.SubstituteTeacher.Name,
+        GuruPenggantiID: substitution.SubstituteTeacherID,
+        SubstitutionID:  &substitution.ID,
+    }
+    if req.JamMulai != "" {
+        lesson.JamMulai = req.JamMulai
+    }
+    if req.JamSelesai != "" {
+        lesson.JamSelesai = req.JamSelesai
+    }
+
+    conflicts, ok, err := checkScheduleConflicts(c, lesson)
+    if !ok {
+        return err
+    }
+
+    description := fmt.Sprintf("Catatan mengajar diisi guru pengganti %s", lesson.GuruPengganti)
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        // Status dicek ulang di dalam transaksi agar dua pengisian bersamaan tidak sama-sama lolos
+        result := tx.Model(&models.Substitution{}).
+            Where("id = ? AND status = ?", substitution.ID, models.SubstitutionAssigned).
+            Update("status", models.SubstitutionDone)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return errStatusChanged
+        }
+
+        if err := tx.Create(&lesson).Error; err != nil {
+            return err
+        }
+        if err := tx.Model(&models.Substitution{}).Where("id = ?", substitution.ID).Update("lesson_id", lesson.ID).Error; err != nil {
+            return err
+        }
+
+        history := newLessonHistory(lesson, nil, "CREATE_SUBSTITUTE", conflictHistoryNote(description, conflicts), userID)
+        return tx.Create(&history).Error
+    })
+    if errors.Is(err, errStatusChanged) {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Penggantian ini belum memiliki guru pengganti atau sudah selesai",
+        })
+    }
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not create lesson record",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Mengisi lesson sebagai guru pengganti: %s - %s (%s, pengganti %s)",
+        lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru, lesson.GuruPengganti)
+    createActivity(userEmail, "create", activityDescription)
+
+    return c.Status(fiber.StatusCreated).JSON(lesson)
+}
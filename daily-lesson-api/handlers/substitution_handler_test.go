@@ -0,0 +1,129 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func fileSubstitutionRequest(t *testing.T, userID uint, substitutionID uint, body SubstitutionLessonRequest) (int, []byte) {
+    t.Helper()
+
+    app := fiber.New()
+    app.Use(func(c *fiber.Ctx) error {
+        c.Locals("userID", userID)
+        c.Locals("role", string(models.RoleTeacher))
+        c.Locals("email", "guru@sekolah.test")
+        return c.Next()
+    })
+    app.Post("/substitutions/:id/lesson", FileSubstitutionLesson)
+
+    data, _ := json.Marshal(body)
+    req := httptest.NewRequest("POST", fmt.Sprintf("/substitutions/%d/lesson", substitutionID), bytes.NewReader(data))
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+
+    var buf bytes.Buffer
+    buf.ReadFrom(resp.Body)
+    return resp.StatusCode, buf.Bytes()
+}
+
+func createTestSubstitution(t *testing.T, original, substitute models.User, status string) models.Substitution {
+    t.Helper()
+
+    substitution := models.Substitution{
+        OriginalTeacherID:   original.ID,
+        SubstituteTeacherID: &substitute.ID,
+        Tanggal:             testDate("2026-10-05"),
+        Kelas:               "7A",
+        MataPelajaran:       "Matematika",
+        JamMulai:            "07:00",
+        JamSelesai:          "08:30",
+        Alasan:              "Sakit",
+        Status:              status,
+        CreatedByID:         original.ID,
+    }
+    if err := database.DB.Create(&substitution).Error; err != nil {
+        t.Fatalf("create substitution: %v", err)
+    }
+    return substitution
+}
+
+func TestFileSubstitutionLesson(t *testing.T) {
+    setupTestDB(t)
+    guruA := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    guruB := createTestUser(t, "Pak Budi", models.RoleTeacher)
+    guruC := createTestUser(t, "Bu Citra", models.RoleTeacher)
+    substitution := createTestSubstitution(t, guruA, guruB, models.SubstitutionAssigned)
+    req := SubstitutionLessonRequest{PokokMateri: "Pecahan"}
+
+    if status, _ := fileSubstitutionRequest(t, guruC.ID, substitution.ID, req); status != fiber.StatusForbidden {
+        t.Fatalf("other teacher status = %d, want 403", status)
+    }
+
+    status, body := fileSubstitutionRequest(t, guruB.ID, substitution.ID, req)
+    if status != fiber.StatusCreated {
+        t.Fatalf("substitute status = %d (%s), want 201", status, body)
+    }
+    var lesson models.DailyLesson
+    json.Unmarshal(body, &lesson)
+    if lesson.CreatedByID != guruA.ID || lesson.GuruPenggantiID == nil || *lesson.GuruPenggantiID != guruB.ID || lesson.NamaGuru != "Bu Ani" {
+        t.Fatalf("lesson = %+v, want Bu Ani's lesson taught by Pak Budi", lesson)
+    }
+
+    var saved models.Substitution
+    database.DB.First(&saved, substitution.ID)
+    if saved.Status != models.SubstitutionDone || saved.LessonID == nil || *saved.LessonID != lesson.ID {
+        t.Fatalf("substitution = %+v, want selesai linked to lesson %d", saved, lesson.ID)
+    }
+
+    var histories int64
+    database.DB.Model(&models.LessonReport{}).Where("lesson_id = ? AND action = ?", lesson.ID, "CREATE_SUBSTITUTE").Count(&histories)
+    if histories != 1 {
+        t.Errorf("substitute histories = %d, want 1", histories)
+    }
+
+    // Pengisian kedua ditolak dan tidak membuat lesson baru
+    if status, body := fileSubstitutionRequest(t, guruB.ID, substitution.ID, req); status != fiber.StatusConflict {
+        t.Fatalf("second filing status = %d (%s), want 409", status, body)
+    }
+    var lessons int64
+    database.DB.Model(&models.DailyLesson{}).Count(&lessons)
+    if lessons != 1 {
+        t.Errorf("lessons = %d, want 1 after a repeated filing", lessons)
+    }
+}
+
+func TestFileSubstitutionLessonRejectsClosedSubstitution(t *testing.T) {
+    setupTestDB(t)
+    guruA := createTestUser(t, "Bu Ani", models.RoleTeacher)
+    guruB := createTestUser(t, "Pak Budi", models.RoleTeacher)
+
+    for _, status := range []string{models.SubstitutionOpen, models.SubstitutionCancelled} {
+        substitution := createTestSubstitution(t, guruA, guruB, status)
+        if code, body := fileSubstitutionRequest(t, guruB.ID, substitution.ID, SubstitutionLessonRequest{PokokMateri: "Pecahan"}); code != fiber.StatusConflict {
+            t.Errorf("%s: status = %d (%s), want 409", status, code, body)
+        }
+
+        var saved models.Substitution
+        database.DB.First(&saved, substitution.ID)
+        if saved.Status != status || saved.LessonID != nil {
+            t.Errorf("%s: substitution = %+v, want it unchanged", status, saved)
+        }
+    }
+
+    var lessons int64
+    database.DB.Model(&models.DailyLesson{}).Count(&lessons)
+    if lessons != 0 {
+        t.Errorf("lessons = %d, want none", lessons)
+    }
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+    "fmt"
+    "log"
+    "os"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/middleware"
+    "daily-lesson-api/models"
+)
+
+// trashRetentionDays adalah lama lesson disimpan di trash sebelum dihapus permanen
+// (TRASH_RETENTION_DAYS, default 30 hari, 0 untuk menonaktifkan purge otomatis)
+func trashRetentionDays() int {
+    days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+    if err != nil || days < 0 {
+        days = 30
+    }
+    return days
+}
+
+// GetTrash menampilkan lesson yang sudah dihapus (soft delete). Guru hanya melihat miliknya sendiri.
+func GetTrash(c *fiber.Ctx) error {
+    userEmail := c.Locals("email").(string)
+
+    pagination, err := parsePagination(c, []string{"id", "deleted_at", "tanggal_mengajar"}, "deleted_at", 20)
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
+    query := database.DB.Unscoped().Model(&models.DailyLesson{}).
+        Preload("CreatedBy").
+        Where("deleted_at IS NOT NULL")
+    query = filterLessons(c, query)
+
+    lessons, meta, err := paginate(c, query, pagination, func(lesson models.DailyLesson) uint {
+        return lesson.ID
+    })
+    if err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not fetch deleted lessons",
+        })
+    }
+
+    createActivity(userEmail, "view", "Melihat trash lessons")
+
+    response := paginatedResponse(lessons, meta)
+    response["retention_days"] = trashRetentionDays()
+    return c.JSON(response)
+}
+
+// RestoreLesson mengembalikan lesson dari trash
+func RestoreLesson(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userRole := c.Locals("role").(string)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+
+    if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&lesson, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson tidak ditemukan di trash",
+        })
+    }
+
+    if userRole == "teacher" && !lessonOwnedBy(lesson, userID) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Anda hanya dapat memulihkan data sendiri",
+        })
+    }
+
+    if err := database.DB.Unscoped().Model(&lesson).Update("deleted_at", nil).Error; err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not restore lesson record",
+        })
+    }
+
+    before := lesson.Snapshot()
+    recordLessonHistory(lesson, &before, "RESTORE", "Catatan mengajar dipulihkan dari trash", userID)
+
+    activityDescription := fmt.Sprintf("Memulihkan lesson: %s - %s (%s)", lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru)
+    createActivity(userEmail, "restore", activityDescription)
+
+    database.DB.Preload("CreatedBy").First(&lesson, lesson.ID)
+    return c.JSON(lesson)
+}
+
+// PurgeLesson menghapus permanen lesson yang sudah ada di trash (khusus admin)
+func PurgeLesson(c *fiber.Ctx) error {
+    id := c.Params("id")
+    userID := c.Locals("userID").(uint)
+    userEmail := c.Locals("email").(string)
+    var lesson models.DailyLesson
+
+    if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&lesson, id).Error; err != nil {
+        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+            "error": "Lesson tidak ditemukan di trash",
+        })
+    }
+
+    if err := purgeLesson(lesson, userID, "Catatan mengajar dihapus permanen"); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Could not purge lesson record",
+        })
+    }
+
+    activityDescription := fmt.Sprintf("Menghapus permanen lesson: %s - %s (%s)", lesson.MataPelajaran, lesson.Kelas, lesson.NamaGuru)
+    createActivity(userEmail, "purge", activityDescription)
+
+    return c.JSON(fiber.Map{
+        "message": "Lesson record purged permanently",
+    })
+}
+
+// PurgeExpiredLessons menghapus permanen lesson yang sudah melewati masa retensi trash
+func PurgeExpiredLessons() {
+    days := trashRetentionDays()
+    if days == 0 {
+        return
+    }
+
+    var lessons []models.DailyLesson
+    cutoff := time.Now().AddDate(0, 0, -days)
+    if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&lessons).Error; err != nil {
+        log.Printf("Failed to find expired trash: %v", err)
+        return
+    }
+
+    description := fmt.Sprintf("Catatan mengajar dihapus permanen otomatis setelah %d hari di trash", days)
+    for _, lesson := range lessons {
+        if err := purgeLesson(lesson, 0, description); err != nil {
+            log.Printf("Failed to purge lesson %d: %v", lesson.ID, err)
+        }
+    }
+
+    if len(lessons) > 0 {
+        log.Printf("Purged %d expired lessons from trash", len(lessons))
+    }
+}
+
+// StartTrashPurger menjalankan PurgeExpiredLessons dan pembersihan Idempotency-Key kedaluwarsa
+// saat server start lalu setiap hari. Fungsi yang dikembalikan menghentikan purger dan menunggu
+// purge yang sedang berjalan selesai.
+func StartTrashPurger() func() {
+    return startTrashPurger(24 * time.Hour)
+}
+
+func startTrashPurger(interval time.Duration) func() {
+    ticker := time.NewTicker(interval)
+    stop := make(chan struct{})
+    done := make(chan struct{})
+
+    go func() {
+        defer close(done)
+        purgeExpired()
+        for {
+            select {
+            case <-ticker.C:
+                purgeExpired()
+            case <-stop:
+                return
+            }
+        }
+    }()
+
+    var once sync.Once
+    return func() {
+        once.Do(func() {
+            ticker.Stop()
+            close(stop)
+            <-done
+        })
+    }
+}
+
+func purgeExpired() {
+    PurgeExpiredLessons()
+    middleware.PurgeExpiredIdempotencyKeys()
+}
+
+// purgeLesson menghapus baris lesson beserta data turunannya dan file buktinya dalam satu
+// transaksi. History tetap disimpan dengan snapshot terakhir sehingga jejak audit tidak hilang,
+// dan catatan kejadian hanya dilepas dari lesson.
+func purgeLesson(lesson models.DailyLesson, userID uint, description string) error {
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        // Relasi tujuan pembelajaran ikut dihapus agar tidak tersisa baris join yatim
+        if err := tx.Unscoped().Select("TujuanPembelajaran").Delete(&lesson).Error; err != nil {
+            return err
+        }
+        // Lesson lain yang ditandai duplikat karena foto lesson ini dihitung ulang
+        if err := clearDuplicateMarks(tx, lesson.ID); err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Where("lesson_id = ?", lesson.ID).Delete(&models.Attendance{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Where("lesson_id = ?", lesson.ID).Delete(&models.Assignment{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Where("assessment_id IN (?)", tx.Unscoped().
+            Model(&models.Assessment{}).Select("id").Where("lesson_id = ?", lesson.ID)).
+            Delete(&models.AssessmentScore{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Where("lesson_id = ?", lesson.ID).Delete(&models.Assessment{}).Error; err != nil {
+            return err
+        }
+        // Catatan kejadian adalah catatan BK tentang siswa, jadi tetap disimpan tanpa tautan lesson
+        if err := tx.Unscoped().Model(&models.Incident{}).Where("lesson_id = ?", lesson.ID).
+            Update("lesson_id", nil).Error; err != nil {
+            return err
+        }
+
+        before := lesson.Snapshot()
+        history := newLessonHistory(lesson, &before, "PURGE", description, userID)
+        return tx.Create(&history).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    // File baru dihapus setelah transaksi berhasil agar bukti tidak hilang jika purge gagal
+    removeEvidenceFile(lesson.BuktiMengajar)
+    return nil
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+    "testing"
+    "time"
+
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func TestPurgeLessonRemovesAttendance(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    lesson := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+    })
+    other := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID,
+    })
+
+    student := models.Student{Nama: "Siswa", Kelas: "7A", Aktif: true}
+    if err := database.DB.Create(&student).Error; err != nil {
+        t.Fatalf("create student: %v", err)
+    }
+    for _, lessonID := range []uint{lesson.ID, other.ID} {
+        attendance := models.Attendance{LessonID: lessonID, StudentID: student.ID, Status: models.AttendanceHadir}
+        if err := database.DB.Create(&attendance).Error; err != nil {
+            t.Fatalf("create attendance: %v", err)
+        }
+    }
+
+    if err := purgeLesson(lesson, guru.ID, "purge"); err != nil {
+        t.Fatalf("purgeLesson: %v", err)
+    }
+
+    var remaining []models.Attendance
+    database.DB.Unscoped().Find(&remaining)
+    if len(remaining) != 1 || remaining[0].LessonID != other.ID {
+        t.Fatalf("remaining attendance = %+v, want only lesson %d", remaining, other.ID)
+    }
+
+    var history models.LessonReport
+    if err := database.DB.Where("lesson_id = ? AND action = ?", lesson.ID, "PURGE").First(&history).Error; err != nil {
+        t.Fatalf("purge history not recorded: %v", err)
+    }
+}
+
+func TestPurgeLessonKeepsIncidents(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    lesson := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+    })
+
+    student := models.Student{Nama: "Siswa", Kelas: "7A", Aktif: true}
+    if err := database.DB.Create(&student).Error; err != nil {
+        t.Fatalf("create student: %v", err)
+    }
+    incident := models.Incident{
+        LessonID: &lesson.ID, Kelas: "7A", Tanggal: lesson.TanggalMengajar, Kategori: models.IncidentKonflik,
+        Deskripsi: "Bertengkar", Students: []models.Student{student}, ReportedByID: guru.ID,
+    }
+    if err := database.DB.Create(&incident).Error; err != nil {
+        t.Fatalf("create incident: %v", err)
+    }
+
+    if err := purgeLesson(lesson, guru.ID, "purge"); err != nil {
+        t.Fatalf("purgeLesson: %v", err)
+    }
+
+    var kept models.Incident
+    if err := database.DB.Preload("Students").First(&kept, incident.ID).Error; err != nil {
+        t.Fatalf("incident was deleted: %v", err)
+    }
+    if kept.LessonID != nil {
+        t.Errorf("lesson_id = %d, want null after purge", *kept.LessonID)
+    }
+    if len(kept.Students) != 1 || kept.Students[0].ID != student.ID {
+        t.Errorf("students = %+v, want student %d", kept.Students, student.ID)
+    }
+}
+
+func TestPurgeLessonRecomputesDuplicateMarks(t *testing.T) {
+    setupTestDB(t)
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    original := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+        BuktiHash: "ffffffffffffffff",
+    })
+    duplicate := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7B", TanggalMengajar: testDate("2026-10-06"), CreatedByID: guru.ID,
+        BuktiHash: "ffffffffffffffff", BuktiDuplikat: true, BuktiDuplikatDariID: &original.ID,
+    })
+
+    if err := purgeLesson(original, guru.ID, "purge"); err != nil {
+        t.Fatalf("purgeLesson: %v", err)
+    }
+
+    var current models.DailyLesson
+    database.DB.First(&current, duplicate.ID)
+    if current.BuktiDuplikat || current.BuktiDuplikatDariID != nil {
+        t.Fatalf("lesson %d mark = %v dari %v, want cleared after the original is purged", current.ID, current.BuktiDuplikat, current.BuktiDuplikatDariID)
+    }
+}
+
+func TestTrashPurgerRemovesExpiredLessons(t *testing.T) {
+    setupTestDB(t)
+    t.Setenv("TRASH_RETENTION_DAYS", "30")
+    guru := createTestUser(t, "guru", models.RoleTeacher)
+    expired := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7A", TanggalMengajar: testDate("2026-08-03"), CreatedByID: guru.ID,
+    })
+    recent := createTestLesson(t, models.DailyLesson{
+        NamaGuru: "guru", Kelas: "7B", TanggalMengajar: testDate("2026-10-05"), CreatedByID: guru.ID,
+    })
+    database.DB.Unscoped().Model(&expired).Update("deleted_at", time.Now().AddDate(0, 0, -31))
+    database.DB.Unscoped().Model(&recent).Update("deleted_at", time.Now().AddDate(0, 0, -1))
+
+    // Purge pertama berjalan saat start; stop menunggu purge itu selesai
+    stop := startTrashPurger(time.Hour)
+    stop()
+    stop()
+
+    var remaining []models.DailyLesson
+    database.DB.Unscoped().Find(&remaining)
+    if len(remaining) != 1 || remaining[0].ID != recent.ID {
+        t.Fatalf("remaining lessons = %d, want only lesson %d", len(remaining), recent.ID)
+    }
+}
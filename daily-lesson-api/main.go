@@ -10,6 +10,7 @@ import (
     "daily-lesson-api/database"
     "daily-lesson-api/handlers"
     "daily-lesson-api/middleware"
+    "daily-lesson-api/models"
     "daily-lesson-api/utils"
 )
 
@@ -30,6 +31,10 @@ func main() {
     // Connect to database
     database.Connect()
     
+    // Hapus permanen lesson yang sudah terlalu lama di trash
+    stopPurger := handlers.StartTrashPurger()
+    defer stopPurger()
+    
     app := fiber.New()
     
     // Middleware
@@ -72,17 +77,142 @@ func main() {
     
     // Auth routes
     api.Get("/auth/profile", handlers.GetProfile)           
+    api.Put("/admin/users/:id/departemen", middleware.RequireRole(models.RoleAdmin), handlers.UpdateUserDepartemen)
+    api.Put("/admin/users/:id/role", middleware.RequireRole(models.RoleAdmin), handlers.UpdateUserRole)
     
     // Lesson routes
     api.Get("/lessons", middleware.TeacherOnly(), handlers.GetLessons)          
+    api.Get("/lessons/search", middleware.TeacherOnly(), handlers.SearchLessons)
+    api.Get("/lessons/trash", middleware.TeacherOnly(), handlers.GetTrash)
+    api.Get("/lessons/export", middleware.TeacherOnly(), handlers.ExportLessons)
     api.Get("/lessons/:id", handlers.GetLesson)                                 
     api.Get("/lessons/:id/history", handlers.GetLessonHistory)                  
     api.Get("/reports/teacher", handlers.GetTeacherReport)                      
+    api.Get("/reports/teacher/summary", handlers.GetTeacherReportSummary)
+    api.Get("/reports/teacher/export", handlers.ExportTeacherReport)
+    api.Get("/reports/teacher/pdf", handlers.GetTeacherJurnalPDF)
+    api.Get("/reports/teacher/html", handlers.GetTeacherReportHTML)
+    api.Get("/reports/compliance", middleware.RequireRole(models.RoleSupervisor), handlers.GetComplianceReport)
+    api.Get("/reports/duplicate-evidence", middleware.RequireRole(models.RoleSupervisor), handlers.GetDuplicateEvidenceReport)
+    api.Get("/reports/evidence-export", middleware.RequireRole(models.RoleSupervisor), handlers.ExportEvidenceZip)
     
     // Lesson management
-    api.Post("/lessons", middleware.TeacherOnly(), handlers.CreateLesson)       
+    api.Post("/lessons", middleware.TeacherOnly(), middleware.Idempotency(), handlers.CreateLesson)       
+    api.Post("/lessons/import", middleware.TeacherOnly(), middleware.Idempotency(), handlers.ImportLessons)
+    api.Post("/lessons/copy-week", middleware.TeacherOnly(), middleware.Idempotency(), handlers.CopyWeek)
+    api.Post("/lessons/:id/clone", middleware.TeacherOnly(), middleware.Idempotency(), handlers.CloneLesson)
     api.Put("/lessons/:id", middleware.TeacherOnly(), handlers.UpdateLesson)    
     api.Delete("/lessons/:id", middleware.TeacherOnly(), handlers.DeleteLesson) 
+    api.Post("/lessons/:id/restore", middleware.TeacherOnly(), handlers.RestoreLesson)
+    api.Post("/lessons/:id/history/:historyId/revert", middleware.TeacherOnly(), handlers.RevertLesson)
+    api.Delete("/lessons/:id/purge", middleware.RequireRole(models.RoleAdmin), handlers.PurgeLesson)
+    
+    // Bukti mengajar
+    api.Post("/lessons/:id/evidence", middleware.TeacherOnly(), handlers.UploadEvidence)
+    api.Get("/lessons/:id/evidence", handlers.GetEvidence)
+
+    // Kurikulum (CP/TP)
+    api.Get("/curriculum/capaian", middleware.TeacherOnly(), handlers.GetCapaianPembelajaran)
+    api.Post("/curriculum/capaian", middleware.RequireRole(models.RoleSupervisor), handlers.CreateCapaianPembelajaran)
+    api.Put("/curriculum/capaian/:id", middleware.RequireRole(models.RoleSupervisor), handlers.UpdateCapaianPembelajaran)
+    api.Delete("/curriculum/capaian/:id", middleware.RequireRole(models.RoleSupervisor), handlers.DeleteCapaianPembelajaran)
+    api.Post("/curriculum/capaian/:id/tujuan", middleware.RequireRole(models.RoleSupervisor), handlers.CreateTujuanPembelajaran)
+    api.Put("/curriculum/tujuan/:id", middleware.RequireRole(models.RoleSupervisor), handlers.UpdateTujuanPembelajaran)
+    api.Delete("/curriculum/tujuan/:id", middleware.RequireRole(models.RoleSupervisor), handlers.DeleteTujuanPembelajaran)
+    api.Put("/lessons/:id/objectives", middleware.TeacherOnly(), handlers.SetLessonObjectives)
+    api.Get("/reports/curriculum-coverage", middleware.TeacherOnly(), handlers.GetCurriculumCoverage)
+
+    // Tugas siswa
+    api.Get("/lessons/:id/assignments", middleware.TeacherOnly(), handlers.GetLessonAssignments)
+    api.Post("/lessons/:id/assignments", middleware.TeacherOnly(), handlers.CreateAssignment)
+    api.Get("/assignments/upcoming", middleware.TeacherOnly(), handlers.GetUpcomingAssignments)
+    api.Get("/assignments/pending-grading", middleware.TeacherOnly(), handlers.GetPendingGrading)
+    api.Put("/assignments/:id", middleware.TeacherOnly(), handlers.UpdateAssignment)
+    api.Put("/assignments/:id/grade", middleware.TeacherOnly(), handlers.GradeAssignment)
+    api.Delete("/assignments/:id", middleware.TeacherOnly(), handlers.DeleteAssignment)
+
+    // Modul ajar
+    api.Get("/lesson-plans", middleware.TeacherOnly(), handlers.GetLessonPlans)
+    api.Post("/lesson-plans", middleware.TeacherOnly(), handlers.CreateLessonPlan)
+    api.Get("/lesson-plans/:id", middleware.TeacherOnly(), handlers.GetLessonPlan)
+    api.Put("/lesson-plans/:id", middleware.TeacherOnly(), handlers.UpdateLessonPlan)
+    api.Delete("/lesson-plans/:id", middleware.TeacherOnly(), handlers.DeleteLessonPlan)
+    api.Post("/lesson-plans/:id/documents", middleware.TeacherOnly(), handlers.UploadLessonPlanDocument)
+    api.Get("/lesson-plans/:id/documents/:version", middleware.TeacherOnly(), handlers.GetLessonPlanDocument)
+    api.Put("/lessons/:id/lesson-plan", middleware.TeacherOnly(), handlers.SetLessonPlan)
+    api.Get("/reports/lessons-without-plan", middleware.RequireRole(models.RoleSupervisor), handlers.GetLessonsWithoutPlan)
+
+    // Program semester
+    api.Get("/semester-plans", middleware.TeacherOnly(), handlers.GetSemesterPlans)
+    api.Post("/semester-plans", middleware.TeacherOnly(), handlers.CreateSemesterPlan)
+    api.Get("/semester-plans/:id", middleware.TeacherOnly(), handlers.GetSemesterPlan)
+    api.Put("/semester-plans/:id", middleware.TeacherOnly(), handlers.UpdateSemesterPlan)
+    api.Delete("/semester-plans/:id", middleware.TeacherOnly(), handlers.DeleteSemesterPlan)
+    api.Get("/semester-plans/:id/progress", middleware.TeacherOnly(), handlers.GetSemesterPlanProgress)
+    api.Put("/lessons/:id/planned-topic", middleware.TeacherOnly(), handlers.SetLessonTopic)
+    api.Get("/reports/syllabus-progress", middleware.RequireRole(models.RoleSupervisor), handlers.GetSyllabusProgressReport)
+
+    // Roster dan absensi siswa
+    api.Get("/students", middleware.TeacherOnly(), handlers.GetStudents)
+    api.Post("/students", middleware.RequireRole(models.RoleAdmin), handlers.CreateStudent)
+    api.Post("/students/import", middleware.RequireRole(models.RoleAdmin), handlers.ImportStudents)
+    api.Post("/students/promote", middleware.RequireRole(models.RoleAdmin), handlers.PromoteStudents)
+    api.Get("/students/:id", middleware.TeacherOnly(), handlers.GetStudent)
+    api.Put("/students/:id", middleware.RequireRole(models.RoleAdmin), handlers.UpdateStudent)
+    api.Delete("/students/:id", middleware.RequireRole(models.RoleAdmin), handlers.DeleteStudent)
+    api.Get("/lessons/:id/attendance", middleware.TeacherOnly(), handlers.GetLessonAttendance)
+    api.Put("/lessons/:id/attendance", middleware.TeacherOnly(), handlers.SaveLessonAttendance)
+    api.Get("/reports/attendance/students", middleware.TeacherOnly(), handlers.GetStudentAttendanceRecap)
+    api.Get("/reports/attendance/students/:id", middleware.TeacherOnly(), handlers.GetStudentAttendanceHistory)
+    api.Get("/reports/attendance/classes", middleware.TeacherOnly(), handlers.GetClassAttendanceRecap)
+
+    // Penilaian harian siswa
+    api.Get("/score-scale", middleware.TeacherOnly(), handlers.GetScoreScale)
+    api.Put("/admin/score-scale", middleware.RequireRole(models.RoleAdmin), handlers.UpdateScoreScale)
+    api.Get("/lessons/:id/assessments", middleware.TeacherOnly(), handlers.GetLessonAssessments)
+    api.Post("/lessons/:id/assessments", middleware.TeacherOnly(), handlers.CreateAssessment)
+    api.Get("/assessments/gradebook", middleware.TeacherOnly(), handlers.GetGradebook)
+    api.Get("/assessments/:id", middleware.TeacherOnly(), handlers.GetAssessment)
+    api.Put("/assessments/:id", middleware.TeacherOnly(), handlers.UpdateAssessment)
+    api.Put("/assessments/:id/scores", middleware.TeacherOnly(), handlers.SaveAssessmentScores)
+    api.Delete("/assessments/:id", middleware.TeacherOnly(), handlers.DeleteAssessment)
+
+    // Catatan kejadian kelas dan bimbingan konseling
+    api.Get("/lessons/:id/incidents", middleware.RequireAnyRole(models.RoleTeacher, models.RoleCounselor), handlers.GetLessonIncidents)
+    api.Post("/lessons/:id/incidents", middleware.RequireRole(models.RoleTeacher), handlers.CreateIncident)
+    api.Get("/incidents", middleware.RequireAnyRole(models.RoleTeacher, models.RoleCounselor), handlers.GetIncidents)
+    api.Get("/incidents/:id", middleware.RequireAnyRole(models.RoleTeacher, models.RoleCounselor), handlers.GetIncident)
+    api.Put("/incidents/:id", middleware.RequireRole(models.RoleTeacher), handlers.UpdateIncident)
+    api.Put("/incidents/:id/follow-up", middleware.RequireRole(models.RoleCounselor), handlers.FollowUpIncident)
+    api.Delete("/incidents/:id", middleware.RequireRole(models.RoleTeacher), handlers.DeleteIncident)
+    api.Get("/reports/incidents/students", middleware.RequireRole(models.RoleCounselor), handlers.GetStudentIncidentRecap)
+    api.Get("/reports/incidents/students/:id", middleware.RequireRole(models.RoleCounselor), handlers.GetStudentIncidentSummary)
+
+    // Guru pengganti
+    api.Get("/substitutions", middleware.TeacherOnly(), handlers.GetSubstitutions)
+    api.Post("/substitutions", middleware.TeacherOnly(), handlers.CreateSubstitution)
+    api.Put("/substitutions/:id/assign", middleware.TeacherOnly(), handlers.AssignSubstitution)
+    api.Delete("/substitutions/:id", middleware.TeacherOnly(), handlers.CancelSubstitution)
+    api.Post("/substitutions/:id/lesson", middleware.TeacherOnly(), middleware.Idempotency(), handlers.FileSubstitutionLesson)
+
+    // Pengajuan izin guru
+    api.Get("/leave-requests", middleware.TeacherOnly(), handlers.GetLeaveRequests)
+    api.Post("/leave-requests", middleware.TeacherOnly(), handlers.CreateLeaveRequest)
+    api.Get("/leave-requests/:id/attachment", middleware.TeacherOnly(), handlers.GetLeaveAttachment)
+    api.Delete("/leave-requests/:id", middleware.TeacherOnly(), handlers.CancelLeaveRequest)
+    api.Put("/leave-requests/:id/approve", middleware.RequireRole(models.RoleSupervisor), handlers.ApproveLeaveRequest)
+    api.Put("/leave-requests/:id/reject", middleware.RequireRole(models.RoleSupervisor), handlers.RejectLeaveRequest)
+
+    // Profil sekolah dan template laporan
+    api.Get("/school-profile/logo", handlers.GetSchoolLogo)
+    api.Get("/admin/school-profile", middleware.RequireRole(models.RoleAdmin), handlers.GetSchoolProfile)
+    api.Put("/admin/school-profile", middleware.RequireRole(models.RoleAdmin), handlers.UpdateSchoolProfile)
+    api.Post("/admin/school-profile/logo", middleware.RequireRole(models.RoleAdmin), handlers.UploadSchoolLogo)
+    api.Get("/admin/report-templates", middleware.RequireRole(models.RoleAdmin), handlers.GetReportTemplates)
+    api.Get("/admin/report-templates/:key", middleware.RequireRole(models.RoleAdmin), handlers.GetReportTemplate)
+    api.Put("/admin/report-templates/:key", middleware.RequireRole(models.RoleAdmin), handlers.UpdateReportTemplate)
+    api.Delete("/admin/report-templates/:key", middleware.RequireRole(models.RoleAdmin), handlers.ResetReportTemplate)
+    api.Post("/admin/report-templates/:key/preview", middleware.RequireRole(models.RoleAdmin), handlers.PreviewReportTemplate)
 
     // Tambahkan route activities
     api.Get("/activities", handlers.GetUserActivities)
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "log"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+// idempotencyKeyTTL adalah lama response disimpan untuk diputar ulang
+const idempotencyKeyTTL = 24 * time.Hour
+
+// Idempotency menyimpan response request yang memiliki header Idempotency-Key per user.
+// Request ulang dengan key dan isi yang sama mendapat response tersimpan tanpa menjalankan
+// handler lagi; key yang sama dengan isi berbeda ditolak.
+func Idempotency() fiber.Handler {
+    return func(c *fiber.Ctx) error {
+        key := c.Get("Idempotency-Key")
+        if key == "" {
+            return c.Next()
+        }
+
+        if len(key) > 255 {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "Idempotency-Key maksimal 255 karakter",
+            })
+        }
+
+        userID := c.Locals("userID").(uint)
+        // Query string ikut di-hash karena opsi seperti on_duplicate=merge mengubah hasil request
+        target := c.Method() + " " + c.Path() + "?" + string(c.Request().URI().QueryString()) + "\n"
+        sum := sha256.Sum256(append([]byte(target), c.Body()...))
+        requestHash := hex.EncodeToString(sum[:])
+
+        // Key kedaluwarsa milik request ini dilepas agar bisa dipakai lagi; sisanya dibersihkan
+        // oleh PurgeExpiredIdempotencyKeys
+        database.DB.Where("user_id = ? AND `key` = ? AND created_at < ?", userID, key, time.Now().Add(-idempotencyKeyTTL)).
+            Delete(&models.IdempotencyKey{})
+
+        var stored models.IdempotencyKey
+        if err := database.DB.Where("user_id = ? AND `key` = ?", userID, key).First(&stored).Error; err == nil {
+            if stored.RequestHash != requestHash {
+                return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+                    "error": "Idempotency-Key sudah dipakai untuk request yang berbeda",
+                })
+            }
+
+            if stored.StatusCode == 0 {
+                return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+                    "error": "Request dengan Idempotency-Key ini masih diproses",
+                })
+            }
+
+            c.Set("Idempotent-Replayed", "true")
+            c.Set(fiber.HeaderContentType, stored.ContentType)
+            return c.Status(stored.StatusCode).Send(stored.Body)
+        }
+
+        // Baris disimpan sebelum handler berjalan; unique index mencegah dua request
+        // bersamaan dengan key yang sama sama-sama diproses
+        record := models.IdempotencyKey{
+            Key:         key,
+            UserID:      userID,
+            Method:      c.Method(),
+            Path:        c.Path(),
+            RequestHash: requestHash,
+        }
+        if err := database.DB.Create(&record).Error; err != nil {
+            if isUniqueViolation(err) {
+                return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+                    "error": "Request dengan Idempotency-Key ini masih diproses",
+                })
+            }
+            log.Printf("Failed to store idempotency key: %v", err)
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "Could not process request",
+            })
+        }
+
+        if err := c.Next(); err != nil {
+            database.DB.Delete(&record)
+            return err
+        }
+
+        // Error server tidak disimpan agar request bisa dicoba lagi dengan key yang sama
+        status := c.Response().StatusCode()
+        if status >= fiber.StatusInternalServerError {
+            database.DB.Delete(&record)
+            return nil
+        }
+
+        database.DB.Model(&record).Updates(map[string]interface{}{
+            "status_code":  status,
+            "content_type": string(c.Response().Header.ContentType()),
+            "body":         append([]byte(nil), c.Response().Body()...),
+        })
+        return nil
+    }
+}
+
+// PurgeExpiredIdempotencyKeys menghapus response tersimpan yang sudah melewati idempotencyKeyTTL
+func PurgeExpiredIdempotencyKeys() {
+    result := database.DB.Where("created_at < ?", time.Now().Add(-idempotencyKeyTTL)).Delete(&models.IdempotencyKey{})
+    if result.Error != nil {
+        log.Printf("Failed to purge expired idempotency keys: %v", result.Error)
+        return
+    }
+    if result.RowsAffected > 0 {
+        log.Printf("Purged %d expired idempotency keys", result.RowsAffected)
+    }
+}
+
+// isUniqueViolation mengenali pelanggaran unique index, baik yang sudah diterjemahkan GORM
+// maupun error asli SQLite
+func isUniqueViolation(err error) bool {
+    return errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+    "io"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/glebarez/sqlite"
+    "github.com/gofiber/fiber/v2"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+    "daily-lesson-api/database"
+    "daily-lesson-api/models"
+)
+
+func setupIdempotencyTest(t *testing.T) (*fiber.App, *int) {
+    t.Helper()
+
+    db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatalf("open database: %v", err)
+    }
+    sqlDB, _ := db.DB()
+    sqlDB.SetMaxOpenConns(1)
+    if err := db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+        t.Fatalf("migrate: %v", err)
+    }
+
+    previous := database.DB
+    database.DB = db
+    t.Cleanup(func() {
+        database.DB = previous
+        sqlDB.Close()
+    })
+
+    calls := 0
+    app := fiber.New()
+    app.Post("/lessons", func(c *fiber.Ctx) error {
+        c.Locals("userID", uint(1))
+        return c.Next()
+    }, Idempotency(), func(c *fiber.Ctx) error {
+        calls++
+        if c.Query("on_duplicate") != "merge" {
+            return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "duplicate"})
+        }
+        return c.JSON(fiber.Map{"merged": true})
+    })
+    return app, &calls
+}
+
+func sendIdempotent(t *testing.T, app *fiber.App, target, key, body string) (int, string, string) {
+    t.Helper()
+
+    req := httptest.NewRequest("POST", target, strings.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Idempotency-Key", key)
+    resp, err := app.Test(req)
+    if err != nil {
+        t.Fatalf("request: %v", err)
+    }
+    data, _ := io.ReadAll(resp.Body)
+    return resp.StatusCode, resp.Header.Get("Idempotent-Replayed"), string(data)
+}
+
+func TestIdempotencyReplaysSameRequest(t *testing.T) {
+    app, calls := setupIdempotencyTest(t)
+
+    status, _, _ := sendIdempotent(t, app, "/lessons", "k1", `{"kelas":"7A"}`)
+    if status != fiber.StatusConflict {
+        t.Fatalf("first status = %d, want 409", status)
+    }
+
+    status, replayed, _ := sendIdempotent(t, app, "/lessons", "k1", `{"kelas":"7A"}`)
+    if status != fiber.StatusConflict || replayed != "true" {
+        t.Fatalf("retry status = %d replayed = %q, want replayed 409", status, replayed)
+    }
+    if *calls != 1 {
+        t.Fatalf("handler calls = %d, want 1", *calls)
+    }
+}
+
+func TestIdempotencyRejectsKeyReuseWithDifferentQuery(t *testing.T) {
+    app, calls := setupIdempotencyTest(t)
+
+    sendIdempotent(t, app, "/lessons", "k1", `{"kelas":"7A"}`)
+
+    status, replayed, _ := sendIdempotent(t, app, "/lessons?on_duplicate=merge", "k1", `{"kelas":"7A"}`)
+    if status != fiber.StatusUnprocessableEntity || replayed != "" {
+        t.Fatalf("status = %d replayed = %q, want 422 without replay", status, replayed)
+    }
+
+    status, _, body := sendIdempotent(t, app, "/lessons?on_duplicate=merge", "k2", `{"kelas":"7A"}`)
+    if status != fiber.StatusOK || !strings.Contains(body, "merged") {
+        t.Fatalf("new key status = %d body = %s, want merged response", status, body)
+    }
+    if *calls != 2 {
+        t.Fatalf("handler calls = %d, want 2", *calls)
+    }
+}
+
+func TestIdempotencyRejectsKeyReuseWithDifferentBody(t *testing.T) {
+    app, _ := setupIdempotencyTest(t)
+
+    sendIdempotent(t, app, "/lessons", "k1", `{"kelas":"7A"}`)
+    status, _, _ := sendIdempotent(t, app, "/lessons", "k1", `{"kelas":"7B"}`)
+    if status != fiber.StatusUnprocessableEntity {
+        t.Fatalf("status = %d, want 422", status)
+    }
+}
+
+func TestIdempotencyExpiredKeys(t *testing.T) {
+    app, calls := setupIdempotencyTest(t)
+
+    sendIdempotent(t, app, "/lessons", "old", `{"kelas":"7A"}`)
+    sendIdempotent(t, app, "/lessons", "other", `{"kelas":"7A"}`)
+    sendIdempotent(t, app, "/lessons", "fresh", `{"kelas":"7A"}`)
+    expired := time.Now().Add(-idempotencyKeyTTL - time.Hour)
+    database.DB.Model(&models.IdempotencyKey{}).Where("`key` IN ?", []string{"old", "other"}).Update("created_at", expired)
+
+    // Key kedaluwarsa boleh dipakai lagi tanpa menyapu key lain
+    status, replayed, _ := sendIdempotent(t, app, "/lessons", "old", `{"kelas":"7B"}`)
+    if status != fiber.StatusConflict || replayed != "" || *calls != 4 {
+        t.Fatalf("status = %d replayed = %q calls = %d, want a fresh run", status, replayed, *calls)
+    }
+    var count int64
+    database.DB.Model(&models.IdempotencyKey{}).Count(&count)
+    if count != 3 {
+        t.Fatalf("keys = %d after request, want 3", count)
+    }
+
+    PurgeExpiredIdempotencyKeys()
+    var keys []string
+    database.DB.Model(&models.IdempotencyKey{}).Order("`key`").Pluck("key", &keys)
+    if strings.Join(keys, ",") != "fresh,old" {
+        t.Fatalf("keys after purge = %v, want fresh and old", keys)
+    }
+}
+
+func TestIdempotencyStoreFailureIsServerError(t *testing.T) {
+    app, calls := setupIdempotencyTest(t)
+    database.DB.Migrator().DropTable(&models.IdempotencyKey{})
+
+    status, _, _ := sendIdempotent(t, app, "/lessons", "k1", `{"kelas":"7A"}`)
+    if status != fiber.StatusInternalServerError || *calls != 0 {
+        t.Fatalf("status = %d calls = %d, want 500 without running the handler", status, *calls)
+    }
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+    setupIdempotencyTest(t)
+
+    database.DB.Create(&models.IdempotencyKey{Key: "k1", UserID: 1})
+    err := database.DB.Create(&models.IdempotencyKey{Key: "k1", UserID: 1}).Error
+    if err == nil || !isUniqueViolation(err) {
+        t.Fatalf("duplicate insert error = %v, want unique violation", err)
+    }
+
+    database.DB.Migrator().DropTable(&models.IdempotencyKey{})
+    err = database.DB.Create(&models.IdempotencyKey{Key: "k2", UserID: 1}).Error
+    if err == nil || isUniqueViolation(err) {
+        t.Fatalf("missing table error = %v, want a non-unique error", err)
+    }
+}
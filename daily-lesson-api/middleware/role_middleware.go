@@ -23,6 +23,27 @@ func RequireRole(requiredRole models.UserRole) fiber.Handler {
     }
 }
 
+// RequireAnyRole mengizinkan salah satu dari beberapa role; admin selalu diizinkan
+func RequireAnyRole(roles ...models.UserRole) fiber.Handler {
+    return func(c *fiber.Ctx) error {
+        userRole := c.Locals("role").(string)
+        
+        if userRole == string(models.RoleAdmin) {
+            return c.Next()
+        }
+        
+        for _, role := range roles {
+            if userRole == string(role) {
+                return c.Next()
+            }
+        }
+        
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "error": "Insufficient permissions",
+        })
+    }
+}
+
 func TeacherOnly() fiber.Handler {
     return func(c *fiber.Ctx) error {
         userRole := c.Locals("role").(string)
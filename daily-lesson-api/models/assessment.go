@@ -0,0 +1,54 @@
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Jenis penilaian harian
+const (
+    AssessmentKuis    = "kuis"
+    AssessmentUlangan = "ulangan"
+    AssessmentPraktik = "praktik"
+    AssessmentLisan   = "lisan"
+    AssessmentTugas   = "tugas"
+)
+
+// ScoreScale adalah skala nilai bawaan sekolah. Nilainya disalin ke setiap penilaian saat
+// dibuat sehingga perubahan skala tidak mengubah penilaian yang sudah ada.
+type ScoreScale struct {
+    gorm.Model
+    NilaiMaksimal float64 `json:"nilai_maksimal" gorm:"default:100"`
+    KKM           float64 `json:"kkm" gorm:"default:75"`
+    UpdatedByID   uint    `json:"updated_by_id"`
+}
+
+// Assessment adalah penilaian harian (kuis, ulangan, praktik) yang dilakukan pada satu lesson.
+// TeacherID adalah guru pemilik lesson; KKM memakai skala yang sama dengan NilaiMaksimal.
+type Assessment struct {
+    gorm.Model
+    LessonID      uint              `json:"lesson_id" gorm:"index"`
+    Lesson        *DailyLesson      `json:"lesson,omitempty" gorm:"foreignKey:LessonID"`
+    Judul         string            `json:"judul"`
+    Jenis         string            `json:"jenis" gorm:"size:20"`
+    Kelas         string            `json:"kelas" gorm:"index"`
+    MataPelajaran string            `json:"mata_pelajaran" gorm:"index"`
+    Tanggal       time.Time         `json:"tanggal" gorm:"index"`
+    TeacherID     uint              `json:"teacher_id" gorm:"index"`
+    NilaiMaksimal float64           `json:"nilai_maksimal"`
+    KKM           float64           `json:"kkm"`
+    Scores        []AssessmentScore `json:"scores,omitempty" gorm:"foreignKey:AssessmentID"`
+    CreatedByID   uint              `json:"created_by_id"`
+}
+
+// AssessmentScore adalah nilai satu siswa pada satu penilaian
+type AssessmentScore struct {
+    gorm.Model
+    AssessmentID uint    `json:"assessment_id" gorm:"uniqueIndex:idx_assessment_student"`
+    StudentID    uint    `json:"student_id" gorm:"uniqueIndex:idx_assessment_student;index"`
+    Student      Student `json:"student" gorm:"foreignKey:StudentID"`
+    Nilai        float64 `json:"nilai"`
+    Catatan      string  `json:"catatan"`
+    RecordedByID uint    `json:"recorded_by_id"`
+}
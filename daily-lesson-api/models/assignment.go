@@ -0,0 +1,24 @@
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Assignment adalah tugas/PR yang diberikan pada satu lesson. TeacherID adalah guru
+// pemilik lesson yang bertanggung jawab menilai, walaupun tugas diberikan guru pengganti.
+type Assignment struct {
+    gorm.Model
+    LessonID      uint         `json:"lesson_id" gorm:"index"`
+    Lesson        *DailyLesson `json:"lesson,omitempty" gorm:"foreignKey:LessonID"`
+    Judul         string       `json:"judul"`
+    Deskripsi     string       `json:"deskripsi"`
+    Kelas         string       `json:"kelas" gorm:"index"`
+    MataPelajaran string       `json:"mata_pelajaran"`
+    Tenggat       time.Time    `json:"tenggat" gorm:"index"`
+    TeacherID     uint         `json:"teacher_id" gorm:"index"`
+    SudahDinilai  bool         `json:"sudah_dinilai" gorm:"index"`
+    DinilaiPada   *time.Time   `json:"dinilai_pada"`
+    CreatedByID   uint         `json:"created_by_id"`
+}
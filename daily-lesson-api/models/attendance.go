@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// Status kehadiran siswa
+const (
+    AttendanceHadir = "hadir"
+    AttendanceSakit = "sakit"
+    AttendanceIzin  = "izin"
+    AttendanceAlpa  = "alpa"
+)
+
+// Attendance adalah kehadiran satu siswa pada satu lesson
+type Attendance struct {
+    gorm.Model
+    LessonID     uint    `json:"lesson_id" gorm:"uniqueIndex:idx_attendance_lesson_student"`
+    StudentID    uint    `json:"student_id" gorm:"uniqueIndex:idx_attendance_lesson_student;index"`
+    Student      Student `json:"student" gorm:"foreignKey:StudentID"`
+    Status       string  `json:"status" gorm:"size:10;index"`
+    Keterangan   string  `json:"keterangan"`
+    RecordedByID uint    `json:"recorded_by_id"`
+}
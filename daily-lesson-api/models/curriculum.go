@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// CapaianPembelajaran (CP) Kurikulum Merdeka untuk satu mata pelajaran, fase dan elemen
+type CapaianPembelajaran struct {
+    gorm.Model
+    MataPelajaran      string               `json:"mata_pelajaran" gorm:"index"`
+    Fase               string               `json:"fase" gorm:"size:1;index"`
+    Elemen             string               `json:"elemen"`
+    Deskripsi          string               `json:"deskripsi"`
+    TujuanPembelajaran []TujuanPembelajaran `json:"tujuan_pembelajaran,omitempty" gorm:"foreignKey:CapaianID"`
+    CreatedByID        uint                 `json:"created_by_id"`
+}
+
+// TujuanPembelajaran (TP) adalah turunan CP yang dapat ditandai pada catatan mengajar
+type TujuanPembelajaran struct {
+    gorm.Model
+    CapaianID uint                 `json:"capaian_id" gorm:"index"`
+    Capaian   *CapaianPembelajaran `json:"capaian,omitempty" gorm:"foreignKey:CapaianID"`
+    Kode      string               `json:"kode"`
+    Deskripsi string               `json:"deskripsi"`
+    Urutan    int                  `json:"urutan"`
+}
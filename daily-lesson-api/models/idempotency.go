@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyKey menyimpan response dari request yang memakai header Idempotency-Key
+// sehingga request yang diulang (retry, double submit) mendapat response yang sama
+// tanpa membuat data baru. StatusCode 0 berarti request pertama masih diproses.
+type IdempotencyKey struct {
+    ID          uint      `gorm:"primaryKey" json:"id"`
+    Key         string    `gorm:"size:255;uniqueIndex:idx_idempotency_user_key" json:"key"`
+    UserID      uint      `gorm:"uniqueIndex:idx_idempotency_user_key" json:"user_id"`
+    Method      string    `gorm:"size:10" json:"method"`
+    Path        string    `json:"path"`
+    RequestHash string    `gorm:"size:64" json:"request_hash"`
+    StatusCode  int       `json:"status_code"`
+    ContentType string    `json:"content_type"`
+    Body        []byte    `json:"-"`
+    CreatedAt   time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Kategori kejadian di kelas
+const (
+    IncidentKedisiplinan = "kedisiplinan"
+    IncidentPerundungan  = "perundungan"
+    IncidentKonflik      = "konflik"
+    IncidentAkademik     = "akademik"
+    IncidentKesehatan    = "kesehatan"
+    IncidentLainnya      = "lainnya"
+)
+
+// Tingkat keparahan kejadian
+const (
+    SeverityRingan = "ringan"
+    SeveritySedang = "sedang"
+    SeverityBerat  = "berat"
+)
+
+// Status tindak lanjut oleh guru BK
+const (
+    FollowUpBaru     = "baru"
+    FollowUpDiproses = "diproses"
+    FollowUpSelesai  = "selesai"
+)
+
+// Incident adalah catatan kejadian atau perilaku siswa yang dicatat guru saat lesson.
+// Siswa yang terlibat bersifat opsional; tindak lanjut diisi oleh guru BK. LessonID menjadi
+// null jika lesson-nya dihapus permanen; kelas, mapel dan tanggal tetap tersimpan di sini.
+type Incident struct {
+    gorm.Model
+    LessonID            *uint        `json:"lesson_id" gorm:"index"`
+    Lesson              *DailyLesson `json:"lesson,omitempty" gorm:"foreignKey:LessonID"`
+    Kelas               string       `json:"kelas" gorm:"index"`
+    MataPelajaran       string       `json:"mata_pelajaran"`
+    Tanggal             time.Time    `json:"tanggal" gorm:"index"`
+    Kategori            string       `json:"kategori" gorm:"size:20;index"`
+    Tingkat             string       `json:"tingkat" gorm:"size:10;index"`
+    Deskripsi           string       `json:"deskripsi"`
+    TindakanGuru        string       `json:"tindakan_guru"`
+    Students            []Student    `json:"students" gorm:"many2many:incident_students"`
+    ReportedByID        uint         `json:"reported_by_id" gorm:"index"`
+    StatusTindakLanjut  string       `json:"status_tindak_lanjut" gorm:"size:10;default:'baru';index"`
+    CatatanTindakLanjut string       `json:"catatan_tindak_lanjut"`
+    DitanganiOlehID     *uint        `json:"ditangani_oleh_id"`
+    SelesaiPada         *time.Time   `json:"selesai_pada"`
+}
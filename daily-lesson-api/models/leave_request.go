@@ -0,0 +1,48 @@
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Jenis izin guru
+const (
+    LeaveIzin  = "izin"
+    LeaveSakit = "sakit"
+    LeaveDinas = "dinas"
+)
+
+// Status pengajuan izin
+const (
+    LeavePending   = "menunggu"
+    LeaveApproved  = "disetujui"
+    LeaveRejected  = "ditolak"
+    LeaveCancelled = "dibatalkan"
+)
+
+// LeaveRequest adalah pengajuan izin/sakit/dinas guru yang disetujui supervisor.
+// Setelah disetujui, lesson guru pada rentang tanggal tersebut ditandai tidak terlaksana
+// dan slot guru pengganti dibuka.
+type LeaveRequest struct {
+    gorm.Model
+    TeacherID      uint       `json:"teacher_id" gorm:"index"`
+    Teacher        User       `json:"teacher" gorm:"foreignKey:TeacherID"`
+    Jenis          string     `json:"jenis" gorm:"size:10"`
+    TanggalMulai   time.Time  `json:"tanggal_mulai"`
+    TanggalSelesai time.Time  `json:"tanggal_selesai"`
+    Alasan         string     `json:"alasan"`
+    Lampiran       string     `json:"-"`
+    HasLampiran    bool       `json:"has_lampiran" gorm:"-"`
+    Status         string     `json:"status" gorm:"size:20;default:'menunggu';index"`
+    ReviewedByID   *uint      `json:"reviewed_by_id"`
+    ReviewedAt     *time.Time `json:"reviewed_at"`
+    CatatanReview  string     `json:"catatan_review"`
+    CreatedByID    uint       `json:"created_by_id"`
+}
+
+// AfterFind mengisi HasLampiran karena path lampiran tidak dikirim ke client
+func (l *LeaveRequest) AfterFind(tx *gorm.DB) error {
+    l.HasLampiran = l.Lampiran != ""
+    return nil
+}
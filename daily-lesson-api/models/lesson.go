@@ -20,8 +20,58 @@ type DailyLesson struct {
     Catatan        string    `json:"catatan"`
     CreatedByID    uint      `json:"created_by_id"`
     CreatedBy      User      `json:"created_by" gorm:"foreignKey:CreatedByID"`
+
+    // Diisi jika lesson diajar oleh guru pengganti atas nama guru asli (NamaGuru/CreatedByID)
+    GuruPengganti   string `json:"guru_pengganti"`
+    GuruPenggantiID *uint  `json:"guru_pengganti_id" gorm:"index"`
+    SubstitutionID  *uint  `json:"substitution_id"`
+
+    // Diisi jika lesson tidak terlaksana karena izin guru yang disetujui
+    LeaveRequestID        *uint  `json:"leave_request_id" gorm:"index"`
+    AlasanTidakTerlaksana string `json:"alasan_tidak_terlaksana"`
+
+    // Hasil verifikasi metadata EXIF foto bukti mengajar
+    BuktiDiambilPada  *time.Time `json:"bukti_diambil_pada"`
+    BuktiLatitude     *float64   `json:"bukti_latitude"`
+    BuktiLongitude    *float64   `json:"bukti_longitude"`
+    VerifikasiBukti   string     `json:"verifikasi_bukti" gorm:"default:'belum_diverifikasi'"`
+    CatatanVerifikasi string     `json:"catatan_verifikasi"`
+
+    // Perceptual hash foto bukti untuk mendeteksi foto yang dipakai ulang
+    BuktiHash           string `json:"bukti_hash" gorm:"index"`
+    BuktiDuplikat       bool   `json:"bukti_duplikat" gorm:"index"`
+    BuktiDuplikatDariID *uint  `json:"bukti_duplikat_dari_id"`
+    BuktiJarakHash      int    `json:"bukti_jarak_hash"`
+
+    // Modul ajar yang dipakai pada pertemuan ini
+    LessonPlanID *uint `json:"lesson_plan_id" gorm:"index"`
+
+    // Topik program semester yang dipilih guru; jika kosong dicocokkan otomatis saat menghitung progres
+    PlannedTopicID *uint `json:"planned_topic_id" gorm:"index"`
+
+    // Tujuan pembelajaran kurikulum yang dicapai pada pertemuan ini
+    TujuanPembelajaran []TujuanPembelajaran `json:"tujuan_pembelajaran,omitempty" gorm:"many2many:lesson_objectives;joinForeignKey:LessonID;joinReferences:TujuanPembelajaranID"`
 }
 
+// Status catatan mengajar. Draft dipakai untuk salinan lesson yang belum dikonfirmasi guru.
+const (
+    StatusTerlaksana = "terlaksana"
+    StatusDibatalkan = "dibatalkan"
+    StatusDitunda    = "ditunda"
+    StatusDraft      = "draft"
+
+    // Dipakai otomatis saat izin guru disetujui
+    StatusTidakTerlaksana = "tidak_terlaksana"
+)
+
+// Status verifikasi bukti mengajar
+const (
+    VerifikasiBelum         = "belum_diverifikasi"
+    VerifikasiSesuai        = "terverifikasi"
+    VerifikasiTidakSesuai   = "tidak_sesuai"
+    VerifikasiTanpaMetadata = "metadata_tidak_ada"
+)
+
 type LessonReport struct {
     gorm.Model
     LessonID     uint        `json:"lesson_id"`
@@ -30,4 +80,8 @@ type LessonReport struct {
     Description  string      `json:"description"`
     PerformedBy  uint        `json:"performed_by"`
     User         User        `json:"user" gorm:"foreignKey:PerformedBy"`
+    
+    // Perubahan per field dan isi lengkap lesson setelah aksi ini
+    Changes      LessonChanges   `json:"changes" gorm:"type:text"`
+    Snapshot     *LessonSnapshot `json:"snapshot" gorm:"type:text"`
 }
\ No newline at end of file
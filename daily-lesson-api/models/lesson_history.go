@@ -0,0 +1,187 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "sort"
+    "strings"
+    "time"
+)
+
+// FieldChange mencatat perubahan satu field lesson (nama field mengikuti JSON)
+type FieldChange struct {
+    Field    string      `json:"field"`
+    OldValue interface{} `json:"old_value"`
+    NewValue interface{} `json:"new_value"`
+}
+
+// LessonChanges adalah daftar perubahan field yang disimpan sebagai JSON
+type LessonChanges []FieldChange
+
+// LessonSnapshot adalah salinan lengkap isi lesson pada saat history dicatat
+type LessonSnapshot struct {
+    NamaGuru              string    `json:"nama_guru"`
+    MataPelajaran         string    `json:"mata_pelajaran"`
+    Kelas                 string    `json:"kelas"`
+    PokokMateri           string    `json:"pokok_materi"`
+    BuktiMengajar         string    `json:"bukti_mengajar"`
+    TanggalMengajar       time.Time `json:"tanggal_mengajar"`
+    JamMulai              string    `json:"jam_mulai"`
+    JamSelesai            string    `json:"jam_selesai"`
+    Status                string    `json:"status"`
+    Catatan               string    `json:"catatan"`
+    CreatedByID           uint      `json:"created_by_id"`
+    GuruPengganti         string    `json:"guru_pengganti"`
+    GuruPenggantiID       *uint     `json:"guru_pengganti_id"`
+    SubstitutionID        *uint     `json:"substitution_id"`
+    LeaveRequestID        *uint     `json:"leave_request_id"`
+    AlasanTidakTerlaksana string    `json:"alasan_tidak_terlaksana"`
+    TujuanPembelajaranIDs []uint    `json:"tujuan_pembelajaran_ids"`
+    PlannedTopicID        *uint     `json:"planned_topic_id"`
+    LessonPlanID          *uint     `json:"lesson_plan_id"`
+}
+
+// Snapshot mengambil isi lesson saat ini untuk disimpan di history. TP hanya ikut jika
+// relasi TujuanPembelajaran sudah dimuat; nil berarti daftar TP tidak diketahui.
+func (l DailyLesson) Snapshot() LessonSnapshot {
+    snapshot := LessonSnapshot{
+        NamaGuru:              l.NamaGuru,
+        MataPelajaran:         l.MataPelajaran,
+        Kelas:                 l.Kelas,
+        PokokMateri:           l.PokokMateri,
+        BuktiMengajar:         l.BuktiMengajar,
+        TanggalMengajar:       l.TanggalMengajar,
+        JamMulai:              l.JamMulai,
+        JamSelesai:            l.JamSelesai,
+        Status:                l.Status,
+        Catatan:               l.Catatan,
+        CreatedByID:           l.CreatedByID,
+        GuruPengganti:         l.GuruPengganti,
+        GuruPenggantiID:       l.GuruPenggantiID,
+        SubstitutionID:        l.SubstitutionID,
+        LeaveRequestID:        l.LeaveRequestID,
+        AlasanTidakTerlaksana: l.AlasanTidakTerlaksana,
+        PlannedTopicID:        l.PlannedTopicID,
+        LessonPlanID:          l.LessonPlanID,
+    }
+
+    if l.TujuanPembelajaran != nil {
+        snapshot.TujuanPembelajaranIDs = make([]uint, len(l.TujuanPembelajaran))
+        for i, tp := range l.TujuanPembelajaran {
+            snapshot.TujuanPembelajaranIDs[i] = tp.ID
+        }
+        sort.Slice(snapshot.TujuanPembelajaranIDs, func(i, j int) bool {
+            return snapshot.TujuanPembelajaranIDs[i] < snapshot.TujuanPembelajaranIDs[j]
+        })
+    }
+    return snapshot
+}
+
+// ApplySnapshot mengembalikan isi lesson sesuai snapshot. Pemilik lesson (CreatedByID)
+// sengaja tidak ikut diubah; TP dikembalikan terpisah lewat association karena berupa relasi.
+// Guru pengganti, penugasan pengganti dan izin guru dikelola alur persetujuannya sendiri,
+// sehingga tidak dikembalikan agar lesson tidak tertaut lagi ke penugasan yang sudah dibatalkan.
+func (l *DailyLesson) ApplySnapshot(s LessonSnapshot) {
+    l.NamaGuru = s.NamaGuru
+    l.MataPelajaran = s.MataPelajaran
+    l.Kelas = s.Kelas
+    l.PokokMateri = s.PokokMateri
+    l.BuktiMengajar = s.BuktiMengajar
+    l.TanggalMengajar = s.TanggalMengajar
+    l.JamMulai = s.JamMulai
+    l.JamSelesai = s.JamSelesai
+    l.Status = s.Status
+    l.Catatan = s.Catatan
+    l.AlasanTidakTerlaksana = s.AlasanTidakTerlaksana
+    l.PlannedTopicID = s.PlannedTopicID
+    l.LessonPlanID = s.LessonPlanID
+}
+
+// DiffSnapshots membandingkan dua snapshot dan mengembalikan field yang berubah
+func DiffSnapshots(before, after LessonSnapshot) LessonChanges {
+    changes := LessonChanges{}
+
+    beforeValue := reflect.ValueOf(before)
+    afterValue := reflect.ValueOf(after)
+    snapshotType := beforeValue.Type()
+
+    for i := 0; i < snapshotType.NumField(); i++ {
+        oldValue := beforeValue.Field(i).Interface()
+        newValue := afterValue.Field(i).Interface()
+
+        if oldIDs, ok := oldValue.([]uint); ok {
+            // Daftar TP yang tidak dimuat (nil) tidak dibandingkan
+            newIDs := newValue.([]uint)
+            if oldIDs == nil || newIDs == nil || reflect.DeepEqual(oldIDs, newIDs) {
+                continue
+            }
+        } else if oldTime, ok := oldValue.(time.Time); ok {
+            if oldTime.Equal(newValue.(time.Time)) {
+                continue
+            }
+            if oldTime.IsZero() {
+                oldValue = nil
+            }
+        } else if reflect.DeepEqual(oldValue, newValue) {
+            // DeepEqual membandingkan isi pointer, bukan alamatnya
+            continue
+        }
+
+        field := strings.Split(snapshotType.Field(i).Tag.Get("json"), ",")[0]
+        changes = append(changes, FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+    }
+
+    return changes
+}
+
+// Fields mengembalikan nama field yang berubah, dipakai untuk deskripsi history
+func (c LessonChanges) Fields() []string {
+    fields := make([]string, 0, len(c))
+    for _, change := range c {
+        fields = append(fields, change.Field)
+    }
+    return fields
+}
+
+func (c LessonChanges) Value() (driver.Value, error) {
+    if c == nil {
+        return nil, nil
+    }
+    data, err := json.Marshal(c)
+    return string(data), err
+}
+
+func (c *LessonChanges) Scan(value interface{}) error {
+    return scanJSON(value, c)
+}
+
+func (s LessonSnapshot) Value() (driver.Value, error) {
+    data, err := json.Marshal(s)
+    return string(data), err
+}
+
+func (s *LessonSnapshot) Scan(value interface{}) error {
+    return scanJSON(value, s)
+}
+
+// scanJSON membaca kolom text/blob berisi JSON ke dalam dest
+func scanJSON(value interface{}, dest interface{}) error {
+    switch v := value.(type) {
+    case nil:
+        return nil
+    case string:
+        if v == "" {
+            return nil
+        }
+        return json.Unmarshal([]byte(v), dest)
+    case []byte:
+        if len(v) == 0 {
+            return nil
+        }
+        return json.Unmarshal(v, dest)
+    default:
+        return fmt.Errorf("cannot scan %T as JSON", value)
+    }
+}
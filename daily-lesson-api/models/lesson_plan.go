@@ -0,0 +1,31 @@
+package models
+
+import "gorm.io/gorm"
+
+// LessonPlan adalah modul ajar/RPP milik seorang guru. Jika Dibagikan, guru lain di
+// departemen yang sama dapat melihat dan memakainya. Setiap unggahan dokumen menambah versi baru.
+type LessonPlan struct {
+    gorm.Model
+    Judul          string               `json:"judul"`
+    MataPelajaran  string               `json:"mata_pelajaran" gorm:"index"`
+    Kelas          string               `json:"kelas"`
+    Deskripsi      string               `json:"deskripsi"`
+    OwnerID        uint                 `json:"owner_id" gorm:"index"`
+    Owner          User                 `json:"owner" gorm:"foreignKey:OwnerID"`
+    Departemen     string               `json:"departemen" gorm:"index"`
+    Dibagikan      bool                 `json:"dibagikan"`
+    VersiTerakhir  int                  `json:"versi_terakhir"`
+    Documents      []LessonPlanDocument `json:"documents,omitempty" gorm:"foreignKey:LessonPlanID"`
+}
+
+// LessonPlanDocument adalah satu versi dokumen modul ajar
+type LessonPlanDocument struct {
+    gorm.Model
+    LessonPlanID uint   `json:"lesson_plan_id" gorm:"uniqueIndex:idx_lesson_plan_version"`
+    Versi        int    `json:"versi" gorm:"uniqueIndex:idx_lesson_plan_version"`
+    NamaFile     string `json:"nama_file"`
+    Path         string `json:"-"`
+    Ukuran       int64  `json:"ukuran"`
+    Catatan      string `json:"catatan"`
+    UploadedByID uint   `json:"uploaded_by_id"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+
+    "gorm.io/gorm"
+)
+
+const (
+    ReportTeacher = "teacher_report"
+
+    TemplateFormatHTML = "html"
+    TemplateFormatText = "text"
+)
+
+// SchoolProfile adalah identitas sekolah yang dipakai sebagai kop dan tanda tangan laporan.
+// Hanya ada satu baris; jika belum diisi admin, nilai diambil dari environment.
+type SchoolProfile struct {
+    gorm.Model
+    SchoolName        string `json:"school_name"`
+    Address           string `json:"address"`
+    Contact           string `json:"contact"`
+    City              string `json:"city"`
+    LogoPath          string `json:"-"`
+    HasLogo           bool   `json:"has_logo" gorm:"-"`
+    KepalaSekolahName string `json:"kepala_sekolah_name"`
+    KepalaSekolahNIP  string `json:"kepala_sekolah_nip"`
+    SupervisorName    string `json:"supervisor_name"`
+    SupervisorNIP     string `json:"supervisor_nip"`
+    UpdatedByID       uint   `json:"updated_by_id"`
+}
+
+// ReportTemplate adalah template laporan yang bisa diubah admin. Content ditulis dengan
+// sintaks Go template ({{.Title}}, {{range .Rows}} dan seterusnya), Columns menentukan
+// kolom tabel dan urutannya.
+type ReportTemplate struct {
+    gorm.Model
+    Key         string        `json:"key" gorm:"uniqueIndex;size:50"`
+    Name        string        `json:"name"`
+    Title       string        `json:"title"`
+    Format      string        `json:"format" gorm:"size:10;default:'html'"`
+    Content     string        `json:"content" gorm:"type:text"`
+    Columns     ReportColumns `json:"columns" gorm:"type:text"`
+    UpdatedByID uint          `json:"updated_by_id"`
+    IsDefault   bool          `json:"is_default" gorm:"-"`
+}
+
+// ReportColumns adalah daftar key kolom laporan yang disimpan sebagai JSON
+type ReportColumns []string
+
+func (c ReportColumns) Value() (driver.Value, error) {
+    if c == nil {
+        return "[]", nil
+    }
+    data, err := json.Marshal(c)
+    return string(data), err
+}
+
+func (c *ReportColumns) Scan(value interface{}) error {
+    return scanJSON(value, c)
+}
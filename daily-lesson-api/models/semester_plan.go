@@ -0,0 +1,39 @@
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Status kemajuan program semester terhadap minggu berjalan
+const (
+    ProgressSesuai     = "sesuai"
+    ProgressTertinggal = "tertinggal"
+    ProgressLebihCepat = "lebih_cepat"
+)
+
+// SemesterPlan adalah program semester satu mata pelajaran di satu kelas:
+// urutan topik yang direncanakan beserta minggu targetnya sejak TanggalMulai
+type SemesterPlan struct {
+    gorm.Model
+    MataPelajaran  string         `json:"mata_pelajaran" gorm:"index"`
+    Kelas          string         `json:"kelas" gorm:"index"`
+    TahunAjaran    string         `json:"tahun_ajaran" gorm:"size:9;index"`
+    Semester       int            `json:"semester"`
+    TanggalMulai   time.Time      `json:"tanggal_mulai"`
+    TanggalSelesai time.Time      `json:"tanggal_selesai"`
+    Topics         []PlannedTopic `json:"topics,omitempty" gorm:"foreignKey:PlanID"`
+    CreatedByID    uint           `json:"created_by_id"`
+}
+
+// PlannedTopic adalah satu topik pada program semester. MingguTarget dihitung mulai 1
+// dari minggu TanggalMulai. TujuanPembelajaranID dipakai untuk mencocokkan lesson yang menandai TP tersebut.
+type PlannedTopic struct {
+    gorm.Model
+    PlanID               uint   `json:"plan_id" gorm:"index"`
+    Urutan               int    `json:"urutan"`
+    Topik                string `json:"topik"`
+    MingguTarget         int    `json:"minggu_target"`
+    TujuanPembelajaranID *uint  `json:"tujuan_pembelajaran_id"`
+}
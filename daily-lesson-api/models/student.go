@@ -0,0 +1,44 @@
+package models
+
+import (
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Jenis kelamin siswa
+const (
+    GenderLaki      = "L"
+    GenderPerempuan = "P"
+)
+
+// Student adalah siswa pada roster kelas. Kelas berisi kelas pada tahun ajaran terakhir;
+// riwayat kelas per tahun ajaran ada di ClassMembership. NIS kosong hanya untuk data lama.
+type Student struct {
+    gorm.Model
+    NIS          string            `json:"nis" gorm:"size:20;index:idx_students_nis,unique,where:nis <> ''"`
+    NISN         string            `json:"nisn" gorm:"size:10"`
+    Nama         string            `json:"nama"`
+    JenisKelamin string            `json:"jenis_kelamin" gorm:"size:1"`
+    Kelas        string            `json:"kelas" gorm:"index"`
+    Aktif        bool              `json:"aktif" gorm:"default:true"`
+    Memberships  []ClassMembership `json:"memberships,omitempty"`
+}
+
+// ClassMembership adalah kelas seorang siswa pada satu tahun ajaran (format 2025/2026)
+type ClassMembership struct {
+    gorm.Model
+    StudentID   uint   `json:"student_id" gorm:"uniqueIndex:idx_membership_student_year"`
+    TahunAjaran string `json:"tahun_ajaran" gorm:"size:9;uniqueIndex:idx_membership_student_year;index"`
+    Kelas       string `json:"kelas" gorm:"index"`
+}
+
+// TahunAjaranOf mengembalikan tahun ajaran untuk tanggal t; tahun ajaran baru dimulai bulan Juli
+func TahunAjaranOf(t time.Time) string {
+    year := t.Year()
+    if t.Month() < time.July {
+        year--
+    }
+    return fmt.Sprintf("%d/%d", year, year+1)
+}
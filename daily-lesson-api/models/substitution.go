@@ -0,0 +1,36 @@
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Status penggantian guru
+const (
+    SubstitutionOpen      = "terbuka"
+    SubstitutionAssigned  = "ditugaskan"
+    SubstitutionDone      = "selesai"
+    SubstitutionCancelled = "dibatalkan"
+)
+
+// Substitution mencatat jam pelajaran guru yang berhalangan dan guru pengganti yang mengisinya.
+// Slot tanpa SubstituteTeacherID masih terbuka. Catatan mengajar yang diisi guru pengganti
+// tetap atas nama guru asli, dengan GuruPengganti terisi.
+type Substitution struct {
+    gorm.Model
+    OriginalTeacherID   uint      `json:"original_teacher_id" gorm:"index"`
+    OriginalTeacher     User      `json:"original_teacher" gorm:"foreignKey:OriginalTeacherID"`
+    SubstituteTeacherID *uint     `json:"substitute_teacher_id" gorm:"index"`
+    SubstituteTeacher   *User     `json:"substitute_teacher,omitempty" gorm:"foreignKey:SubstituteTeacherID"`
+    Tanggal             time.Time `json:"tanggal" gorm:"index"`
+    Kelas               string    `json:"kelas"`
+    MataPelajaran       string    `json:"mata_pelajaran"`
+    JamMulai            string    `json:"jam_mulai"`
+    JamSelesai          string    `json:"jam_selesai"`
+    Alasan              string    `json:"alasan"`
+    Status              string    `json:"status" gorm:"size:20;default:'terbuka';index"`
+    LessonID            *uint     `json:"lesson_id"`
+    LeaveRequestID      *uint     `json:"leave_request_id" gorm:"index"`
+    CreatedByID         uint      `json:"created_by_id"`
+}
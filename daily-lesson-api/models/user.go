@@ -11,14 +11,18 @@ const (
     RoleAdmin      UserRole = "admin"
     RoleTeacher    UserRole = "teacher"
     RoleSupervisor UserRole = "supervisor"
+    RoleCounselor  UserRole = "counselor"
 )
 
 type User struct {
     gorm.Model
     Name     string   `json:"name" validate:"required"`
     Email    string   `json:"email" validate:"required,email" gorm:"unique"`
-    Password string   `json:"password" validate:"required,min=6"`
+    Password string   `json:"-" validate:"required,min=6"` // hash bcrypt, tidak pernah dikirim di response
     Role     UserRole `json:"role" gorm:"type:varchar(20);default:'teacher'"`
+
+    // Departemen/rumpun mata pelajaran, dipakai untuk berbagi modul ajar antar guru
+    Departemen string `json:"departemen" gorm:"index"`
 }
 
 func (u *User) HashPassword() error {
@@ -0,0 +1,99 @@
+package utils
+
+import (
+    "math"
+    "os"
+    "strconv"
+    "time"
+
+    "github.com/rwcarlsen/goexif/exif"
+)
+
+// EvidenceMetadata berisi informasi yang dibaca dari EXIF foto bukti mengajar
+type EvidenceMetadata struct {
+    TakenAt   *time.Time
+    Latitude  *float64
+    Longitude *float64
+}
+
+// Geofence adalah area sekolah berupa titik pusat dan radius dalam meter
+type Geofence struct {
+    Latitude     float64
+    Longitude    float64
+    RadiusMeters float64
+}
+
+// ExtractEvidenceMetadata membaca waktu pengambilan dan koordinat GPS dari file foto.
+// Foto tanpa EXIF tidak dianggap error, hanya menghasilkan metadata kosong.
+func ExtractEvidenceMetadata(path string) (EvidenceMetadata, error) {
+    var meta EvidenceMetadata
+
+    f, err := os.Open(path)
+    if err != nil {
+        return meta, err
+    }
+    defer f.Close()
+
+    x, err := exif.Decode(f)
+    if err != nil {
+        return meta, nil
+    }
+
+    if takenAt, err := x.DateTime(); err == nil {
+        meta.TakenAt = &takenAt
+    }
+
+    if lat, long, err := x.LatLong(); err == nil {
+        meta.Latitude = &lat
+        meta.Longitude = &long
+    }
+
+    return meta, nil
+}
+
+// SchoolGeofence membaca geofence sekolah dari environment.
+// Mengembalikan false jika SCHOOL_LATITUDE / SCHOOL_LONGITUDE belum diatur.
+func SchoolGeofence() (Geofence, bool) {
+    lat, errLat := strconv.ParseFloat(os.Getenv("SCHOOL_LATITUDE"), 64)
+    long, errLong := strconv.ParseFloat(os.Getenv("SCHOOL_LONGITUDE"), 64)
+    if errLat != nil || errLong != nil {
+        return Geofence{}, false
+    }
+
+    radius, err := strconv.ParseFloat(os.Getenv("SCHOOL_RADIUS_METERS"), 64)
+    if err != nil || radius <= 0 {
+        radius = 300
+    }
+
+    return Geofence{Latitude: lat, Longitude: long, RadiusMeters: radius}, true
+}
+
+// EvidenceTimeTolerance adalah selisih waktu yang masih diterima antara
+// waktu foto dan jam mengajar (EVIDENCE_TIME_TOLERANCE_MINUTES, default 60 menit)
+func EvidenceTimeTolerance() time.Duration {
+    minutes, err := strconv.Atoi(os.Getenv("EVIDENCE_TIME_TOLERANCE_MINUTES"))
+    if err != nil || minutes < 0 {
+        minutes = 60
+    }
+    return time.Duration(minutes) * time.Minute
+}
+
+// DistanceMeters menghitung jarak dua koordinat dengan rumus haversine
+func DistanceMeters(lat1, long1, lat2, long2 float64) float64 {
+    const earthRadius = 6371000.0
+
+    toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+    dLat := toRad(lat2 - lat1)
+    dLong := toRad(long2 - long1)
+
+    a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+        math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLong/2)*math.Sin(dLong/2)
+
+    return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Contains mengecek apakah koordinat berada di dalam geofence
+func (g Geofence) Contains(lat, long float64) bool {
+    return DistanceMeters(g.Latitude, g.Longitude, lat, long) <= g.RadiusMeters
+}
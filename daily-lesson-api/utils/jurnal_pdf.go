@@ -0,0 +1,326 @@
+package utils
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/go-pdf/fpdf"
+    "daily-lesson-api/models"
+)
+
+// Letterhead adalah kop surat sekolah yang dicetak di atas laporan
+type Letterhead struct {
+    SchoolName string
+    Address    string
+    Contact    string
+    City       string
+    LogoPath   string
+}
+
+// Signatory adalah satu kolom tanda tangan di bagian bawah jurnal
+type Signatory struct {
+    Title string
+    Name  string
+    NIP   string
+}
+
+// JurnalColumn adalah satu kolom tabel jurnal
+type JurnalColumn struct {
+    Key   string
+    Label string
+    Width float64
+}
+
+// JurnalData berisi semua data yang dibutuhkan untuk mencetak jurnal mengajar
+type JurnalData struct {
+    Letterhead  Letterhead
+    Title       string
+    NamaGuru    string
+    Periode     string
+    Columns     []JurnalColumn
+    Lessons     []models.DailyLesson
+    Signatories []Signatory
+    PrintedAt   time.Time
+}
+
+// DefaultJurnalColumns adalah kolom tabel standar jurnal mengajar (lebar dalam mm, A4 landscape)
+var DefaultJurnalColumns = []JurnalColumn{
+    {Key: "no", Label: "No", Width: 10},
+    {Key: "tanggal", Label: "Tanggal", Width: 26},
+    {Key: "jam", Label: "Jam", Width: 26},
+    {Key: "kelas", Label: "Kelas", Width: 25},
+    {Key: "mata_pelajaran", Label: "Mata Pelajaran", Width: 40},
+    {Key: "pokok_materi", Label: "Pokok Materi", Width: 90},
+    {Key: "status", Label: "Status", Width: 25},
+    {Key: "catatan", Label: "Catatan", Width: 35},
+}
+
+// AvailableJurnalColumns adalah semua kolom yang boleh dipilih pada template laporan
+var AvailableJurnalColumns = append(append([]JurnalColumn{}, DefaultJurnalColumns...),
+    JurnalColumn{Key: "nama_guru", Label: "Nama Guru", Width: 35},
+    JurnalColumn{Key: "verifikasi_bukti", Label: "Verifikasi Bukti", Width: 30},
+    JurnalColumn{Key: "guru_pengganti", Label: "Guru Pengganti", Width: 35},
+)
+
+// JurnalColumnsFor mengubah daftar key kolom menjadi kolom tabel; daftar kosong berarti kolom standar
+func JurnalColumnsFor(keys []string) ([]JurnalColumn, error) {
+    if len(keys) == 0 {
+        return DefaultJurnalColumns, nil
+    }
+
+    columns := make([]JurnalColumn, 0, len(keys))
+    for _, key := range keys {
+        found := false
+        for _, column := range AvailableJurnalColumns {
+            if column.Key == key {
+                columns = append(columns, column)
+                found = true
+                break
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("kolom %q tidak dikenal", key)
+        }
+    }
+    return columns, nil
+}
+
+// LetterheadFromEnv membaca kop sekolah dari environment (SCHOOL_NAME, SCHOOL_ADDRESS,
+// SCHOOL_CONTACT, SCHOOL_CITY)
+func LetterheadFromEnv() Letterhead {
+    name := os.Getenv("SCHOOL_NAME")
+    if name == "" {
+        name = "SMK Bisa SMK Hebat"
+    }
+    return Letterhead{
+        SchoolName: name,
+        Address:    os.Getenv("SCHOOL_ADDRESS"),
+        Contact:    os.Getenv("SCHOOL_CONTACT"),
+        City:       os.Getenv("SCHOOL_CITY"),
+    }
+}
+
+// JurnalCellValue mengambil isi sel tabel jurnal untuk kolom tertentu
+func JurnalCellValue(lesson models.DailyLesson, key string, number int) string {
+    switch key {
+    case "no":
+        return fmt.Sprint(number)
+    case "tanggal":
+        return FormatTanggal(lesson.TanggalMengajar)
+    case "jam":
+        if lesson.JamMulai == "" && lesson.JamSelesai == "" {
+            return "-"
+        }
+        return fmt.Sprintf("%s - %s", lesson.JamMulai, lesson.JamSelesai)
+    case "nama_guru":
+        return lesson.NamaGuru
+    case "kelas":
+        return lesson.Kelas
+    case "mata_pelajaran":
+        return lesson.MataPelajaran
+    case "pokok_materi":
+        return lesson.PokokMateri
+    case "status":
+        if lesson.Status == "" {
+            return "-"
+        }
+        status := strings.ReplaceAll(lesson.Status, "_", " ")
+        return strings.ToUpper(status[:1]) + status[1:]
+    case "catatan":
+        return lesson.Catatan
+    case "verifikasi_bukti":
+        return lesson.VerifikasiBukti
+    case "guru_pengganti":
+        return lesson.GuruPengganti
+    }
+    return ""
+}
+
+var bulanIndonesia = []string{
+    "Januari", "Februari", "Maret", "April", "Mei", "Juni",
+    "Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// FormatTanggal menulis tanggal dalam format Indonesia, misalnya 6 Januari 2025
+func FormatTanggal(t time.Time) string {
+    return fmt.Sprintf("%d %s %d", t.Day(), bulanIndonesia[t.Month()-1], t.Year())
+}
+
+// RenderJurnalPDF menulis jurnal mengajar (kop sekolah, tabel kegiatan dan tanda tangan)
+// dalam format PDF A4 landscape
+func RenderJurnalPDF(w io.Writer, data JurnalData) error {
+    pdf := fpdf.New("L", "mm", "A4", "")
+    pdf.SetMargins(12, 12, 12)
+    pdf.SetAutoPageBreak(true, 15)
+    tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+    columns := data.Columns
+    if len(columns) == 0 {
+        columns = DefaultJurnalColumns
+    }
+
+    pageWidth, _ := pdf.GetPageSize()
+    left, _, right, _ := pdf.GetMargins()
+    contentWidth := pageWidth - left - right
+
+    // Lebar kolom diskalakan agar tabel selalu memenuhi lebar halaman
+    var totalWidth float64
+    for _, column := range columns {
+        totalWidth += column.Width
+    }
+    widths := make([]float64, len(columns))
+    for i, column := range columns {
+        widths[i] = column.Width * contentWidth / totalWidth
+    }
+
+    drawHeader := func() {
+        pdf.SetFont("Arial", "B", 9)
+        pdf.SetFillColor(220, 230, 241)
+        for i, column := range columns {
+            pdf.CellFormat(widths[i], 8, tr(column.Label), "1", 0, "C", true, 0, "")
+        }
+        pdf.Ln(-1)
+        pdf.SetFont("Arial", "", 9)
+    }
+
+    pdf.AddPage()
+
+    // Kop sekolah
+    logoWidth := 0.0
+    if data.Letterhead.LogoPath != "" {
+        if _, err := os.Stat(data.Letterhead.LogoPath); err == nil {
+            pdf.ImageOptions(data.Letterhead.LogoPath, left, 10, 22, 0, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+            logoWidth = 22
+        }
+    }
+
+    pdf.SetFont("Arial", "B", 16)
+    pdf.CellFormat(contentWidth, 8, tr(strings.ToUpper(data.Letterhead.SchoolName)), "", 1, "C", false, 0, "")
+    pdf.SetFont("Arial", "", 10)
+    for _, line := range []string{data.Letterhead.Address, data.Letterhead.Contact} {
+        if line != "" {
+            pdf.CellFormat(contentWidth, 5, tr(line), "", 1, "C", false, 0, "")
+        }
+    }
+    if y := pdf.GetY(); logoWidth > 0 && y < 34 {
+        pdf.SetY(34)
+    }
+    pdf.SetLineWidth(0.8)
+    pdf.Line(left, pdf.GetY()+1, pageWidth-right, pdf.GetY()+1)
+    pdf.SetLineWidth(0.2)
+    pdf.Ln(5)
+
+    // Judul dan identitas guru
+    title := data.Title
+    if title == "" {
+        title = "Jurnal Mengajar Guru"
+    }
+    pdf.SetFont("Arial", "B", 13)
+    pdf.CellFormat(contentWidth, 7, tr(strings.ToUpper(title)), "", 1, "C", false, 0, "")
+    pdf.Ln(2)
+
+    pdf.SetFont("Arial", "", 10)
+    pdf.CellFormat(30, 6, "Nama Guru", "", 0, "L", false, 0, "")
+    pdf.CellFormat(0, 6, tr(": "+data.NamaGuru), "", 1, "L", false, 0, "")
+    pdf.CellFormat(30, 6, "Periode", "", 0, "L", false, 0, "")
+    pdf.CellFormat(0, 6, tr(": "+data.Periode), "", 1, "L", false, 0, "")
+    pdf.Ln(3)
+
+    // Tabel kegiatan mengajar
+    drawHeader()
+    const lineHeight = 5.0
+    _, pageHeight := pdf.GetPageSize()
+    _, _, _, bottom := pdf.GetMargins()
+
+    if len(data.Lessons) == 0 {
+        pdf.CellFormat(contentWidth, 8, "Tidak ada catatan mengajar pada periode ini", "1", 1, "C", false, 0, "")
+    }
+
+    for index, lesson := range data.Lessons {
+        cells := make([][]string, len(columns))
+        rowLines := 1
+        for i, column := range columns {
+            value := tr(JurnalCellValue(lesson, column.Key, index+1))
+            lines := pdf.SplitText(value, widths[i]-2)
+            if len(lines) == 0 {
+                lines = []string{""}
+            }
+            cells[i] = lines
+            if len(lines) > rowLines {
+                rowLines = len(lines)
+            }
+        }
+        rowHeight := float64(rowLines)*lineHeight + 2
+
+        if pdf.GetY()+rowHeight > pageHeight-bottom {
+            pdf.AddPage()
+            drawHeader()
+        }
+
+        x, y := pdf.GetX(), pdf.GetY()
+        for i, lines := range cells {
+            pdf.Rect(x, y, widths[i], rowHeight, "D")
+            align := "L"
+            if columns[i].Key == "no" {
+                align = "C"
+            }
+            pdf.SetXY(x+1, y+1)
+            pdf.MultiCell(widths[i]-2, lineHeight, strings.Join(lines, "\n"), "", align, false)
+            x += widths[i]
+        }
+        pdf.SetXY(left, y+rowHeight)
+    }
+
+    // Tanda tangan
+    if len(data.Signatories) > 0 {
+        if pdf.GetY()+45 > pageHeight-bottom {
+            pdf.AddPage()
+        }
+        pdf.Ln(8)
+
+        place := signaturePlace(data)
+
+        blockWidth := contentWidth / float64(len(data.Signatories))
+        y := pdf.GetY()
+        pdf.SetFont("Arial", "", 10)
+        pdf.SetXY(left+blockWidth*float64(len(data.Signatories)-1), y)
+        pdf.CellFormat(blockWidth, 5, tr(place), "", 0, "C", false, 0, "")
+
+        for i, signatory := range data.Signatories {
+            x := left + blockWidth*float64(i)
+            pdf.SetXY(x, y+5)
+            pdf.CellFormat(blockWidth, 5, tr(signatory.Title), "", 0, "C", false, 0, "")
+
+            name := signatory.Name
+            if name == "" {
+                name = "(..............................)"
+            }
+            pdf.SetXY(x, y+28)
+            pdf.SetFont("Arial", "BU", 10)
+            pdf.CellFormat(blockWidth, 5, tr(name), "", 0, "C", false, 0, "")
+            pdf.SetFont("Arial", "", 10)
+            if signatory.NIP != "" {
+                pdf.SetXY(x, y+33)
+                pdf.CellFormat(blockWidth, 5, tr("NIP. "+signatory.NIP), "", 0, "C", false, 0, "")
+            }
+        }
+    }
+
+    return pdf.Output(w)
+}
+
+// signaturePlace menulis tempat dan tanggal cetak di atas tanda tangan, misalnya Bandung, 6 Januari 2025
+func signaturePlace(data JurnalData) string {
+    printedAt := data.PrintedAt
+    if printedAt.IsZero() {
+        printedAt = time.Now()
+    }
+    place := FormatTanggal(printedAt)
+    if data.Letterhead.City != "" {
+        place = data.Letterhead.City + ", " + place
+    }
+    return place
+}
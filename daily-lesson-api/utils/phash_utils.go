@@ -0,0 +1,119 @@
+package utils
+
+import (
+    "fmt"
+    "image"
+    _ "image/jpeg"
+    _ "image/png"
+    "io"
+    "math/bits"
+    "os"
+    "strconv"
+)
+
+// maxHashPixels membatasi ukuran gambar yang di-decode agar file kecil berdimensi raksasa
+// (decompression bomb) tidak menghabiskan memori server
+const maxHashPixels = 50_000_000
+
+// ImageDifferenceHash menghitung perceptual hash (dHash 64-bit) dari file gambar.
+// Gambar yang sama walaupun di-resize atau dikompres ulang menghasilkan hash yang mirip.
+func ImageDifferenceHash(path string) (string, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return "", err
+    }
+    defer f.Close()
+
+    config, _, err := image.DecodeConfig(f)
+    if err != nil {
+        return "", err
+    }
+    if config.Width*config.Height > maxHashPixels {
+        return "", fmt.Errorf("image too large: %dx%d", config.Width, config.Height)
+    }
+    if _, err := f.Seek(0, io.SeekStart); err != nil {
+        return "", err
+    }
+
+    img, _, err := image.Decode(f)
+    if err != nil {
+        return "", err
+    }
+
+    // Perkecil gambar ke grid 9x8 grayscale, lalu bandingkan setiap piksel dengan tetangga kanannya
+    const width, height = 9, 8
+    var gray [height][width]float64
+
+    bounds := img.Bounds()
+    cellW := float64(bounds.Dx()) / width
+    cellH := float64(bounds.Dy()) / height
+
+    for gy := 0; gy < height; gy++ {
+        for gx := 0; gx < width; gx++ {
+            x0 := bounds.Min.X + int(float64(gx)*cellW)
+            x1 := bounds.Min.X + int(float64(gx+1)*cellW)
+            y0 := bounds.Min.Y + int(float64(gy)*cellH)
+            y1 := bounds.Min.Y + int(float64(gy+1)*cellH)
+            if x1 <= x0 {
+                x1 = x0 + 1
+            }
+            if y1 <= y0 {
+                y1 = y0 + 1
+            }
+
+            var sum float64
+            var count int
+            for y := y0; y < y1; y++ {
+                for x := x0; x < x1; x++ {
+                    r, g, b, _ := img.At(x, y).RGBA()
+                    sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+                    count++
+                }
+            }
+            gray[gy][gx] = sum / float64(count)
+        }
+    }
+
+    var hash uint64
+    for gy := 0; gy < height; gy++ {
+        for gx := 0; gx < width-1; gx++ {
+            hash <<= 1
+            if gray[gy][gx] > gray[gy][gx+1] {
+                hash |= 1
+            }
+        }
+    }
+
+    return fmt.Sprintf("%016x", hash), nil
+}
+
+// HashDistance menghitung jumlah bit yang berbeda (hamming distance) antara dua hash.
+// Mengembalikan -1 jika salah satu hash tidak valid.
+func HashDistance(a, b string) int {
+    x, errA := strconv.ParseUint(a, 16, 64)
+    y, errB := strconv.ParseUint(b, 16, 64)
+    if errA != nil || errB != nil {
+        return -1
+    }
+    return bits.OnesCount64(x ^ y)
+}
+
+// DuplicateHashThreshold adalah jarak hash maksimum agar dua foto dianggap sama
+// (EVIDENCE_HASH_THRESHOLD, default 5 dari 64 bit)
+func DuplicateHashThreshold() int {
+    threshold, err := strconv.Atoi(os.Getenv("EVIDENCE_HASH_THRESHOLD"))
+    if err != nil || threshold < 0 {
+        threshold = 5
+    }
+    return threshold
+}
+
+// DuplicateHashWindowDays adalah rentang hari di sekitar tanggal mengajar yang dibandingkan
+// saat mencari foto duplikat (EVIDENCE_HASH_WINDOW_DAYS, default 180 hari)
+func DuplicateHashWindowDays() int {
+    days, err := strconv.Atoi(os.Getenv("EVIDENCE_HASH_WINDOW_DAYS"))
+    if err != nil || days <= 0 {
+        days = 180
+    }
+    return days
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+    "encoding/base64"
+    htmltemplate "html/template"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    texttemplate "text/template"
+
+    "daily-lesson-api/models"
+)
+
+// ReportView adalah data yang tersedia untuk placeholder template laporan
+type ReportView struct {
+    School      Letterhead
+    LogoURL     htmltemplate.URL
+    Title       string
+    NamaGuru    string
+    Periode     string
+    Columns     []JurnalColumn
+    Rows        [][]string
+    Lessons     []models.DailyLesson
+    Total       int
+    Signatories []Signatory
+    Place       string
+    PrintedAt   string
+}
+
+// reportTemplateFuncs adalah fungsi tambahan yang bisa dipakai di dalam template
+var reportTemplateFuncs = map[string]interface{}{
+    "upper":   strings.ToUpper,
+    "tanggal": FormatTanggal,
+}
+
+// NewReportView menyiapkan data template dari data jurnal (baris tabel sudah berupa teks)
+func NewReportView(data JurnalData) ReportView {
+    columns := data.Columns
+    if len(columns) == 0 {
+        columns = DefaultJurnalColumns
+    }
+
+    rows := make([][]string, len(data.Lessons))
+    for i, lesson := range data.Lessons {
+        rows[i] = make([]string, len(columns))
+        for j, column := range columns {
+            rows[i][j] = JurnalCellValue(lesson, column.Key, i+1)
+        }
+    }
+
+    return ReportView{
+        School:      data.Letterhead,
+        LogoURL:     LogoDataURI(data.Letterhead.LogoPath),
+        Title:       data.Title,
+        NamaGuru:    data.NamaGuru,
+        Periode:     data.Periode,
+        Columns:     columns,
+        Rows:        rows,
+        Lessons:     data.Lessons,
+        Total:       len(data.Lessons),
+        Signatories: data.Signatories,
+        Place:       signaturePlace(data),
+        PrintedAt:   FormatTanggal(data.PrintedAt),
+    }
+}
+
+// RenderReportTemplate menjalankan template laporan. Format html memakai html/template
+// sehingga isi data selalu di-escape; format text memakai text/template.
+func RenderReportTemplate(w io.Writer, format, content string, view ReportView) error {
+    if format == models.TemplateFormatText {
+        tmpl, err := texttemplate.New("report").Funcs(reportTemplateFuncs).Parse(content)
+        if err != nil {
+            return err
+        }
+        return tmpl.Execute(w, view)
+    }
+
+    tmpl, err := htmltemplate.New("report").Funcs(reportTemplateFuncs).Parse(content)
+    if err != nil {
+        return err
+    }
+    return tmpl.Execute(w, view)
+}
+
+// LogoDataURI membaca file logo menjadi data URI agar HTML laporan tidak bergantung pada URL API
+func LogoDataURI(path string) htmltemplate.URL {
+    if path == "" {
+        return ""
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return ""
+    }
+
+    mime := "image/png"
+    if ext := strings.ToLower(filepath.Ext(path)); ext == ".jpg" || ext == ".jpeg" {
+        mime = "image/jpeg"
+    }
+    return htmltemplate.URL("data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+// DefaultTeacherReportHTML adalah template bawaan untuk laporan mengajar guru
+const DefaultTeacherReportHTML = `<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - {{.NamaGuru}}</title>
+<style>
+    body { font-family: Arial, sans-serif; font-size: 12px; margin: 24px; }
+    .kop { display: flex; align-items: center; gap: 16px; border-bottom: 3px double #000; padding-bottom: 8px; }
+    .kop img { height: 72px; }
+    .kop div { flex: 1; text-align: center; }
+    .kop h1 { font-size: 20px; margin: 0; }
+    .kop p { margin: 2px 0; }
+    h2 { text-align: center; font-size: 16px; margin: 16px 0 8px; }
+    table.info td { padding: 2px 8px 2px 0; }
+    table.data { width: 100%; border-collapse: collapse; margin-top: 8px; }
+    table.data th, table.data td { border: 1px solid #000; padding: 4px; vertical-align: top; }
+    table.data th { background: #dce6f1; }
+    .tempat { text-align: right; margin-top: 24px; }
+    table.ttd { width: 100%; margin-top: 4px; text-align: center; }
+    table.ttd .nama { margin-top: 64px; font-weight: bold; text-decoration: underline; }
+    @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<div class="kop">
+    {{if .LogoURL}}<img src="{{.LogoURL}}" alt="Logo">{{end}}
+    <div>
+        <h1>{{upper .School.SchoolName}}</h1>
+        {{with .School.Address}}<p>{{.}}</p>{{end}}
+        {{with .School.Contact}}<p>{{.}}</p>{{end}}
+    </div>
+</div>
+
+<h2>{{upper .Title}}</h2>
+<table class="info">
+    <tr><td>Nama Guru</td><td>: {{.NamaGuru}}</td></tr>
+    <tr><td>Periode</td><td>: {{.Periode}}</td></tr>
+    <tr><td>Jumlah Catatan</td><td>: {{.Total}}</td></tr>
+</table>
+
+<table class="data">
+    <thead>
+        <tr>{{range .Columns}}<th>{{.Label}}</th>{{end}}</tr>
+    </thead>
+    <tbody>
+        {{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+        {{else}}<tr><td colspan="{{len .Columns}}">Tidak ada catatan mengajar pada periode ini</td></tr>{{end}}
+    </tbody>
+</table>
+
+<p class="tempat">{{.Place}}</p>
+<table class="ttd">
+    <tr>
+        {{range .Signatories}}<td>
+            <div>{{.Title}}</div>
+            <div class="nama">{{if .Name}}{{.Name}}{{else}}(..............................){{end}}</div>
+            {{with .NIP}}<div>NIP. {{.}}</div>{{end}}
+        </td>{{end}}
+    </tr>
+</table>
+</body>
+</html>
+`
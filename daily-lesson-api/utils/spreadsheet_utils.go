@@ -0,0 +1,105 @@
+package utils
+
+import (
+    "bytes"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "math"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/xuri/excelize/v2"
+)
+
+// ReadSpreadsheet membaca file CSV atau XLSX (sheet pertama) menjadi baris-baris teks.
+// CSV boleh memakai pemisah koma atau titik koma (format ekspor Excel Indonesia).
+// Sel XLSX dibaca apa adanya, sehingga tanggal/jam muncul sebagai serial number Excel
+// (lihat ParseSpreadsheetDate dan ParseSpreadsheetClock).
+func ReadSpreadsheet(filename string, r io.Reader) ([][]string, error) {
+    switch strings.ToLower(filepath.Ext(filename)) {
+    case ".csv":
+        data, err := io.ReadAll(r)
+        if err != nil {
+            return nil, err
+        }
+        data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+        reader := csv.NewReader(bytes.NewReader(data))
+        reader.FieldsPerRecord = -1
+        reader.TrimLeadingSpace = true
+
+        firstLine, _, _ := strings.Cut(string(data), "\n")
+        if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+            reader.Comma = ';'
+        }
+        return reader.ReadAll()
+
+    case ".xlsx":
+        f, err := excelize.OpenReader(r)
+        if err != nil {
+            return nil, err
+        }
+        defer f.Close()
+
+        sheets := f.GetSheetList()
+        if len(sheets) == 0 {
+            return nil, fmt.Errorf("file XLSX tidak memiliki sheet")
+        }
+        return f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+
+    default:
+        return nil, fmt.Errorf("format file tidak didukung, gunakan CSV atau XLSX")
+    }
+}
+
+// ParseSpreadsheetDate menerima tanggal YYYY-MM-DD, DD/MM/YYYY, DD-MM-YYYY atau serial number Excel
+func ParseSpreadsheetDate(value string) (time.Time, error) {
+    value = strings.TrimSpace(value)
+    for _, layout := range []string{"2006-01-02", "02/01/2006", "2/1/2006", "02-01-2006", "2-1-2006"} {
+        if t, err := time.Parse(layout, value); err == nil {
+            return t, nil
+        }
+    }
+
+    if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+        t, err := excelize.ExcelDateToTime(serial, false)
+        if err == nil {
+            return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+        }
+    }
+
+    return time.Time{}, fmt.Errorf("format tanggal tidak valid: %q", value)
+}
+
+// ParseSpreadsheetClock menerima jam HH:MM, H:MM, HH.MM atau pecahan hari dari Excel,
+// lalu mengembalikannya dalam format HH:MM
+func ParseSpreadsheetClock(value string) (string, error) {
+    value = strings.TrimSpace(value)
+    if value == "" {
+        return "", nil
+    }
+
+    normalized := strings.Replace(value, ".", ":", 1)
+    for _, layout := range []string{"15:04", "15:04:05"} {
+        if t, err := time.Parse(layout, normalized); err == nil {
+            return t.Format("15:04"), nil
+        }
+    }
+
+    if fraction, err := strconv.ParseFloat(value, 64); err == nil && fraction >= 0 && fraction < 1 {
+        minutes := int(math.Round(fraction * 24 * 60))
+        return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60), nil
+    }
+
+    return "", fmt.Errorf("format jam tidak valid: %q", value)
+}
+
+// NormalizeHeader menyamakan judul kolom: huruf kecil, spasi/tanda hubung menjadi underscore
+func NormalizeHeader(header string) string {
+    header = strings.ToLower(strings.TrimSpace(header))
+    header = strings.NewReplacer(" ", "_", "-", "_", ".", "", "/", "_").Replace(header)
+    return header
+}
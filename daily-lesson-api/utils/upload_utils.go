@@ -0,0 +1,21 @@
+package utils
+
+import (
+    "os"
+    "path/filepath"
+)
+
+// UploadDir mengembalikan folder penyimpanan file upload (UPLOAD_DIR, default data/uploads)
+// dan membuat subfolder yang diminta jika belum ada
+func UploadDir(subdir string) (string, error) {
+    base := os.Getenv("UPLOAD_DIR")
+    if base == "" {
+        base = filepath.Join("data", "uploads")
+    }
+
+    dir := filepath.Join(base, subdir)
+    if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+        return "", err
+    }
+    return dir, nil
+}
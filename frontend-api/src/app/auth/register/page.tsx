@@ -2,7 +2,7 @@
 
 import { useState, useEffect } from 'react';
 import { useRouter } from 'next/navigation';
-import { Eye, EyeOff, User, Mail, Lock, UserCog, Sparkles } from 'lucide-react';
+import { Eye, EyeOff, User, Mail, Lock, Sparkles } from 'lucide-react';
 import { apiService, RegisterData } from '../../../service/api';
 
 interface RegisterProps {
@@ -25,8 +25,7 @@ const Register: React.FC<RegisterProps> = ({ onToggleForm }) => {
   const [formData, setFormData] = useState<RegisterData>({
     name: '',
     email: '',
-    password: '',
-    role: 'teacher'
+    password: ''
   });
   const [showPassword, setShowPassword] = useState(false);
   const [isLoading, setIsLoading] = useState(false);
@@ -39,7 +38,7 @@ const Register: React.FC<RegisterProps> = ({ onToggleForm }) => {
     return () => setIsMounted(false);
   }, []);
 
-  const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLSelectElement>) => {
+  const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
     setFormData({
       ...formData,
       [e.target.name]: e.target.value,
@@ -178,28 +177,6 @@ const Register: React.FC<RegisterProps> = ({ onToggleForm }) => {
                 </div>
               </div>
 
-              <div className="space-y-2">
-                <label htmlFor="role" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
-                  Peran
-                </label>
-                <div className="relative">
-                  <div className="absolute inset-y-0 left-0 pl-3 flex items-center pointer-events-none z-10">
-                    <UserCog className="h-5 w-5 text-gray-400" />
-                  </div>
-                  <select
-                    id="role"
-                    name="role"
-                    value={formData.role}
-                    onChange={handleChange}
-                    className="transition-all duration-300 pl-10 appearance-none block w-full px-3 py-3 border border-gray-300 dark:border-gray-600 rounded-lg shadow-sm placeholder-gray-400 focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent dark:bg-gray-700 dark:text-white"
-                  >
-                    <option value="teacher">Guru</option>
-                    <option value="supervisor">Supervisor</option>
-                    <option value="admin">Admin</option>
-                  </select>
-                </div>
-              </div>
-
               <div className="pt-4">
                 <button
                   type="submit"
@@ -188,6 +188,7 @@ export default function EditLesson() {
                 <option value="terlaksana">Terlaksana</option>
                 <option value="dibatalkan">Dibatalkan</option>
                 <option value="ditunda">Ditunda</option>
+                <option value="draft">Draft</option>
               </select>
             </div>
 
@@ -21,10 +21,11 @@ interface Lesson {
 export default function LessonsPage() {
   const router = useRouter();
   const [lessons, setLessons] = useState<Lesson[]>([]);
-  const [filteredLessons, setFilteredLessons] = useState<Lesson[]>([]);
   const [isLoading, setIsLoading] = useState(true);
   const [searchTerm, setSearchTerm] = useState('');
+  const [search, setSearch] = useState('');
   const [currentPage, setCurrentPage] = useState(1);
+  const [totalPages, setTotalPages] = useState(0);
   const [error, setError] = useState<string | null>(null);
   const itemsPerPage = 10;
 
@@ -32,8 +33,13 @@ export default function LessonsPage() {
     try {
       setIsLoading(true);
       setError(null);
-      const data = await lessonService.getLessons();
-      setLessons(data || []);
+      const response = await lessonService.getLessons({
+        q: search || undefined,
+        page: currentPage,
+        limit: itemsPerPage,
+      });
+      setLessons(response.data || []);
+      setTotalPages(response.pagination?.total_pages || 0);
     } catch (err: unknown) {
       console.error('Error fetching lessons:', err);
       const errorMessage = err instanceof Error ? err.message : 'Gagal memuat data pembelajaran';
@@ -46,21 +52,20 @@ export default function LessonsPage() {
     } finally {
       setIsLoading(false);
     }
-  }, [router]);
+  }, [router, search, currentPage]);
 
   useEffect(() => {
     fetchLessons();
   }, [fetchLessons]);
 
+  // Pencarian dan halaman diproses di server, input ditunda sebentar agar tidak request tiap ketikan
   useEffect(() => {
-    const filtered = lessons.filter((lesson) =>
-      lesson.nama_guru.toLowerCase().includes(searchTerm.toLowerCase()) ||
-      lesson.mata_pelajaran.toLowerCase().includes(searchTerm.toLowerCase()) ||
-      lesson.kelas.toLowerCase().includes(searchTerm.toLowerCase())
-    );
-    setFilteredLessons(filtered);
-    setCurrentPage(1);
-  }, [searchTerm, lessons]);
+    const timer = setTimeout(() => {
+      setSearch(searchTerm.trim());
+      setCurrentPage(1);
+    }, 300);
+    return () => clearTimeout(timer);
+  }, [searchTerm]);
 
   const handleDelete = async (id: number) => {
     if (!confirm('Apakah Anda yakin ingin menghapus data ini?')) return;
@@ -68,8 +73,12 @@ export default function LessonsPage() {
     try {
       setError(null);
       await lessonService.deleteLesson(id);
-      setLessons((prev) => prev.filter((lesson) => lesson.id !== id));
       alert('Data berhasil dihapus');
+      if (lessons.length === 1 && currentPage > 1) {
+        setCurrentPage(currentPage - 1);
+      } else {
+        fetchLessons();
+      }
     } catch (err: unknown) {
       console.error('Error deleting lesson:', err);
       const errorMessage = err instanceof Error ? err.message : 'Gagal menghapus data';
@@ -78,11 +87,6 @@ export default function LessonsPage() {
     }
   };
 
-  const indexOfLastItem = currentPage * itemsPerPage;
-  const indexOfFirstItem = indexOfLastItem - itemsPerPage;
-  const currentItems = filteredLessons.slice(indexOfFirstItem, indexOfLastItem);
-  const totalPages = Math.ceil(filteredLessons.length / itemsPerPage);
-
   const paginate = (pageNumber: number) => setCurrentPage(pageNumber);
 
   const formatDate = (dateString: string) => {
@@ -98,7 +102,8 @@ export default function LessonsPage() {
     }
   };
 
-  if (isLoading) {
+  // Skeleton hanya untuk muatan pertama agar input pencarian tidak hilang saat berpindah halaman
+  if (isLoading && lessons.length === 0 && !searchTerm) {
     return (
       <div className="animate-pulse space-y-4">
         <div className="flex justify-between items-center mb-6">
@@ -147,7 +152,7 @@ export default function LessonsPage() {
         </div>
       </div>
 
-      {currentItems.length === 0 ? (
+      {lessons.length === 0 ? (
         <div className="text-center py-12 bg-white dark:bg-gray-800 rounded-lg shadow">
           <p className="text-gray-500 dark:text-gray-400">
             {searchTerm
@@ -157,7 +162,7 @@ export default function LessonsPage() {
         </div>
       ) : (
         <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6 mb-6">
-          {currentItems.map((lesson) => (
+          {lessons.map((lesson) => (
             <div
               key={lesson.id}
               className="bg-white dark:bg-gray-800 rounded-lg shadow-sm border border-gray-200 dark:border-gray-700 p-6"
@@ -251,6 +256,7 @@ export default function LessonsPage() {
             disabled={currentPage === totalPages}
             className="px-3 py-1 rounded border border-gray-300 dark:border-gray-600 disabled:opacity-50"
           >
+            Next
           </button>
         </div>
       )}
@@ -14,9 +14,9 @@ const RecentData: React.FC = () => {
   useEffect(() => {
     const fetchRecentLessons = async () => {
       try {
-        const data = await lessonService.getLessons();
         // Ambil 5 data terbaru
-        const recentLessons = data.slice(0, 5);
+        const response = await lessonService.getLessons({ limit: 5 });
+        const recentLessons = response.data || [];
         setLessons(recentLessons);
       } catch (error) {
         console.error('Error fetching recent lessons:', error);
@@ -27,7 +27,7 @@ const UserActivities: React.FC = () => {
 
         if (response.ok) {
           const data = await response.json();
-          setActivities(data.activities || []);
+          setActivities(data.data || []);
         }
       } catch (error) {
         console.error('Error fetching activities:', error);
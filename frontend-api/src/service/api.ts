@@ -6,11 +6,11 @@ export interface LoginData {
   password: string;
 }
 
+// Akun baru selalu berperan guru; peran lain diatur admin
 export interface RegisterData {
   name: string;
   email: string;
   password: string;
-  role: string;
 }
 
 export interface LessonData {
@@ -51,6 +51,23 @@ export interface Lesson {
   updated_at: string;
 }
 
+// Metadata pagination dari backend
+export interface PaginationMeta {
+  page?: number;
+  limit: number;
+  total: number;
+  total_pages?: number;
+  sort: string;
+  order: string;
+  next_cursor?: string;
+  has_more: boolean;
+}
+
+export interface Paginated<T> {
+  data: T[];
+  pagination: PaginationMeta;
+}
+
 // Generic response interface
 export interface ApiResponse<T = unknown> {
   data?: T;
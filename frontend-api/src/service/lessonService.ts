@@ -1,24 +1,32 @@
-import { apiService, LessonData, Lesson } from './api';
+import { apiService, LessonData, Lesson, Paginated } from './api';
 
 export const lessonService = {
   async getLessons(filters?: {
+    q?: string;
     tanggal?: string;
     guru?: string;
     mapel?: string;
     kelas?: string;
+    page?: number;
+    limit?: number;
+    sort?: string;
   }) {
     try {
       const params = new URLSearchParams();
       
+      if (filters?.q) params.append('q', filters.q);
       if (filters?.tanggal) params.append('tanggal', filters.tanggal);
       if (filters?.guru) params.append('guru', filters.guru);
       if (filters?.mapel) params.append('mapel', filters.mapel);
       if (filters?.kelas) params.append('kelas', filters.kelas);
+      if (filters?.page) params.append('page', String(filters.page));
+      if (filters?.limit) params.append('limit', String(filters.limit));
+      if (filters?.sort) params.append('sort', filters.sort);
       
       const queryString = params.toString();
       const url = queryString ? `/lessons?${queryString}` : '/lessons';
       
-      return await apiService.get<Lesson[]>(url);
+      return await apiService.get<Paginated<Lesson>>(url);
     } catch (error) {
       console.error('Error in getLessons:', error);
       throw error;